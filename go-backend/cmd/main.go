@@ -18,6 +18,7 @@ import (
 	"consultorio-juridico/internal/services"
 	"consultorio-juridico/pkg/auth"
 	"consultorio-juridico/pkg/pdf"
+	"consultorio-juridico/pkg/validacion"
 )
 
 func main() {
@@ -49,16 +50,61 @@ func main() {
 	emailService := services.NewEmailService(db, cfg.SMTP)
 	authService := services.NewAuthService(db, cfg.JWT.SecretKey, cfg.JWT.ExpirationTime, emailService)
 	notificationService := services.NewNotificationService(db)
+	workflowService := services.NewWorkflowService(db)
+	versionService := services.NewVersionService(db)
+	configuracionService := services.NewConfiguracionService(db)
+	asignacionService := services.NewAsignacionService(db, workflowService, configuracionService)
+	slaService := services.NewSLAService(db, configuracionService, notificationService)
+	seguimientoService := services.NewSeguimientoService(db)
+	citaService := services.NewCitaService(db, configuracionService, notificationService)
+	conciliacionService := services.NewConciliacionService(db, workflowService)
+	procesoJudicialService := services.NewProcesoJudicialService(db, workflowService)
+	consultanteService := services.NewConsultanteService(db)
+	conflictoInteresService := services.NewConflictoInteresService(db)
+	duplicadoService := services.NewDuplicadoService(db)
+	plantillaService := services.NewPlantillaService(db)
+	comentarioService := services.NewComentarioService(db)
+	borradorService := services.NewBorradorService(db, configuracionService)
+	operacionMasivaService := services.NewOperacionMasivaService(db, workflowService, asignacionService, conciliacionService, slaService, notificationService)
+	if err := consultanteService.MigrarSiPendiente(configuracionService); err != nil {
+		log.Printf("⚠️  Warning al migrar consultantes: %v", err)
+	}
+	if corregidos, err := consultanteService.NormalizarTiposRegistrados(); err != nil {
+		log.Printf("⚠️  Warning al normalizar tipos de documento: %v", err)
+	} else if corregidos > 0 {
+		log.Printf("✅ Tipo de documento normalizado en %d consultantes", corregidos)
+	}
+	auditService := services.NewAuditService(db)
+	if err := auditService.RegistrarCallbacks(); err != nil {
+		log.Fatal("Error registrando auditoría:", err)
+	}
 	pdfGenerator := pdf.NewPDFGenerator()
+	if err := validacion.Registrar(); err != nil {
+		log.Fatal("Error registrando validaciones:", err)
+	}
 
 	// Inicializar handlers
 	authHandler := handlers.NewAuthHandler(authService)
-	controlOperativoHandler := handlers.NewControlOperativoHandler(db, notificationService, pdfGenerator)
-	profesorHandler := handlers.NewProfesorHandler(db, notificationService)
-	coordinadorHandler := handlers.NewCoordinadorHandler(db, notificationService)
+	controlOperativoHandler := handlers.NewControlOperativoHandler(db, notificationService, pdfGenerator, workflowService, versionService, asignacionService, slaService, conciliacionService, consultanteService, conflictoInteresService, duplicadoService, plantillaService)
+	profesorHandler := handlers.NewProfesorHandler(db, notificationService, workflowService)
+	coordinadorHandler := handlers.NewCoordinadorHandler(db, notificationService, workflowService, asignacionService, conciliacionService)
 	notificationHandler := handlers.NewNotificationHandler(notificationService)
 	calificacionHandler := handlers.NewCalificacionHandler(db, notificationService)
 	maintenanceHandler := handlers.NewMaintenanceHandler(db)
+	auditoriaHandler := handlers.NewAuditoriaHandler(auditService)
+	asignacionHandler := handlers.NewAsignacionHandler(db, asignacionService)
+	slaHandler := handlers.NewSLAHandler(slaService)
+	seguimientoHandler := handlers.NewSeguimientoHandler(db, seguimientoService)
+	citaHandler := handlers.NewCitaHandler(db, citaService)
+	conciliacionHandler := handlers.NewConciliacionHandler(db, conciliacionService, notificationService, pdfGenerator)
+	procesoJudicialHandler := handlers.NewProcesoJudicialHandler(db, procesoJudicialService)
+	consultanteHandler := handlers.NewConsultanteHandler(consultanteService)
+	terminosHandler := handlers.NewTerminosHandler()
+	comentarioHandler := handlers.NewComentarioHandler(db, comentarioService, notificationService)
+	borradorHandler := handlers.NewBorradorHandler(borradorService, controlOperativoHandler)
+	conflictoInteresHandler := handlers.NewConflictoInteresHandler(db, conflictoInteresService, workflowService, notificationService)
+	operacionMasivaHandler := handlers.NewOperacionMasivaHandler(operacionMasivaService)
+	plantillaHandler := handlers.NewPlantillaHandler(plantillaService)
 
 	// Obtener configuraciones de optimización
 	optConfig := config.GetOptimizedConfig()
@@ -99,6 +145,15 @@ func main() {
 	// Iniciar limpieza automática de cache cada 5 minutos
 	middleware.StartCacheCleanup(5 * time.Minute)
 
+	// Revisar vencimientos del concepto del asesor cada 15 minutos
+	slaService.IniciarScheduler(15 * time.Minute)
+
+	// Enviar recordatorios de citas próximas cada 15 minutos
+	citaService.IniciarScheduler(15 * time.Minute)
+
+	// Depurar borradores abandonados cada 6 horas
+	borradorService.IniciarScheduler(6 * time.Hour)
+
 	// Rutas públicas
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "API Consultorio Jurídico UCMC"})
@@ -140,6 +195,9 @@ func main() {
 		authRoutes.POST("/reset-password", authHandler.ResetPassword)
 	}
 
+	// Feed .ics de citas; se autentica con el token privado del enlace para que los calendarios puedan suscribirse
+	router.GET("/api/calendario.ics", citaHandler.FeedCalendario)
+
 	// Rutas protegidas
 	protected := router.Group("/api")
 	protected.Use(middleware.AuthMiddleware(db, cfg.JWT.SecretKey))
@@ -152,17 +210,70 @@ func main() {
 		protected.POST("/control-operativo", controlOperativoHandler.CrearControl)
 		protected.GET("/control-operativo/list", controlOperativoHandler.ListarControles)
 		protected.GET("/control-operativo/search", controlOperativoHandler.BuscarControles)
+		protected.GET("/control-operativo/motivos-cierre", controlOperativoHandler.ListarMotivosCierre)
+		protected.GET("/control-operativo/borradores", borradorHandler.ListarBorradores)
+		protected.POST("/control-operativo/borradores", borradorHandler.CrearBorrador)
+		protected.GET("/control-operativo/borradores/:borradorId", borradorHandler.ObtenerBorrador)
+		protected.PUT("/control-operativo/borradores/:borradorId", borradorHandler.ActualizarBorrador)
+		protected.DELETE("/control-operativo/borradores/:borradorId", borradorHandler.EliminarBorrador)
+		protected.POST("/control-operativo/borradores/:borradorId/enviar", borradorHandler.EnviarBorrador)
 		protected.GET("/control-operativo/:id", controlOperativoHandler.ObtenerControl)
+		protected.PUT("/control-operativo/:id", controlOperativoHandler.ActualizarControl)
 		protected.GET("/control-operativo/:id/pdf", controlOperativoHandler.GenerarPDF)
+		protected.GET("/control-operativo/:id/historial", controlOperativoHandler.ObtenerHistorial)
+		protected.GET("/control-operativo/:id/versiones", controlOperativoHandler.ListarVersiones)
+		protected.GET("/control-operativo/:id/versiones/diff", controlOperativoHandler.DiferenciasVersiones)
+		protected.GET("/control-operativo/:id/correcciones", controlOperativoHandler.ObtenerCorrecciones)
+		protected.PUT("/control-operativo/:id/reenviar", controlOperativoHandler.ReenviarControl)
+		protected.PUT("/control-operativo/:id/cerrar", controlOperativoHandler.CerrarControl)
 		protected.PUT("/control-operativo/:id/estado-resultado", controlOperativoHandler.EstablecerEstadoResultado)
+		protected.GET("/control-operativo/:id/seguimientos", seguimientoHandler.ListarSeguimientos)
+		protected.POST("/control-operativo/:id/seguimientos", seguimientoHandler.CrearSeguimiento)
+		protected.PUT("/control-operativo/:id/seguimientos/:seguimientoId", seguimientoHandler.ActualizarSeguimiento)
+		protected.DELETE("/control-operativo/:id/seguimientos/:seguimientoId", seguimientoHandler.EliminarSeguimiento)
+		protected.GET("/control-operativo/:id/citas", citaHandler.ListarCitasControl)
+		protected.POST("/control-operativo/:id/citas", citaHandler.ProgramarCita)
+		protected.GET("/control-operativo/:id/conciliacion", conciliacionHandler.ObtenerConciliacion)
+		protected.PUT("/control-operativo/:id/conciliacion", conciliacionHandler.ActualizarConciliacion)
+		protected.PUT("/control-operativo/:id/conciliacion/resultado", conciliacionHandler.RegistrarResultadoConciliacion)
+		protected.GET("/control-operativo/:id/conciliacion/acta", conciliacionHandler.GenerarActaConciliacion)
+		protected.GET("/control-operativo/:id/proceso-judicial", procesoJudicialHandler.ObtenerProcesoJudicial)
+		protected.PUT("/control-operativo/:id/proceso-judicial", procesoJudicialHandler.GuardarProcesoJudicial)
+		protected.POST("/control-operativo/:id/proceso-judicial/actuaciones", procesoJudicialHandler.AgregarActuacion)
+		protected.DELETE("/control-operativo/:id/proceso-judicial/actuaciones/:actuacionId", procesoJudicialHandler.EliminarActuacion)
+		protected.GET("/control-operativo/:id/comentarios", comentarioHandler.ListarComentarios)
+		protected.POST("/control-operativo/:id/comentarios", comentarioHandler.CrearComentario)
+		protected.GET("/control-operativo/:id/comentarios/no-leidos", comentarioHandler.ContarComentariosNoLeidos)
+		protected.GET("/control-operativo/:id/comentarios/:comentarioId/adjuntos/:adjuntoId", comentarioHandler.DescargarAdjuntoComentario)
 		protected.POST("/upload/temp", controlOperativoHandler.UploadTempFile)
 
+		// Rutas de consultantes
+		protected.GET("/consultantes/buscar", consultanteHandler.BuscarConsultante)
+		protected.GET("/consultantes/:id", consultanteHandler.ObtenerConsultante)
+		protected.GET("/consultantes/:id/controles", consultanteHandler.ListarControlesConsultante)
+
+		// Plantillas por área para guiar la recepción del caso
+		protected.GET("/plantillas-area", plantillaHandler.ListarPlantillas)
+		protected.GET("/plantillas-area/buscar", plantillaHandler.ObtenerPlantillaArea)
+
+		// Rutas de términos legales
+		protected.GET("/terminos/tipos", terminosHandler.ListarTiposTermino)
+		protected.GET("/terminos/festivos", terminosHandler.ListarFestivos)
+		protected.GET("/terminos/calcular", terminosHandler.CalcularTermino)
+
+		// Rutas de citas
+		protected.GET("/citas", citaHandler.MisCitas)
+		protected.PUT("/citas/:id", citaHandler.ReprogramarCita)
+		protected.PUT("/citas/:id/cancelar", citaHandler.CancelarCita)
+		protected.POST("/citas/calendario", citaHandler.GenerarEnlaceCalendario)
+
 		// Rutas para profesores
 		profesorRoutes := protected.Group("/profesor")
 		profesorRoutes.Use(middleware.RequireRole("profesor"))
 		{
 			profesorRoutes.GET("/controles-asignados", profesorHandler.ObtenerControlesAsignados)
 			profesorRoutes.PUT("/control-operativo/:id/concepto", profesorHandler.CompletarConcepto)
+			profesorRoutes.PUT("/control-operativo/:id/devolver", profesorHandler.DevolverControl)
 			profesorRoutes.POST("/calificaciones", calificacionHandler.CrearCalificacion)
 			profesorRoutes.PUT("/calificaciones/:id", calificacionHandler.ActualizarCalificacion)
 		}
@@ -176,10 +287,35 @@ func main() {
 			coordinadorRoutes.GET("/controles-completos", coordinadorHandler.ListarControlesCompletos)
 			coordinadorRoutes.PUT("/control-operativo/:id/resultado", coordinadorHandler.AsignarResultado)
 			coordinadorRoutes.PUT("/control-operativo/:id/editar-estado", coordinadorHandler.EditarEstadoResultado)
+			coordinadorRoutes.PUT("/control-operativo/:id/profesor", coordinadorHandler.ReasignarProfesor)
+			coordinadorRoutes.PUT("/control-operativo/:id/reabrir", controlOperativoHandler.ReabrirControl)
+			coordinadorRoutes.PUT("/control-operativo/:id/desactivar", controlOperativoHandler.DesactivarControl)
+			coordinadorRoutes.PUT("/control-operativo/:id/restaurar", controlOperativoHandler.RestaurarControl)
+			coordinadorRoutes.GET("/controles-desactivados", controlOperativoHandler.ListarDesactivados)
+			coordinadorRoutes.GET("/procesos-judiciales", procesoJudicialHandler.ListarProcesosActivos)
+			coordinadorRoutes.GET("/conflictos-interes", conflictoInteresHandler.ListarConflictosPendientes)
+			coordinadorRoutes.GET("/control-operativo/:id/conflictos-interes", conflictoInteresHandler.ObtenerConflictos)
+			coordinadorRoutes.PUT("/control-operativo/:id/conflictos-interes/reconocer", conflictoInteresHandler.ReconocerConflicto)
+			coordinadorRoutes.PUT("/controles/reasignar", coordinadorHandler.ReasignarProfesorMasivo)
+			coordinadorRoutes.POST("/operaciones-masivas/resultado", operacionMasivaHandler.AsignarResultados)
+			coordinadorRoutes.POST("/operaciones-masivas/reasignacion", operacionMasivaHandler.ReasignarProfesor)
+			coordinadorRoutes.POST("/operaciones-masivas/desactivacion", operacionMasivaHandler.DesactivarControles)
+			coordinadorRoutes.GET("/plantillas-area", plantillaHandler.ListarTodasPlantillas)
+			coordinadorRoutes.POST("/plantillas-area", plantillaHandler.CrearPlantilla)
+			coordinadorRoutes.PUT("/plantillas-area/:id", plantillaHandler.ActualizarPlantilla)
+			coordinadorRoutes.DELETE("/plantillas-area/:id", plantillaHandler.EliminarPlantilla)
+			coordinadorRoutes.GET("/asignacion", asignacionHandler.ObtenerConfiguracion)
+			coordinadorRoutes.PUT("/asignacion/estrategia", asignacionHandler.EstablecerEstrategia)
+			coordinadorRoutes.PUT("/profesor/:id/areas", asignacionHandler.EstablecerAreasProfesor)
+			coordinadorRoutes.GET("/borradores", borradorHandler.ObtenerConfiguracionBorradores)
+			coordinadorRoutes.PUT("/borradores", borradorHandler.ActualizarConfiguracionBorradores)
+			coordinadorRoutes.GET("/sla", slaHandler.ObtenerConfiguracion)
+			coordinadorRoutes.PUT("/sla", slaHandler.ActualizarConfiguracion)
 			coordinadorRoutes.GET("/estadisticas", coordinadorHandler.ObtenerEstadisticas)
 			coordinadorRoutes.GET("/estadisticas-completas", coordinadorHandler.ObtenerEstadisticasCompletas)
 			coordinadorRoutes.POST("/calificaciones", calificacionHandler.CrearCalificacion)
 			coordinadorRoutes.PUT("/calificaciones/:id", calificacionHandler.ActualizarCalificacion)
+			coordinadorRoutes.GET("/auditoria", auditoriaHandler.ListarAuditoria)
 			
 			// Rutas de mantenimiento (solo coordinadores)
 			coordinadorRoutes.GET("/maintenance/status", maintenanceHandler.VerificarEstadoBaseDatos)
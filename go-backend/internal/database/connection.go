@@ -45,6 +45,13 @@ func InitializeDatabase(cfg *config.Config) (*gorm.DB, error) {
 		return nil, fmt.Errorf("error en las migraciones: %w", err)
 	}
 
+	if err := MigrarFechasControl(db); err != nil {
+		return nil, fmt.Errorf("error en las migraciones: %w", err)
+	}
+	if err := MigrarFechaNacimientoConsultantes(db); err != nil {
+		return nil, fmt.Errorf("error en las migraciones: %w", err)
+	}
+
 	log.Println("✅ Migraciones completadas")
 	return db, nil
 }
@@ -52,12 +59,33 @@ func InitializeDatabase(cfg *config.Config) (*gorm.DB, error) {
 func AutoMigrate(db *gorm.DB) error {
 	return db.AutoMigrate(
 		&models.User{},
+		&models.Consultante{},
 		&models.Estudiante{},
 		&models.Profesor{},
 		&models.ControlOperativo{},
 		&models.DocumentoAdjunto{},
 		&models.Notificacion{},
 		&models.Calificacion{},
+		&models.ControlOperativoTransicion{},
+		&models.Auditoria{},
+		&models.ControlOperativoVersion{},
+		&models.RondaCorreccion{},
+		&models.ConfiguracionSistema{},
+		&models.Seguimiento{},
+		&models.SeguimientoAdjunto{},
+		&models.Cita{},
+		&models.Conciliacion{},
+		&models.ConciliacionParte{},
+		&models.ProcesoJudicial{},
+		&models.ActuacionProcesal{},
+		&models.Contraparte{},
+		&models.ConflictoInteres{},
+		&models.Comentario{},
+		&models.ComentarioAdjunto{},
+		&models.ComentarioMencion{},
+		&models.ComentarioLectura{},
+		&models.BorradorControl{},
+		&models.PlantillaArea{},
 	)
 }
 
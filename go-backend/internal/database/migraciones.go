@@ -0,0 +1,104 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+)
+
+// columnasFechaPorPartes son las columnas con las que se guardaban las fechas del control antes de
+// tener columnas de tipo DATE
+var columnasFechaPorPartes = []string{
+	"fecha_dia", "fecha_mes", "fecha_ano",
+	"fecha_nacimiento_dia", "fecha_nacimiento_mes", "fecha_nacimiento_ano",
+}
+
+// fechaDesdeColumnas arma en SQL la fecha a partir de las columnas de año, mes y día. Los CASE
+// anidados evitan llamar make_date con valores que no forman una fecha, y en ese caso retornan NULL
+func fechaDesdeColumnas(ano, mes, dia string) string {
+	return fmt.Sprintf(`CASE WHEN %[1]s BETWEEN 1 AND 9999 AND %[2]s BETWEEN 1 AND 12 THEN
+		CASE WHEN %[3]s BETWEEN 1 AND EXTRACT(DAY FROM make_date(%[1]s, %[2]s, 1) + INTERVAL '1 month - 1 day')
+			THEN make_date(%[1]s, %[2]s, %[3]s) END
+	END`, ano, mes, dia)
+}
+
+// MigrarFechasControl copia la fecha de atención y la de nacimiento guardadas por partes a las
+// columnas fecha_atencion y fecha_nacimiento, y luego elimina las columnas anteriores. Solo hace
+// algo mientras existan las columnas anteriores, así que puede ejecutarse en cada arranque
+func MigrarFechasControl(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.ControlOperativo{}, "fecha_dia") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Una fecha de atención imposible (p. ej. 31 de febrero) se reemplaza por el día de registro
+		atencion := tx.Exec(`UPDATE control_operativos SET fecha_atencion = COALESCE(` +
+			fechaDesdeColumnas("fecha_ano", "fecha_mes", "fecha_dia") + `, created_at::date)
+			WHERE fecha_atencion IS NULL`)
+		if atencion.Error != nil {
+			return fmt.Errorf("error migrando fecha de atención: %w", atencion.Error)
+		}
+
+		// Una fecha de nacimiento incompleta o imposible queda vacía
+		nacimiento := tx.Exec(`UPDATE control_operativos SET fecha_nacimiento = ` +
+			fechaDesdeColumnas("fecha_nacimiento_ano", "fecha_nacimiento_mes", "fecha_nacimiento_dia") + `
+			WHERE fecha_nacimiento IS NULL`)
+		if nacimiento.Error != nil {
+			return fmt.Errorf("error migrando fecha de nacimiento: %w", nacimiento.Error)
+		}
+
+		var descartadas int64
+		if err := tx.Raw(`SELECT COUNT(*) FROM control_operativos
+			WHERE fecha_nacimiento IS NULL AND COALESCE(fecha_nacimiento_ano, 0) <> 0`).
+			Scan(&descartadas).Error; err != nil {
+			return err
+		}
+
+		for _, columna := range columnasFechaPorPartes {
+			if !tx.Migrator().HasColumn(&models.ControlOperativo{}, columna) {
+				continue
+			}
+			if err := tx.Migrator().DropColumn(&models.ControlOperativo{}, columna); err != nil {
+				return fmt.Errorf("error eliminando columna %s: %w", columna, err)
+			}
+		}
+
+		log.Printf("✅ Fechas de %d controles migradas a columnas DATE (%d fechas de nacimiento inválidas quedaron vacías)",
+			atencion.RowsAffected, descartadas)
+		return nil
+	})
+}
+
+// MigrarFechaNacimientoConsultantes copia la fecha de nacimiento de los consultantes guardada por
+// partes a la columna fecha_nacimiento y elimina las columnas anteriores. Como MigrarFechasControl,
+// solo hace algo mientras existan las columnas anteriores
+func MigrarFechaNacimientoConsultantes(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.Consultante{}, "fecha_nacimiento_ano") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Una fecha de nacimiento incompleta o imposible queda vacía
+		nacimiento := tx.Exec(`UPDATE consultantes SET fecha_nacimiento = ` +
+			fechaDesdeColumnas("fecha_nacimiento_ano", "fecha_nacimiento_mes", "fecha_nacimiento_dia") + `
+			WHERE fecha_nacimiento IS NULL`)
+		if nacimiento.Error != nil {
+			return fmt.Errorf("error migrando fecha de nacimiento de consultantes: %w", nacimiento.Error)
+		}
+
+		for _, columna := range []string{"fecha_nacimiento_dia", "fecha_nacimiento_mes", "fecha_nacimiento_ano"} {
+			if !tx.Migrator().HasColumn(&models.Consultante{}, columna) {
+				continue
+			}
+			if err := tx.Migrator().DropColumn(&models.Consultante{}, columna); err != nil {
+				return fmt.Errorf("error eliminando columna %s de consultantes: %w", columna, err)
+			}
+		}
+
+		log.Printf("✅ Fechas de nacimiento de %d consultantes migradas a columna DATE", nacimiento.RowsAffected)
+		return nil
+	})
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/services"
+	"consultorio-juridico/pkg/auth"
+)
+
+type AsignacionHandler struct {
+	db                *gorm.DB
+	asignacionService *services.AsignacionService
+}
+
+func NewAsignacionHandler(db *gorm.DB, asignacionService *services.AsignacionService) *AsignacionHandler {
+	return &AsignacionHandler{
+		db:                db,
+		asignacionService: asignacionService,
+	}
+}
+
+// ObtenerConfiguracion muestra la estrategia de asignación vigente y la carga actual de cada profesor
+// La carga cambia con cada asignación, así que la respuesta no se guarda en cache
+func (h *AsignacionHandler) ObtenerConfiguracion(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	candidatos, err := h.asignacionService.CandidatosAsignacion(h.db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo carga de profesores"})
+		return
+	}
+
+	var disponibles []string
+	for nombre := range services.EstrategiasAsignacion {
+		disponibles = append(disponibles, nombre)
+	}
+	sort.Strings(disponibles)
+
+	c.JSON(http.StatusOK, gin.H{
+		"estrategia":              h.asignacionService.EstrategiaActual(),
+		"estrategias_disponibles": disponibles,
+		"profesores":              candidatos,
+	})
+}
+
+// EstablecerEstrategia cambia la estrategia usada cuando el estudiante no elige profesor
+func (h *AsignacionHandler) EstablecerEstrategia(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var req struct {
+		Estrategia string `json:"estrategia" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Estrategia requerida"})
+		return
+	}
+
+	if _, ok := services.EstrategiasAsignacion[req.Estrategia]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Estrategia de asignación no válida"})
+		return
+	}
+
+	if err := h.asignacionService.EstablecerEstrategia(req.Estrategia, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error guardando estrategia"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Estrategia de asignación actualizada",
+		"estrategia": req.Estrategia,
+	})
+}
+
+// EstablecerAreasProfesor define las áreas de consulta que atiende un profesor
+func (h *AsignacionHandler) EstablecerAreasProfesor(c *gin.Context) {
+	profesorID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de profesor inválido"})
+		return
+	}
+
+	var req struct {
+		Areas []string `json:"areas"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	areas, err := h.asignacionService.EstablecerAreasEspecialidad(uint(profesorID), req.Areas)
+	if errors.Is(err, services.ErrProfesorNoDisponible) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profesor no encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando áreas del profesor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Áreas de especialidad actualizadas",
+		"profesor_id":        profesorID,
+		"areas_especialidad": areas,
+	})
+}
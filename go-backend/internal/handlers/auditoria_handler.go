@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"consultorio-juridico/internal/services"
+)
+
+type AuditoriaHandler struct {
+	auditService *services.AuditService
+}
+
+func NewAuditoriaHandler(auditService *services.AuditService) *AuditoriaHandler {
+	return &AuditoriaHandler{
+		auditService: auditService,
+	}
+}
+
+// ListarAuditoria permite al coordinador consultar quién modificó cada registro
+// Cada escritura agrega registros, así que la respuesta no se guarda en cache
+func (h *AuditoriaHandler) ListarAuditoria(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	pagination := services.PaginationParams{
+		Page:  1,
+		Limit: 50,
+	}
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		pagination.Page = page
+	}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit > 0 {
+		pagination.Limit = limit
+	}
+
+	filtros := services.AuditoriaFiltros{
+		Tabla:     c.Query("tabla"),
+		Campo:     c.Query("campo"),
+		Operacion: c.Query("operacion"),
+		DateFrom:  c.Query("date_from"),
+		DateTo:    c.Query("date_to"),
+	}
+	if registroID, err := strconv.ParseUint(c.Query("registro_id"), 10, 32); err == nil {
+		filtros.RegistroID = uint(registroID)
+	}
+	if usuarioID, err := strconv.ParseUint(c.Query("usuario_id"), 10, 32); err == nil {
+		filtros.UsuarioID = uint(usuarioID)
+	}
+
+	result, err := h.auditService.Listar(filtros, pagination)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo auditoría"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
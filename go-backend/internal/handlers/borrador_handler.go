@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+	"consultorio-juridico/internal/services"
+	"consultorio-juridico/pkg/auth"
+	"consultorio-juridico/pkg/validacion"
+)
+
+type BorradorHandler struct {
+	borradorService         *services.BorradorService
+	controlOperativoHandler *ControlOperativoHandler
+}
+
+func NewBorradorHandler(borradorService *services.BorradorService, controlOperativoHandler *ControlOperativoHandler) *BorradorHandler {
+	return &BorradorHandler{
+		borradorService:         borradorService,
+		controlOperativoHandler: controlOperativoHandler,
+	}
+}
+
+// leerFormularioParcial decodifica el formulario sin aplicar las validaciones de binding
+func leerFormularioParcial(c *gin.Context) (*models.ControlOperativoRequest, bool) {
+	var req models.ControlOperativoRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return nil, false
+	}
+	return &req, true
+}
+
+// cargarBorrador obtiene el borrador de la ruta; cada usuario solo ve los propios.
+// Los borradores cambian con cada guardado, así que sus respuestas no se guardan en cache
+func (h *BorradorHandler) cargarBorrador(c *gin.Context) (*models.User, *models.BorradorControl, bool) {
+	c.Header("Cache-Control", "no-store")
+
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return nil, nil, false
+	}
+
+	borradorID, err := strconv.ParseUint(c.Param("borradorId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de borrador inválido"})
+		return nil, nil, false
+	}
+
+	borrador, err := h.borradorService.Obtener(uint(borradorID), user.ID)
+	if errors.Is(err, services.ErrBorradorNoEncontrado) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Borrador no encontrado"})
+		return nil, nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo borrador"})
+		return nil, nil, false
+	}
+	return user, borrador, true
+}
+
+// ListarBorradores retorna los borradores del usuario
+func (h *BorradorHandler) ListarBorradores(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	borradores, err := h.borradorService.Listar(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo borradores"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"borradores":     borradores,
+		"total":          len(borradores),
+		"dias_retencion": h.borradorService.DiasRetencion(),
+	})
+}
+
+// ObtenerBorrador retorna el formulario guardado para continuar diligenciándolo
+func (h *BorradorHandler) ObtenerBorrador(c *gin.Context) {
+	_, borrador, ok := h.cargarBorrador(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"borrador": borrador})
+}
+
+// CrearBorrador guarda un formulario incompleto sin validar los campos requeridos
+func (h *BorradorHandler) CrearBorrador(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	req, ok := leerFormularioParcial(c)
+	if !ok {
+		return
+	}
+
+	borrador, err := h.borradorService.Crear(req, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error guardando borrador"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Borrador guardado",
+		"borrador": borrador,
+	})
+}
+
+// ActualizarBorrador reemplaza el formulario guardado; puede llamarse tantas veces como se necesite
+func (h *BorradorHandler) ActualizarBorrador(c *gin.Context) {
+	_, borrador, ok := h.cargarBorrador(c)
+	if !ok {
+		return
+	}
+
+	req, ok := leerFormularioParcial(c)
+	if !ok {
+		return
+	}
+
+	if err := h.borradorService.Actualizar(borrador, req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error guardando borrador"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Borrador guardado",
+		"borrador": borrador,
+	})
+}
+
+// EliminarBorrador descarta un borrador
+func (h *BorradorHandler) EliminarBorrador(c *gin.Context) {
+	_, borrador, ok := h.cargarBorrador(c)
+	if !ok {
+		return
+	}
+
+	if err := h.borradorService.Descartar(borrador); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error eliminando borrador"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Borrador eliminado"})
+}
+
+// EnviarBorrador valida el formulario completo y lo registra como control operativo, con la
+// misma asignación y notificación al profesor que CrearControl. El borrador se elimina al crearse
+func (h *BorradorHandler) EnviarBorrador(c *gin.Context) {
+	user, borrador, ok := h.cargarBorrador(c)
+	if !ok {
+		return
+	}
+
+	req, err := h.borradorService.Solicitud(borrador)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error leyendo borrador"})
+		return
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "El borrador está incompleto o tiene datos inválidos",
+			"errores": validacion.Errores(err),
+		})
+		return
+	}
+	// La confirmación de posibles duplicados se da al enviar, no al guardar el borrador
+	if c.Query("confirmar_duplicado") == "true" {
+		req.ConfirmarDuplicado = true
+	}
+
+	h.controlOperativoHandler.registrarControl(c, user, req, func(tx *gorm.DB) error {
+		return h.borradorService.Eliminar(tx, borrador)
+	})
+}
+
+// ObtenerConfiguracionBorradores retorna la antigüedad máxima de los borradores
+func (h *BorradorHandler) ObtenerConfiguracionBorradores(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"dias_retencion": h.borradorService.DiasRetencion()})
+}
+
+// ActualizarConfiguracionBorradores cambia cuántos días sin modificarse se conserva un borrador
+func (h *BorradorHandler) ActualizarConfiguracionBorradores(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var req struct {
+		DiasRetencion int `json:"dias_retencion" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	if err := h.borradorService.EstablecerDiasRetencion(req.DiasRetencion, user); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Configuración de borradores actualizada",
+		"dias_retencion": h.borradorService.DiasRetencion(),
+	})
+}
@@ -86,7 +86,7 @@ func (h *CalificacionHandler) CrearCalificacion(c *gin.Context) {
 	}
 
 	// El hook BeforeSave calculará automáticamente el promedio
-	if err := h.db.Create(&calificacion).Error; err != nil {
+	if err := services.ConActor(h.db, user).Create(&calificacion).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creando calificación"})
 		return
 	}
@@ -276,7 +276,7 @@ func (h *CalificacionHandler) ActualizarCalificacion(c *gin.Context) {
 	calificacion.Observaciones = req.Observaciones
 
 	// El hook BeforeSave calculará automáticamente el promedio
-	if err := h.db.Save(&calificacion).Error; err != nil {
+	if err := services.ConActor(h.db, user).Save(&calificacion).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando calificación"})
 		return
 	}
@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+	"consultorio-juridico/internal/services"
+	"consultorio-juridico/pkg/auth"
+	"consultorio-juridico/pkg/calendario"
+)
+
+type CitaHandler struct {
+	db          *gorm.DB
+	citaService *services.CitaService
+}
+
+func NewCitaHandler(db *gorm.DB, citaService *services.CitaService) *CitaHandler {
+	return &CitaHandler{
+		db:          db,
+		citaService: citaService,
+	}
+}
+
+// responderErrorCita traduce los errores de agenda a respuestas HTTP
+func responderErrorCita(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrCitaSolapada):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCitaInvalida):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCitaNoEncontrada):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cita no encontrada"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error guardando cita"})
+	}
+}
+
+// cargarControlCita obtiene el control de la ruta y verifica que el usuario pueda verlo
+// Las citas cambian con cada agendamiento, así que sus respuestas no se guardan en cache
+func (h *CitaHandler) cargarControlCita(c *gin.Context) (*models.User, *models.ControlOperativo, bool) {
+	c.Header("Cache-Control", "no-store")
+
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return nil, nil, false
+	}
+
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
+		return nil, nil, false
+	}
+
+	var control models.ControlOperativo
+	if err := h.db.First(&control, uint(controlID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
+		return nil, nil, false
+	}
+
+	if !puedeAccederControl(user, &control) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para ver este control"})
+		return nil, nil, false
+	}
+	return user, &control, true
+}
+
+// cargarCitaModificable obtiene la cita de la ruta; solo sus participantes o un coordinador pueden cambiarla
+func (h *CitaHandler) cargarCitaModificable(c *gin.Context) (*models.User, *models.Cita, bool) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return nil, nil, false
+	}
+
+	citaID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de cita inválido"})
+		return nil, nil, false
+	}
+
+	cita, err := h.citaService.Obtener(uint(citaID))
+	if err != nil {
+		responderErrorCita(c, err)
+		return nil, nil, false
+	}
+
+	if user.Role != "coordinador" && !services.EsParticipante(cita, user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para modificar esta cita"})
+		return nil, nil, false
+	}
+	return user, cita, true
+}
+
+// ListarCitasControl retorna las citas programadas sobre el control
+func (h *CitaHandler) ListarCitasControl(c *gin.Context) {
+	_, control, ok := h.cargarControlCita(c)
+	if !ok {
+		return
+	}
+
+	citas, err := h.citaService.ListarPorControl(control.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo citas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"control_id": control.ID, "citas": citas})
+}
+
+// ProgramarCita agenda una reunión con el consultante para el estudiante y el profesor del caso
+func (h *CitaHandler) ProgramarCita(c *gin.Context) {
+	user, control, ok := h.cargarControlCita(c)
+	if !ok {
+		return
+	}
+
+	var req models.CitaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	cita, err := h.citaService.Programar(control, req, user)
+	if err != nil {
+		responderErrorCita(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Cita programada exitosamente",
+		"cita":    cita,
+	})
+}
+
+// ReprogramarCita cambia el horario o el lugar de una cita
+func (h *CitaHandler) ReprogramarCita(c *gin.Context) {
+	user, cita, ok := h.cargarCitaModificable(c)
+	if !ok {
+		return
+	}
+
+	var req models.CitaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	if err := h.citaService.Reprogramar(cita, req, user); err != nil {
+		responderErrorCita(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cita reprogramada exitosamente",
+		"cita":    cita,
+	})
+}
+
+// CancelarCita cancela una cita indicando el motivo
+func (h *CitaHandler) CancelarCita(c *gin.Context) {
+	user, cita, ok := h.cargarCitaModificable(c)
+	if !ok {
+		return
+	}
+
+	var req models.CancelarCitaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Debe indicar el motivo de la cancelación"})
+		return
+	}
+
+	if err := h.citaService.Cancelar(cita, user, req.Motivo); err != nil {
+		responderErrorCita(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cita cancelada exitosamente",
+		"cita":    cita,
+	})
+}
+
+// MisCitas lista la agenda del usuario autenticado (desde/hasta en formato AAAA-MM-DD)
+func (h *CitaHandler) MisCitas(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var desde, hasta *time.Time
+	if valor := c.Query("desde"); valor != "" {
+		fecha, err := time.ParseInLocation("2006-01-02", valor, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Fecha desde inválida"})
+			return
+		}
+		desde = &fecha
+	}
+	if valor := c.Query("hasta"); valor != "" {
+		fecha, err := time.ParseInLocation("2006-01-02", valor, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Fecha hasta inválida"})
+			return
+		}
+		finDia := fecha.AddDate(0, 0, 1)
+		hasta = &finDia
+	}
+	incluirCanceladas, _ := strconv.ParseBool(c.Query("incluir_canceladas"))
+
+	citas, err := h.citaService.ListarPorUsuario(user.ID, desde, hasta, incluirCanceladas)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo citas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"citas": citas})
+}
+
+// GenerarEnlaceCalendario crea (o renueva) el enlace privado del feed .ics del usuario
+func (h *CitaHandler) GenerarEnlaceCalendario(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	token, err := h.citaService.RegenerarTokenCalendario(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generando enlace de calendario"})
+		return
+	}
+
+	esquema := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		esquema = "https"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Enlace de calendario generado; el enlace anterior deja de funcionar",
+		"url":     fmt.Sprintf("%s://%s/api/calendario.ics?token=%s", esquema, c.Request.Host, token),
+	})
+}
+
+// FeedCalendario publica las citas del dueño del token en formato iCalendar
+func (h *CitaHandler) FeedCalendario(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	user, err := h.citaService.UsuarioPorTokenCalendario(c.Query("token"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendario no encontrado"})
+		return
+	}
+
+	// Se incluyen las canceladas recientes para que los clientes retiren el evento
+	desde := time.Now().AddDate(0, -3, 0)
+	citas, err := h.citaService.ListarPorUsuario(user.ID, &desde, nil, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo citas"})
+		return
+	}
+
+	eventos := make([]calendario.Evento, 0, len(citas))
+	for _, cita := range citas {
+		descripcion := cita.Descripcion
+		if cita.Participantes != "" {
+			descripcion = fmt.Sprintf("%s\nParticipantes: %s", descripcion, cita.Participantes)
+		}
+		eventos = append(eventos, calendario.Evento{
+			UID:         fmt.Sprintf("cita-%d@consultorio-juridico", cita.ID),
+			Inicio:      cita.Inicio,
+			Fin:         cita.Fin,
+			Resumen:     fmt.Sprintf("Cita control operativo #%d", cita.ControlOperativoID),
+			Descripcion: descripcion,
+			Lugar:       cita.Lugar,
+			Cancelado:   cita.Estado == services.EstadoCitaCancelada,
+			Actualizado: cita.UpdatedAt,
+		})
+	}
+
+	nombre := fmt.Sprintf("Citas Consultorio Jurídico - %s %s", user.Nombres, user.Apellidos)
+	c.Header("Content-Disposition", "inline; filename=citas.ics")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", calendario.GenerarICS(nombre, eventos))
+}
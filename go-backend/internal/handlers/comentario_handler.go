@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+	"consultorio-juridico/internal/services"
+	"consultorio-juridico/pkg/auth"
+)
+
+type ComentarioHandler struct {
+	db                  *gorm.DB
+	comentarioService   *services.ComentarioService
+	notificationService *services.NotificationService
+}
+
+func NewComentarioHandler(db *gorm.DB, comentarioService *services.ComentarioService, notificationService *services.NotificationService) *ComentarioHandler {
+	return &ComentarioHandler{
+		db:                  db,
+		comentarioService:   comentarioService,
+		notificationService: notificationService,
+	}
+}
+
+// cargarControlHilo obtiene el control de la ruta y verifica que el usuario pueda ver su hilo.
+// El hilo cambia con cada lectura, así que sus respuestas no se guardan en cache
+func (h *ComentarioHandler) cargarControlHilo(c *gin.Context) (*models.User, *models.ControlOperativo, bool) {
+	c.Header("Cache-Control", "no-store")
+
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return nil, nil, false
+	}
+
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
+		return nil, nil, false
+	}
+
+	var control models.ControlOperativo
+	if err := h.db.First(&control, uint(controlID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
+		return nil, nil, false
+	}
+
+	if !puedeAccederControl(user, &control) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para ver este control"})
+		return nil, nil, false
+	}
+	return user, &control, true
+}
+
+// usuariosConAcceso filtra los usuarios que pueden ver el control según las reglas de ListarControles
+func (h *ComentarioHandler) usuariosConAcceso(control *models.ControlOperativo, ids []uint) []uint {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var usuarios []models.User
+	if err := h.db.Where("id IN ? AND activo = true", ids).Find(&usuarios).Error; err != nil {
+		log.Printf("Warning: no se pudieron consultar los participantes del control #%d: %v", control.ID, err)
+		return nil
+	}
+
+	var permitidos []uint
+	for i := range usuarios {
+		if puedeAccederControl(&usuarios[i], control) {
+			permitidos = append(permitidos, usuarios[i].ID)
+		}
+	}
+	return permitidos
+}
+
+// ListarComentarios retorna el hilo del control y registra su lectura por el usuario
+func (h *ComentarioHandler) ListarComentarios(c *gin.Context) {
+	user, control, ok := h.cargarControlHilo(c)
+	if !ok {
+		return
+	}
+
+	noLeidos, err := h.comentarioService.ContarNoLeidos(control.ID, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo comentarios"})
+		return
+	}
+
+	comentarios, err := h.comentarioService.Listar(control.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo comentarios"})
+		return
+	}
+
+	if noLeidos > 0 {
+		if err := h.comentarioService.MarcarLeidos(control.ID, user.ID); err != nil {
+			log.Printf("Warning: no se pudo registrar la lectura del hilo #%d: %v", control.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"control_id":  control.ID,
+		"comentarios": comentarios,
+		"no_leidos":   noLeidos,
+	})
+}
+
+// ContarComentariosNoLeidos retorna cuántos comentarios del hilo no ha leído el usuario
+func (h *ComentarioHandler) ContarComentariosNoLeidos(c *gin.Context) {
+	user, control, ok := h.cargarControlHilo(c)
+	if !ok {
+		return
+	}
+
+	noLeidos, err := h.comentarioService.ContarNoLeidos(control.ID, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error contando comentarios no leídos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"no_leidos": noLeidos})
+}
+
+// CrearComentario publica un comentario en el hilo. Las menciones a usuarios que no pueden ver
+// el control se ignoran
+func (h *ComentarioHandler) CrearComentario(c *gin.Context) {
+	user, control, ok := h.cargarControlHilo(c)
+	if !ok {
+		return
+	}
+
+	var req models.ComentarioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	candidatos, err := h.comentarioService.ResolverMenciones(req.Contenido)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error procesando menciones"})
+		return
+	}
+	var mencionados []models.User
+	ignoradas := []string{}
+	for i := range candidatos {
+		if puedeAccederControl(&candidatos[i], control) {
+			mencionados = append(mencionados, candidatos[i])
+		} else {
+			ignoradas = append(ignoradas, candidatos[i].NombreUsuario)
+		}
+	}
+
+	comentario, err := h.comentarioService.Crear(control.ID, req, mencionados, user)
+	if errors.Is(err, services.ErrComentarioInvalido) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error publicando comentario"})
+		return
+	}
+
+	go func() {
+		participantes, err := h.comentarioService.Participantes(control)
+		if err != nil {
+			log.Printf("Warning: no se pudieron obtener los participantes del hilo #%d: %v", control.ID, err)
+			return
+		}
+		h.notificationService.NotificarComentario(comentario, user, h.usuariosConAcceso(control, participantes))
+	}()
+
+	comentario.Autor = *user
+	c.JSON(http.StatusCreated, gin.H{
+		"message":             "Comentario publicado",
+		"comentario":          comentario,
+		"menciones_ignoradas": ignoradas,
+	})
+}
+
+// DescargarAdjuntoComentario entrega un archivo compartido en el hilo
+func (h *ComentarioHandler) DescargarAdjuntoComentario(c *gin.Context) {
+	_, control, ok := h.cargarControlHilo(c)
+	if !ok {
+		return
+	}
+
+	comentarioID, err := strconv.ParseUint(c.Param("comentarioId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de comentario inválido"})
+		return
+	}
+	adjuntoID, err := strconv.ParseUint(c.Param("adjuntoId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de adjunto inválido"})
+		return
+	}
+
+	adjunto, err := h.comentarioService.ObtenerAdjunto(control.ID, uint(comentarioID), uint(adjuntoID))
+	if errors.Is(err, services.ErrAdjuntoNoEncontrado) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Adjunto no encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo adjunto"})
+		return
+	}
+
+	c.FileAttachment(adjunto.RutaArchivo, adjunto.NombreOriginal)
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+	"consultorio-juridico/internal/services"
+	"consultorio-juridico/pkg/auth"
+	"consultorio-juridico/pkg/pdf"
+)
+
+type ConciliacionHandler struct {
+	db                  *gorm.DB
+	conciliacionService *services.ConciliacionService
+	notificationService *services.NotificationService
+	pdfGenerator        *pdf.PDFGenerator
+}
+
+func NewConciliacionHandler(db *gorm.DB, conciliacionService *services.ConciliacionService, notificationService *services.NotificationService, pdfGenerator *pdf.PDFGenerator) *ConciliacionHandler {
+	return &ConciliacionHandler{
+		db:                  db,
+		conciliacionService: conciliacionService,
+		notificationService: notificationService,
+		pdfGenerator:        pdfGenerator,
+	}
+}
+
+// responderErrorConciliacion traduce los errores del trámite a respuestas HTTP
+func responderErrorConciliacion(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrConciliacionNoEncontrada):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrConciliacionFinalizada):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrConciliacionInvalida):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error procesando la conciliación"})
+	}
+}
+
+// cargarConciliacion obtiene el control de la ruta, valida el acceso y retorna su trámite de conciliación
+// El trámite cambia con cada audiencia, así que sus respuestas no se guardan en cache
+func (h *ConciliacionHandler) cargarConciliacion(c *gin.Context) (*models.User, *models.ControlOperativo, *models.Conciliacion, bool) {
+	c.Header("Cache-Control", "no-store")
+
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return nil, nil, nil, false
+	}
+
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
+		return nil, nil, nil, false
+	}
+
+	var control models.ControlOperativo
+	if err := h.db.First(&control, uint(controlID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
+		return nil, nil, nil, false
+	}
+
+	if !puedeAccederControl(user, &control) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para ver este control"})
+		return nil, nil, nil, false
+	}
+
+	conciliacion, err := h.conciliacionService.Obtener(&control)
+	if err != nil {
+		responderErrorConciliacion(c, err)
+		return nil, nil, nil, false
+	}
+	return user, &control, conciliacion, true
+}
+
+// ObtenerConciliacion retorna el trámite de conciliación del control
+func (h *ConciliacionHandler) ObtenerConciliacion(c *gin.Context) {
+	_, control, conciliacion, ok := h.cargarConciliacion(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"control_id":   control.ID,
+		"conciliacion": conciliacion,
+		"resultados":   services.ResultadosConciliacion,
+	})
+}
+
+// ActualizarConciliacion programa la audiencia y registra convocantes y convocados
+func (h *ConciliacionHandler) ActualizarConciliacion(c *gin.Context) {
+	user, _, conciliacion, ok := h.cargarConciliacion(c)
+	if !ok {
+		return
+	}
+
+	var req models.ConciliacionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	if err := h.conciliacionService.Actualizar(conciliacion, req, user); err != nil {
+		responderErrorConciliacion(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Conciliación actualizada exitosamente",
+		"conciliacion": conciliacion,
+	})
+}
+
+// RegistrarResultadoConciliacion registra el resultado de la audiencia (profesor asignado o coordinador)
+func (h *ConciliacionHandler) RegistrarResultadoConciliacion(c *gin.Context) {
+	user, control, conciliacion, ok := h.cargarConciliacion(c)
+	if !ok {
+		return
+	}
+
+	if user.Role != "profesor" && user.Role != "coordinador" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Solo el profesor asignado o un coordinador pueden registrar el resultado"})
+		return
+	}
+
+	var req models.ResultadoConciliacionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	if err := h.conciliacionService.RegistrarResultado(control, conciliacion, req, user); err != nil {
+		responderErrorConciliacion(c, err)
+		return
+	}
+
+	go h.notificationService.NotificarResultadoConciliacion(control.ID, control.CreatedByID, control.ProfesorAsignadoID, req.Resultado)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Resultado de conciliación registrado exitosamente",
+		"conciliacion": conciliacion,
+		"documento":    fmt.Sprintf("/api/control-operativo/%d/conciliacion/acta", control.ID),
+	})
+}
+
+// GenerarActaConciliacion descarga el acta de conciliación o la constancia de no acuerdo/inasistencia
+func (h *ConciliacionHandler) GenerarActaConciliacion(c *gin.Context) {
+	_, control, conciliacion, ok := h.cargarConciliacion(c)
+	if !ok {
+		return
+	}
+
+	if conciliacion.Resultado == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "La conciliación aún no tiene resultado registrado"})
+		return
+	}
+
+	pdfBytes, err := h.pdfGenerator.GenerarActaConciliacion(control, conciliacion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generando PDF"})
+		return
+	}
+
+	nombre := "constancia_conciliacion"
+	if services.HayAcuerdo(*conciliacion.Resultado) {
+		nombre = "acta_conciliacion"
+	}
+	filename := fmt.Sprintf("%s_%d.pdf", nombre, control.ID)
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Header("Content-Length", strconv.Itoa(len(pdfBytes)))
+
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+	"consultorio-juridico/internal/services"
+	"consultorio-juridico/pkg/auth"
+)
+
+type ConflictoInteresHandler struct {
+	db                      *gorm.DB
+	conflictoInteresService *services.ConflictoInteresService
+	workflowService         *services.WorkflowService
+	notificationService     *services.NotificationService
+}
+
+func NewConflictoInteresHandler(db *gorm.DB, conflictoInteresService *services.ConflictoInteresService, workflowService *services.WorkflowService, notificationService *services.NotificationService) *ConflictoInteresHandler {
+	return &ConflictoInteresHandler{
+		db:                      db,
+		conflictoInteresService: conflictoInteresService,
+		workflowService:         workflowService,
+		notificationService:     notificationService,
+	}
+}
+
+// cargarControlConflicto obtiene el control de la ruta; las rutas solo son accesibles por coordinadores
+func (h *ConflictoInteresHandler) cargarControlConflicto(c *gin.Context) (*models.User, *models.ControlOperativo, bool) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return nil, nil, false
+	}
+
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
+		return nil, nil, false
+	}
+
+	var control models.ControlOperativo
+	if err := h.db.Preload("Contrapartes").First(&control, uint(controlID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
+		return nil, nil, false
+	}
+	return user, &control, true
+}
+
+// ObtenerConflictos retorna las partes del caso y los casos en los que aparecen del otro lado
+// El estado pendiente cambia al reconocer el conflicto, así que la respuesta no se guarda en cache
+func (h *ConflictoInteresHandler) ObtenerConflictos(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	_, control, ok := h.cargarControlConflicto(c)
+	if !ok {
+		return
+	}
+
+	conflictos, err := h.conflictoInteresService.Listar(control.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo conflictos de interés"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"control_id":          control.ID,
+		"nombre_consultante":  control.NombreConsultante,
+		"contrapartes":        control.Contrapartes,
+		"conflicto_pendiente": control.ConflictoPendiente,
+		"conflictos":          conflictos,
+	})
+}
+
+// ReconocerConflicto registra la revisión del coordinador para que el caso pueda continuar
+func (h *ConflictoInteresHandler) ReconocerConflicto(c *gin.Context) {
+	user, control, ok := h.cargarControlConflicto(c)
+	if !ok {
+		return
+	}
+
+	var req models.ReconocerConflictoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Debe registrar las observaciones de la revisión"})
+		return
+	}
+
+	err := services.ConActor(h.db, user).Transaction(func(tx *gorm.DB) error {
+		if err := h.conflictoInteresService.Reconocer(tx, control, user, req.Observaciones); err != nil {
+			return err
+		}
+		return h.workflowService.RegistrarTransicion(tx, control.ID, control.EstadoFlujo, control.EstadoFlujo, user,
+			"Conflicto de interés revisado por coordinación: "+req.Observaciones)
+	})
+	if errors.Is(err, services.ErrSinConflictoPendiente) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error registrando la revisión del conflicto"})
+		return
+	}
+
+	go h.notificationService.CrearNotificacion(
+		control.CreatedByID,
+		control.ID,
+		"conflicto_reconocido",
+		fmt.Sprintf("Coordinación revisó el conflicto de interés del control operativo #%d; el caso puede continuar", control.ID),
+		"",
+	)
+
+	// El profesor no fue notificado al crear el caso porque no podía revisarlo con el conflicto pendiente
+	if control.ProfesorAsignadoID != nil && control.EstadoFlujo == services.EstadoPendienteProfesor {
+		go h.notificationService.NotificarNuevoControlAProfesor(control.ID, *control.ProfesorAsignadoID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Conflicto de interés reconocido",
+		"control": control,
+	})
+}
+
+// ListarConflictosPendientes retorna los casos detenidos a la espera de revisión de coordinación
+// Cada reconocimiento saca casos de la lista, así que la respuesta no se guarda en cache
+func (h *ConflictoInteresHandler) ListarConflictosPendientes(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	controles, err := h.conflictoInteresService.ListarPendientes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo conflictos pendientes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"controles": controles,
+		"total":     len(controles),
+	})
+}
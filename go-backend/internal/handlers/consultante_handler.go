@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"consultorio-juridico/internal/models"
+	"consultorio-juridico/internal/services"
+	"consultorio-juridico/pkg/auth"
+)
+
+type ConsultanteHandler struct {
+	consultanteService *services.ConsultanteService
+}
+
+func NewConsultanteHandler(consultanteService *services.ConsultanteService) *ConsultanteHandler {
+	return &ConsultanteHandler{consultanteService: consultanteService}
+}
+
+// BuscarConsultante encuentra a un consultante por su documento para precargar el formulario
+func (h *ConsultanteHandler) BuscarConsultante(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	tipo := c.Query("tipo_documento")
+	numero := c.Query("numero_documento")
+	if tipo == "" || numero == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tipo_documento y numero_documento son requeridos"})
+		return
+	}
+
+	consultante, err := h.consultanteService.BuscarPorDocumento(tipo, numero)
+	if errors.Is(err, services.ErrConsultanteNoEncontrado) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Consultante no encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error buscando consultante"})
+		return
+	}
+
+	controles, err := h.consultanteService.ListarControles(consultante.ID, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo casos del consultante"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"consultante":     consultante,
+		"total_controles": len(controles),
+	})
+}
+
+// cargarConsultante obtiene el consultante de la ruta con los casos que el usuario puede ver.
+// Quien no es coordinador solo accede a consultantes de casos que le son visibles, y como las
+// respuestas dependen del usuario no se guardan en cache
+func (h *ConsultanteHandler) cargarConsultante(c *gin.Context) (*models.Consultante, []services.ResumenControlConsultante, bool) {
+	c.Header("Cache-Control", "no-store")
+
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return nil, nil, false
+	}
+
+	consultanteID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de consultante inválido"})
+		return nil, nil, false
+	}
+
+	consultante, err := h.consultanteService.Obtener(uint(consultanteID))
+	if errors.Is(err, services.ErrConsultanteNoEncontrado) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Consultante no encontrado"})
+		return nil, nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo consultante"})
+		return nil, nil, false
+	}
+
+	controles, err := h.consultanteService.ListarControles(consultante.ID, func(control *models.ControlOperativo) bool {
+		return puedeAccederControl(user, control)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo casos del consultante"})
+		return nil, nil, false
+	}
+
+	if user.Role != "coordinador" && len(controles) == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para ver este consultante"})
+		return nil, nil, false
+	}
+	return consultante, controles, true
+}
+
+// ObtenerConsultante retorna los datos vigentes de un consultante
+func (h *ConsultanteHandler) ObtenerConsultante(c *gin.Context) {
+	consultante, _, ok := h.cargarConsultante(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"consultante": consultante})
+}
+
+// ListarControlesConsultante retorna el resumen de los casos anteriores del consultante que el
+// usuario puede ver
+func (h *ConsultanteHandler) ListarControlesConsultante(c *gin.Context) {
+	consultante, controles, ok := h.cargarConsultante(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"consultante": consultante,
+		"controles":   controles,
+		"total":       len(controles),
+	})
+}
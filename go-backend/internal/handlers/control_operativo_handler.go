@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -17,6 +20,7 @@ import (
 	"consultorio-juridico/internal/services"
 	"consultorio-juridico/pkg/auth"
 	"consultorio-juridico/pkg/pdf"
+	"consultorio-juridico/pkg/validacion"
 )
 
 type ControlOperativoHandler struct {
@@ -24,14 +28,114 @@ type ControlOperativoHandler struct {
 	notificationService *services.NotificationService
 	pdfGenerator        *pdf.PDFGenerator
 	queryService        *services.QueryService
+	workflowService     *services.WorkflowService
+	versionService      *services.VersionService
+	asignacionService   *services.AsignacionService
+	slaService          *services.SLAService
+	conciliacionService *services.ConciliacionService
+	consultanteService  *services.ConsultanteService
+	conflictoService    *services.ConflictoInteresService
+	duplicadoService    *services.DuplicadoService
+	plantillaService    *services.PlantillaService
 }
 
-func NewControlOperativoHandler(db *gorm.DB, notificationService *services.NotificationService, pdfGenerator *pdf.PDFGenerator) *ControlOperativoHandler {
+func NewControlOperativoHandler(db *gorm.DB, notificationService *services.NotificationService, pdfGenerator *pdf.PDFGenerator, workflowService *services.WorkflowService, versionService *services.VersionService, asignacionService *services.AsignacionService, slaService *services.SLAService, conciliacionService *services.ConciliacionService, consultanteService *services.ConsultanteService, conflictoService *services.ConflictoInteresService, duplicadoService *services.DuplicadoService, plantillaService *services.PlantillaService) *ControlOperativoHandler {
 	return &ControlOperativoHandler{
 		db:                  db,
 		notificationService: notificationService,
 		pdfGenerator:        pdfGenerator,
 		queryService:        services.NewQueryService(db),
+		workflowService:     workflowService,
+		versionService:      versionService,
+		asignacionService:   asignacionService,
+		slaService:          slaService,
+		conciliacionService: conciliacionService,
+		consultanteService:  consultanteService,
+		conflictoService:    conflictoService,
+		duplicadoService:    duplicadoService,
+		plantillaService:    plantillaService,
+	}
+}
+
+// aplicarDatosControl copia al control los datos diligenciados por el estudiante
+func aplicarDatosControl(control *models.ControlOperativo, req *models.ControlOperativoRequest) {
+	control.Ciudad = req.Ciudad
+	control.FechaDia = req.FechaDia
+	control.FechaMes = req.FechaMes
+	control.FechaAno = req.FechaAno
+	control.NombreEstudiante = req.NombreEstudiante
+	control.AreaConsulta = req.AreaConsulta
+	control.RemitidoPor = req.RemitidoPor
+	control.CorreoElectronico = req.CorreoElectronico
+	control.NombreConsultante = req.NombreConsultante
+	control.Edad = req.Edad
+	control.FechaNacimientoDia = req.FechaNacimientoDia
+	control.FechaNacimientoMes = req.FechaNacimientoMes
+	control.FechaNacimientoAno = req.FechaNacimientoAno
+	control.LugarNacimiento = req.LugarNacimiento
+	control.Sexo = req.Sexo
+	control.TipoDocumento = req.TipoDocumento
+	control.NumeroDocumento = req.NumeroDocumento
+	control.LugarExpedicion = req.LugarExpedicion
+	control.Direccion = req.Direccion
+	control.Barrio = req.Barrio
+	control.Estrato = req.Estrato
+	control.NumeroTelefonico = req.NumeroTelefonico
+	control.NumeroCelular = req.NumeroCelular
+	control.EstadoCivil = req.EstadoCivil
+	control.Escolaridad = req.Escolaridad
+	control.ProfesionOficio = req.ProfesionOficio
+	control.DescripcionCaso = req.DescripcionCaso
+	control.ConceptoEstudiante = req.ConceptoEstudiante
+}
+
+// puedeAccederControl aplica las mismas reglas de visibilidad por rol que ListarControles
+func puedeAccederControl(user *models.User, control *models.ControlOperativo) bool {
+	switch user.Role {
+	case "estudiante":
+		return control.Activo && control.CreatedByID == user.ID
+	case "profesor":
+		if !control.Activo {
+			return false
+		}
+		if control.ProfesorAsignadoID != nil && *control.ProfesorAsignadoID == user.ID {
+			return true
+		}
+		nombreCompleto := fmt.Sprintf("%s %s", strings.TrimSpace(user.Nombres), strings.TrimSpace(user.Apellidos))
+		return strings.Contains(strings.ToLower(control.NombreDocenteResponsable), strings.ToLower(nombreCompleto))
+	case "coordinador":
+		return true
+	}
+	return false
+}
+
+// responderErrorValidacion retorna la lista de campos inválidos del formulario
+func responderErrorValidacion(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":   "Datos inválidos",
+		"errores": validacion.Errores(err),
+	})
+}
+
+// responderErrorPlantilla indica qué campos de la plantilla del área no son válidos
+func responderErrorPlantilla(c *gin.Context, err error) {
+	var errorRespuestas *services.ErrorRespuestasPlantilla
+	if errors.As(err, &errorRespuestas) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorRespuestas.Error(), "campos": errorRespuestas.Campos})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Error validando la plantilla del área"})
+}
+
+// responderErrorTransicion traduce los errores del flujo a respuestas HTTP
+func responderErrorTransicion(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrActorNoAutorizado):
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para modificar este control"})
+	case errors.Is(err, services.ErrTransicionNoPermitida):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando estado"})
 	}
 }
 
@@ -50,50 +154,125 @@ func (h *ControlOperativoHandler) CrearControl(c *gin.Context) {
 	var req models.ControlOperativoRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		fmt.Printf("❌ Error binding JSON: %v\n", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Datos inválidos: %v", err)})
+		responderErrorValidacion(c, err)
 		return
 	}
 
+	h.registrarControl(c, user, &req, nil)
+}
+
+// registrarControl crea el control a partir de una solicitud ya validada, notifica al profesor y
+// responde. alConfirmar corre dentro de la misma transacción (p. ej. para borrar el borrador enviado)
+func (h *ControlOperativoHandler) registrarControl(c *gin.Context, user *models.User, req *models.ControlOperativoRequest, alConfirmar func(tx *gorm.DB) error) {
 	// Crear control operativo
 	control := models.ControlOperativo{
-		Ciudad:                   req.Ciudad,
-		FechaDia:                 req.FechaDia,
-		FechaMes:                 req.FechaMes,
-		FechaAno:                 req.FechaAno,
 		NombreDocenteResponsable: req.NombreDocenteResponsable,
-		NombreEstudiante:         req.NombreEstudiante,
-		AreaConsulta:             req.AreaConsulta,
-		RemitidoPor:              req.RemitidoPor,
-		CorreoElectronico:        req.CorreoElectronico,
-		NombreConsultante:        req.NombreConsultante,
-		Edad:                     req.Edad,
-		FechaNacimientoDia:       req.FechaNacimientoDia,
-		FechaNacimientoMes:       req.FechaNacimientoMes,
-		FechaNacimientoAno:       req.FechaNacimientoAno,
-		LugarNacimiento:          req.LugarNacimiento,
-		Sexo:                     req.Sexo,
-		TipoDocumento:            req.TipoDocumento,
-		NumeroDocumento:          req.NumeroDocumento,
-		LugarExpedicion:          req.LugarExpedicion,
-		Direccion:                req.Direccion,
-		Barrio:                   req.Barrio,
-		Estrato:                  req.Estrato,
-		NumeroTelefonico:         req.NumeroTelefonico,
-		NumeroCelular:            req.NumeroCelular,
-		EstadoCivil:              req.EstadoCivil,
-		Escolaridad:              req.Escolaridad,
-		ProfesionOficio:          req.ProfesionOficio,
-		DescripcionCaso:          req.DescripcionCaso,
-		ConceptoEstudiante:       req.ConceptoEstudiante,
 		ProfesorAsignadoID:       req.ProfesorID,
-		EstadoFlujo:              "pendiente_profesor",
+		EstadoFlujo:              services.EstadoPendienteProfesor,
 		Activo:                   true,
 		CreatedByID:              user.ID,
 	}
+	aplicarDatosControl(&control, req)
+	respuestas, err := h.plantillaService.ValidarRespuestas(control.AreaConsulta, req.RespuestasPlantilla)
+	if err != nil {
+		responderErrorPlantilla(c, err)
+		return
+	}
+	control.RespuestasPlantilla = respuestas
+	fechaLimite := h.slaService.CalcularFechaLimite(control.AreaConsulta, time.Now())
+	control.FechaLimiteConcepto = &fechaLimite
 	
 	fmt.Printf("🔍 BACKEND: Asignando profesor ID: %v al control\n", req.ProfesorID)
 
-	if err := h.db.Create(&control).Error; err != nil {
+	// Antes de guardar se buscan casos recientes del mismo consultante y problema; el estudiante
+	// debe confirmar explícitamente para registrarlo de todas formas
+	control.ConsultanteID = req.ConsultanteID
+	duplicados, err := h.duplicadoService.BuscarPosiblesDuplicados(&control)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error verificando casos duplicados"})
+		return
+	}
+	if len(duplicados) > 0 && !req.ConfirmarDuplicado {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":                 "Existen casos recientes que parecen corresponder al mismo consultante y problema",
+			"requiere_confirmacion": true,
+			"posibles_duplicados":   duplicados,
+		})
+		return
+	}
+	comentarioCreacion := "Control operativo creado"
+	if len(duplicados) > 0 {
+		ids := make([]string, len(duplicados))
+		for i, duplicado := range duplicados {
+			ids[i] = fmt.Sprintf("#%d", duplicado.ControlID)
+		}
+		comentarioCreacion += " pese a posibles duplicados " + strings.Join(ids, ", ")
+	}
+
+	var conflictos []models.ConflictoInteres
+	err = services.ConActor(h.db, user).Transaction(func(tx *gorm.DB) error {
+		// Sin profesor elegido se asigna según la estrategia configurada por los coordinadores. En ambos
+		// casos el nombre del docente se toma del profesor, ya que los listados filtran por ese nombre
+		if req.ProfesorID != nil {
+			profesor, err := h.asignacionService.ObtenerProfesorActivo(tx, *req.ProfesorID)
+			if err != nil {
+				return err
+			}
+			control.NombreDocenteResponsable = services.NombreProfesor(profesor)
+		} else {
+			profesor, err := h.asignacionService.AsignarAutomaticamente(tx, user, req.AreaConsulta)
+			switch {
+			case err == nil:
+				control.ProfesorAsignadoID = &profesor.ID
+				control.NombreDocenteResponsable = services.NombreProfesor(profesor)
+			case errors.Is(err, services.ErrSinProfesoresDisponibles):
+				fmt.Printf("⚠️ BACKEND: No hay profesores disponibles para asignación automática\n")
+			default:
+				return err
+			}
+		}
+
+		// El consultante que regresa se vincula por su documento y completa los datos no diligenciados
+		if req.ConsultanteID != nil {
+			if err := h.consultanteService.CompletarDesdeConsultante(tx, &control, *req.ConsultanteID); err != nil {
+				return err
+			}
+		}
+		if err := h.consultanteService.Vincular(tx, &control); err != nil {
+			return err
+		}
+
+		if err := tx.Create(&control).Error; err != nil {
+			return err
+		}
+		if err := h.workflowService.RegistrarTransicion(tx, control.ID, "", services.EstadoPendienteProfesor, user, comentarioCreacion); err != nil {
+			return err
+		}
+
+		// Antes de continuar se verifica que el consultorio no asesore ya a la otra parte
+		if err := h.conflictoService.GuardarContrapartes(tx, control.ID, req.Contrapartes); err != nil {
+			return err
+		}
+		var err error
+		conflictos, err = h.conflictoService.Evaluar(tx, &control)
+		if err != nil || alConfirmar == nil {
+			return err
+		}
+		return alConfirmar(tx)
+	})
+	if errors.Is(err, services.ErrConsultanteNoEncontrado) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El consultante indicado no existe"})
+		return
+	}
+	if errors.Is(err, services.ErrProfesorNoDisponible) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El profesor seleccionado no existe o está inactivo"})
+		return
+	}
+	if errors.Is(err, services.ErrBorradorNoEncontrado) {
+		c.JSON(http.StatusConflict, gin.H{"error": "El borrador ya fue enviado o eliminado"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creando control operativo"})
 		return
 	}
@@ -106,20 +285,45 @@ func (h *ControlOperativoHandler) CrearControl(c *gin.Context) {
 	go func() {
 		// Procesar documentos adjuntos de manera asíncrona
 		if len(req.DocumentosAdjuntos) > 0 {
-			h.procesarDocumentosAdjuntos(control.ID, req.DocumentosAdjuntos)
+			h.procesarDocumentosAdjuntos(control.ID, user.ID, req.DocumentosAdjuntos)
 		}
 
-		// Enviar notificación al profesor de manera asíncrona
-		if req.ProfesorID != nil {
-			h.notificationService.NotificarNuevoControlAProfesor(control.ID, *req.ProfesorID)
+		// Enviar notificación al profesor de manera asíncrona; con un conflicto de interés pendiente
+		// el profesor no puede revisar el caso, así que se le avisa cuando coordinación lo reconozca
+		if control.ProfesorAsignadoID != nil {
+			if !control.ConflictoPendiente {
+				h.notificationService.NotificarNuevoControlAProfesor(control.ID, *control.ProfesorAsignadoID)
+			}
+		} else {
+			h.notificationService.CrearNotificacion(
+				0,
+				control.ID,
+				"control_sin_profesor",
+				fmt.Sprintf("El control operativo #%d no pudo asignarse automáticamente a un profesor", control.ID),
+				"coordinador",
+			)
+		}
+
+		if len(conflictos) > 0 {
+			h.notificationService.CrearNotificacion(
+				0,
+				control.ID,
+				"conflicto_interes",
+				fmt.Sprintf("El control operativo #%d tiene %d posible(s) conflicto(s) de interés pendientes de revisión", control.ID, len(conflictos)),
+				"coordinador",
+			)
 		}
 	}()
 
 	// **RESPUESTA INSTANTÁNEA**: Sin consultas adicionales a BD
-	c.JSON(http.StatusCreated, gin.H{
+	respuesta := gin.H{
 		"message": "Control operativo creado exitosamente",
 		"control": control,
-	})
+	}
+	if len(conflictos) > 0 {
+		respuesta["advertencia"] = "Una de las partes aparece del otro lado en un caso existente. El caso queda detenido hasta que coordinación revise el posible conflicto de interés"
+	}
+	c.JSON(http.StatusCreated, respuesta)
 }
 
 func (h *ControlOperativoHandler) ListarControles(c *gin.Context) {
@@ -162,6 +366,22 @@ func (h *ControlOperativoHandler) ListarControles(c *gin.Context) {
 		AreaConsulta:    c.Query("area_consulta"),
 		DateFrom:        c.Query("date_from"),
 		DateTo:          c.Query("date_to"),
+		SLA:             c.Query("sla"),
+		IncluirCerrados: c.Query("incluir_cerrados") == "true",
+	}
+
+	// Filtros de vencimiento: ?sla=vencido|por_vencer o ?vencido=true / ?por_vencer=true
+	if c.Query(services.FiltroSLAVencido) == "true" {
+		filters.SLA = services.FiltroSLAVencido
+	} else if c.Query(services.FiltroSLAPorVencer) == "true" {
+		filters.SLA = services.FiltroSLAPorVencer
+	}
+	if filters.SLA != "" {
+		if filters.SLA != services.FiltroSLAVencido && filters.SLA != services.FiltroSLAPorVencer {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Filtro sla no válido (vencido, por_vencer)"})
+			return
+		}
+		filters.DiasAvisoSLA = h.slaService.ObtenerConfiguracion().DiasAviso
 	}
 
 	// Filtros específicos por mes y año
@@ -233,7 +453,7 @@ func (h *ControlOperativoHandler) ObtenerControl(c *gin.Context) {
 	}
 	
 	var control models.ControlOperativo
-	if err := h.db.Preload("CreatedBy").First(&control, uint(controlID)).Error; err != nil {
+	if err := h.db.Preload("CreatedBy").Preload("Contrapartes").First(&control, uint(controlID)).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
 		} else {
@@ -260,6 +480,7 @@ func (h *ControlOperativoHandler) BuscarControles(c *gin.Context) {
 	searchConsultante := c.Query("consultante")
 	searchArea := c.Query("area")
 	searchEstado := c.Query("estado")
+	searchRadicado := services.NormalizarRadicado(c.Query("radicado"))
 	
 	// Parsear parámetros de paginación
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -349,6 +570,11 @@ func (h *ControlOperativoHandler) BuscarControles(c *gin.Context) {
 	if searchEstado != "" {
 		query = query.Where("estado_flujo = ?", searchEstado)
 	}
+
+	if searchRadicado != "" {
+		// Permite buscar con el radicado completo o con una parte (p. ej. el consecutivo)
+		query = query.Where("id IN (SELECT control_operativo_id FROM procesos_judiciales WHERE radicado LIKE ?)", "%"+searchRadicado+"%")
+	}
 	
 	// Contar total de registros
 	var total int64
@@ -386,6 +612,7 @@ func (h *ControlOperativoHandler) BuscarControles(c *gin.Context) {
 			"consultante": searchConsultante,
 			"area":        searchArea,
 			"estado":      searchEstado,
+			"radicado":    searchRadicado,
 		},
 	}
 	
@@ -402,8 +629,16 @@ func (h *ControlOperativoHandler) GenerarPDF(c *gin.Context) {
 		return
 	}
 	
+	query := h.db.Preload("CreatedBy").Preload("DocumentosAdjuntos")
+	// ?seguimiento=true agrega el anexo con las actuaciones del caso
+	if incluir, _ := strconv.ParseBool(c.Query("seguimiento")); incluir {
+		query = query.Preload("Seguimientos", func(db *gorm.DB) *gorm.DB {
+			return db.Order("fecha ASC, id ASC")
+		}).Preload("Seguimientos.Autor").Preload("Seguimientos.Adjuntos")
+	}
+
 	var control models.ControlOperativo
-	if err := h.db.Preload("CreatedBy").Preload("DocumentosAdjuntos").First(&control, uint(controlID)).Error; err != nil {
+	if err := query.First(&control, uint(controlID)).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
 		} else {
@@ -415,24 +650,34 @@ func (h *ControlOperativoHandler) GenerarPDF(c *gin.Context) {
 	// Obtener rutas de archivos PDF adjuntos
 	var archivosAdjuntos []string
 	for _, doc := range control.DocumentosAdjuntos {
-		if doc.TipoArchivo == "application/pdf" && doc.RutaArchivo != "" {
-			archivosAdjuntos = append(archivosAdjuntos, doc.RutaArchivo)
+		if doc.ConvertidoPDF && doc.RutaPDF != "" {
+			archivosAdjuntos = append(archivosAdjuntos, doc.RutaPDF)
 		}
 	}
 
 	// Generar PDF (ya incluye manejo de adjuntos internamente)
-	pdfBytes, err := h.pdfGenerator.GenerarControlOperativo(&control)
-	
+	resultado, err := h.pdfGenerator.GenerarControlOperativoCombinado(&control)
+	var errAdjunto *pdf.ErrorArchivoPDF
+	if errors.As(err, &errAdjunto) {
+		// Un adjunto cifrado o dañado se reporta para que el estudiante lo reemplace
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":     fmt.Sprintf("No se pudo anexar el documento %s: %v", errAdjunto.Nombre, errAdjunto.Err),
+			"documento": errAdjunto.Nombre,
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generando PDF"})
 		return
 	}
+	pdfBytes := resultado.PDF
 
 	// Configurar headers para descarga
 	filename := fmt.Sprintf("control_operativo_%d.pdf", control.ID)
 	c.Header("Content-Type", "application/pdf")
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 	c.Header("Content-Length", strconv.Itoa(len(pdfBytes)))
+	c.Header("X-Total-Paginas", strconv.Itoa(resultado.Paginas))
 
 	c.Data(http.StatusOK, "application/pdf", pdfBytes)
 }
@@ -460,15 +705,7 @@ func (h *ControlOperativoHandler) EstablecerEstadoResultado(c *gin.Context) {
 	}
 
 	// Validar estados permitidos
-	estadosPermitidos := []string{"asesoria_consulta", "reparto", "auto_reparto", "solicitud_conciliacion"}
-	estadoValido := false
-	for _, estado := range estadosPermitidos {
-		if req.EstadoResultado == estado {
-			estadoValido = true
-			break
-		}
-	}
-	if !estadoValido {
+	if !services.EsEstadoResultadoValido(req.EstadoResultado) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Estado resultado no válido"})
 		return
 	}
@@ -480,34 +717,20 @@ func (h *ControlOperativoHandler) EstablecerEstadoResultado(c *gin.Context) {
 		return
 	}
 
-	// Verificar permisos según el rol
-	if user.Role == "estudiante" {
-		// Los estudiantes solo pueden establecer estado si:
-		// 1. Son el creador del control
-		// 2. El profesor ya completó el concepto (estado_flujo = 'completo')
-		if control.CreatedByID != user.ID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para modificar este control"})
-			return
+	// El motor de flujo valida el rol, la propiedad del control y el estado actual
+	err = services.ConActor(h.db, user).Transaction(func(tx *gorm.DB) error {
+		if err := h.workflowService.Transicionar(tx, &control, services.EstadoConResultado, user,
+			fmt.Sprintf("Estado resultado establecido: %s", req.EstadoResultado),
+			map[string]interface{}{"estado_resultado": req.EstadoResultado}); err != nil {
+			return err
 		}
-		
-		if control.EstadoFlujo != "completo" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Solo puedes establecer el estado después de que el profesor complete su concepto"})
-			return
+		if req.EstadoResultado == services.EstadoResultadoSolicitudConciliacion {
+			return h.conciliacionService.Iniciar(tx, &control)
 		}
-	} else if user.Role == "coordinador" {
-		// Los coordinadores pueden establecer estado en cualquier momento para cualquier control
-		// No hay restricciones adicionales
-	} else {
-		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para realizar esta acción"})
-		return
-	}
-
-	// Actualizar estado resultado y cambiar flujo a con_resultado
-	control.EstadoResultado = &req.EstadoResultado
-	control.EstadoFlujo = "con_resultado"
-
-	if err := h.db.Save(&control).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando estado"})
+		return nil
+	})
+	if err != nil {
+		responderErrorTransicion(c, err)
 		return
 	}
 
@@ -531,103 +754,775 @@ func (h *ControlOperativoHandler) EstablecerEstadoResultado(c *gin.Context) {
 	})
 }
 
-// UploadTempFile maneja la subida temporal de archivos
-func (h *ControlOperativoHandler) UploadTempFile(c *gin.Context) {
+// ObtenerHistorial retorna las transiciones de estado de un control
+// Cada transición agrega una entrada, así que la respuesta no se guarda en cache
+func (h *ControlOperativoHandler) ObtenerHistorial(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
 	user, exists := auth.GetUserFromContext(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
 		return
 	}
 
-	// Obtener el archivo del formulario
-	file, err := c.FormFile("file")
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No se pudo obtener el archivo"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
 		return
 	}
 
-	// Validar que sea PDF
-	if !strings.HasSuffix(strings.ToLower(file.Filename), ".pdf") {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Solo se permiten archivos PDF"})
+	var control models.ControlOperativo
+	if err := h.db.First(&control, uint(controlID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
 		return
 	}
 
-	// Validar tamaño (5MB máximo)
-	if file.Size > 5*1024*1024 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "El archivo excede el límite de 5MB"})
+	if !puedeAccederControl(user, &control) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para ver este control"})
 		return
 	}
 
-	// Crear directorio temporal si no existe
-	tempDir := "storage/uploads/temp"
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creando directorio temporal"})
+	historial, err := h.workflowService.ObtenerHistorial(control.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo historial"})
 		return
 	}
 
-	// Generar nombre único para el archivo
-	timestamp := time.Now().Unix()
-	filename := fmt.Sprintf("%d_%d_%s", user.ID, timestamp, file.Filename)
-	filepath := filepath.Join(tempDir, filename)
+	c.JSON(http.StatusOK, gin.H{
+		"control_id":               control.ID,
+		"estado_actual":            control.EstadoFlujo,
+		"transiciones_disponibles": h.workflowService.TransicionesDisponibles(control.EstadoFlujo, user.Role),
+		"historial":                historial,
+	})
+}
 
-	// Guardar archivo temporalmente
-	if err := c.SaveUploadedFile(file, filepath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error guardando archivo"})
+// ActualizarControl permite al estudiante creador corregir el control mientras el profesor no lo ha revisado
+func (h *ControlOperativoHandler) ActualizarControl(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
+		return
+	}
+
+	var req models.ControlOperativoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responderErrorValidacion(c, err)
+		return
+	}
+
+	var control models.ControlOperativo
+	if err := h.db.First(&control, uint(controlID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
+		return
+	}
+
+	if !control.Activo || control.CreatedByID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Solo el estudiante que creó el control puede editarlo"})
+		return
+	}
+	if !services.PermiteEdicion(control.EstadoFlujo) {
+		c.JSON(http.StatusConflict, gin.H{"error": "El control ya no puede editarse en su estado actual"})
 		return
 	}
 
+	actualizado := control
+	aplicarDatosControl(&actualizado, &req)
+	// Las respuestas de la plantilla se validan de nuevo cuando se envían o cuando cambia el área
+	if req.RespuestasPlantilla != nil || !strings.EqualFold(strings.TrimSpace(actualizado.AreaConsulta), strings.TrimSpace(control.AreaConsulta)) {
+		respuestas, err := h.plantillaService.ValidarRespuestas(actualizado.AreaConsulta, req.RespuestasPlantilla)
+		if err != nil {
+			responderErrorPlantilla(c, err)
+			return
+		}
+		actualizado.RespuestasPlantilla = respuestas
+	}
+	cambios := services.Diferencias(services.DatosEditables(&control), services.DatosEditables(&actualizado))
+
+	var conflictos []models.ConflictoInteres
+	if len(cambios) > 0 || req.Contrapartes != nil {
+		err = services.ConActor(h.db, user).Transaction(func(tx *gorm.DB) error {
+			if len(cambios) > 0 {
+				if _, err := h.versionService.GuardarVersion(tx, &control, user); err != nil {
+					return err
+				}
+
+				if err := h.consultanteService.Vincular(tx, &actualizado); err != nil {
+					return err
+				}
+
+				datos := services.ColumnasEditables(&actualizado)
+				datos["consultante_id"] = actualizado.ConsultanteID
+				datos["updated_at"] = time.Now()
+
+				// La condición sobre el estado evita editar un control que el profesor acaba de revisar
+				result := tx.Model(&models.ControlOperativo{}).
+					Where("id = ? AND estado_flujo = ?", control.ID, control.EstadoFlujo).
+					Updates(datos)
+				if result.Error != nil {
+					return result.Error
+				}
+				if result.RowsAffected == 0 {
+					return services.ErrTransicionNoPermitida
+				}
+				if err := tx.First(&control, control.ID).Error; err != nil {
+					return err
+				}
+			}
+
+			// Las partes pudieron cambiar, así que se vuelve a verificar el conflicto de interés
+			if req.Contrapartes != nil {
+				if err := h.conflictoService.GuardarContrapartes(tx, control.ID, req.Contrapartes); err != nil {
+					return err
+				}
+			}
+			var err error
+			conflictos, err = h.conflictoService.Evaluar(tx, &control)
+			return err
+		})
+		if errors.Is(err, services.ErrTransicionNoPermitida) {
+			c.JSON(http.StatusConflict, gin.H{"error": "El control cambió de estado y ya no puede editarse"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando control operativo"})
+			return
+		}
+	}
+
+	if len(req.DocumentosAdjuntos) > 0 {
+		go h.procesarDocumentosAdjuntos(control.ID, user.ID, req.DocumentosAdjuntos)
+	}
+
+	if len(cambios) > 0 && control.ProfesorAsignadoID != nil {
+		campos := make([]string, 0, len(cambios))
+		for _, cambio := range cambios {
+			campos = append(campos, cambio.Campo)
+		}
+		go h.notificationService.NotificarControlEditadoAProfesor(control.ID, *control.ProfesorAsignadoID, campos)
+	}
+
+	if len(conflictos) > 0 {
+		go h.notificationService.CrearNotificacion(
+			0,
+			control.ID,
+			"conflicto_interes",
+			fmt.Sprintf("El control operativo #%d tiene %d posible(s) conflicto(s) de interés pendientes de revisión", control.ID, len(conflictos)),
+			"coordinador",
+		)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message":  "Archivo subido exitosamente",
-		"filename": filename,
-		"size":     file.Size,
-		"original": file.Filename,
+		"message": "Control operativo actualizado exitosamente",
+		"control": control,
+		"cambios": cambios,
 	})
 }
 
-// procesarDocumentosAdjuntos mueve archivos temporales a la ubicación final
-func (h *ControlOperativoHandler) procesarDocumentosAdjuntos(controlID uint, documentos []string) {
-	tempDir := "storage/uploads/temp"
-	finalDir := fmt.Sprintf("storage/uploads/control-operativo/%d", controlID)
-	
-	// Crear directorio final si no existe
-	if err := os.MkdirAll(finalDir, 0755); err != nil {
-		fmt.Printf("Error creando directorio final: %v\n", err)
+// ListarVersiones retorna las revisiones anteriores de un control
+// Cada edición agrega una revisión, así que la respuesta no se guarda en cache
+func (h *ControlOperativoHandler) ListarVersiones(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
 		return
 	}
 
-	for _, filename := range documentos {
-		tempPath := filepath.Join(tempDir, filename)
-		finalPath := filepath.Join(finalDir, filename)
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
+		return
+	}
 
-		// Verificar que el archivo temporal existe
-		if _, err := os.Stat(tempPath); os.IsNotExist(err) {
-			fmt.Printf("Archivo temporal no encontrado: %s\n", tempPath)
-			continue
-		}
+	var control models.ControlOperativo
+	if err := h.db.First(&control, uint(controlID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
+		return
+	}
 
-		// Mover archivo de temporal a final
-		if err := os.Rename(tempPath, finalPath); err != nil {
-			fmt.Printf("Error moviendo archivo %s: %v\n", filename, err)
-			continue
-		}
+	if !puedeAccederControl(user, &control) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para ver este control"})
+		return
+	}
 
-		// Obtener información del archivo
-		fileInfo, err := os.Stat(finalPath)
-		if err != nil {
-			fmt.Printf("Error obteniendo info del archivo %s: %v\n", filename, err)
-			continue
+	versiones, err := h.versionService.ListarVersiones(control.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo versiones"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"control_id":     control.ID,
+		"version_actual": len(versiones) + 1,
+		"versiones":      versiones,
+	})
+}
+
+// DiferenciasVersiones compara dos revisiones de un control; por defecto la última guardada contra la actual
+// Se compara contra el estado actual, así que la respuesta no se guarda en cache
+func (h *ControlOperativoHandler) DiferenciasVersiones(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
+		return
+	}
+
+	var control models.ControlOperativo
+	if err := h.db.First(&control, uint(controlID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
+		return
+	}
+
+	if !puedeAccederControl(user, &control) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para ver este control"})
+		return
+	}
+
+	actual, err := h.versionService.VersionActual(control.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo versiones"})
+		return
+	}
+
+	hasta := actual
+	if valor := c.Query("hasta"); valor != "" {
+		if hasta, err = strconv.Atoi(valor); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro 'hasta' inválido"})
+			return
+		}
+	}
+	desde := hasta - 1
+	if valor := c.Query("desde"); valor != "" {
+		if desde, err = strconv.Atoi(valor); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro 'desde' inválido"})
+			return
 		}
+	}
 
-		// Crear registro en base de datos
-		documento := models.DocumentoAdjunto{
-			ControlOperativoID: controlID,
-			NombreOriginal:     filename,
-			NombreArchivo:      filename,
-			TipoArchivo:        "application/pdf",
-			TamanoBytes:        fileInfo.Size(),
-			RutaArchivo:        finalPath,
-			ConvertidoPDF:      true,
-			RutaPDF:            finalPath,
+	datosDesde, err := h.versionService.DatosVersion(&control, desde)
+	if err != nil {
+		responderErrorVersion(c, err, actual)
+		return
+	}
+	datosHasta, err := h.versionService.DatosVersion(&control, hasta)
+	if err != nil {
+		responderErrorVersion(c, err, actual)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"control_id":     control.ID,
+		"version_actual": actual,
+		"desde":          desde,
+		"hasta":          hasta,
+		"cambios":        services.Diferencias(datosDesde, datosHasta),
+	})
+}
+
+func responderErrorVersion(c *gin.Context, err error, actual int) {
+	if errors.Is(err, services.ErrVersionNoEncontrada) {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Versión no encontrada (disponibles: 1 a %d)", actual)})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo versiones"})
+}
+
+// ReenviarControl devuelve al profesor un control que el estudiante ya corrigió
+func (h *ControlOperativoHandler) ReenviarControl(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
+		return
+	}
+
+	var req models.ReenviarControlRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	var control models.ControlOperativo
+	if err := h.db.First(&control, uint(controlID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
+		return
+	}
+
+	var ronda *models.RondaCorreccion
+	err = services.ConActor(h.db, user).Transaction(func(tx *gorm.DB) error {
+		var err error
+		ronda, err = h.workflowService.ReenviarAProfesor(tx, &control, user, strings.TrimSpace(req.Respuesta))
+		if err != nil {
+			return err
+		}
+		// El profesor recibe un plazo nuevo para revisar las correcciones
+		return h.slaService.AsignarFechaLimite(tx, &control, time.Now())
+	})
+	if err != nil {
+		responderErrorTransicion(c, err)
+		return
+	}
+
+	if control.ProfesorAsignadoID != nil {
+		go h.notificationService.NotificarControlReenviadoAProfesor(control.ID, *control.ProfesorAsignadoID, ronda.Ronda)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Control reenviado al profesor",
+		"control": control,
+		"ronda":   ronda,
+	})
+}
+
+// ObtenerCorrecciones retorna las rondas de corrección de un control
+// Cada devolución y reenvío cambia las rondas, así que la respuesta no se guarda en cache
+func (h *ControlOperativoHandler) ObtenerCorrecciones(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
+		return
+	}
+
+	var control models.ControlOperativo
+	if err := h.db.First(&control, uint(controlID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
+		return
+	}
+
+	if !puedeAccederControl(user, &control) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para ver este control"})
+		return
+	}
+
+	rondas, err := h.workflowService.ObtenerRondasCorreccion(control.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo correcciones"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"control_id":    control.ID,
+		"estado_actual": control.EstadoFlujo,
+		"total_rondas":  len(rondas),
+		"rondas":        rondas,
+	})
+}
+
+// ListarMotivosCierre retorna el catálogo de motivos de cierre
+func (h *ControlOperativoHandler) ListarMotivosCierre(c *gin.Context) {
+	c.JSON(http.StatusOK, services.MotivosCierre)
+}
+
+// CerrarControl archiva el caso; lo pueden hacer el profesor asignado o un coordinador
+func (h *ControlOperativoHandler) CerrarControl(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	if user.Role != "profesor" && user.Role != "coordinador" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Solo profesores y coordinadores pueden cerrar casos"})
+		return
+	}
+
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
+		return
+	}
+
+	var req models.CerrarControlRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El motivo de cierre es obligatorio"})
+		return
+	}
+
+	if !services.EsMotivoCierreValido(req.MotivoCierre) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Motivo de cierre no válido"})
+		return
+	}
+
+	fechaCierre := time.Now()
+	if req.FechaCierre != "" {
+		fecha, err := time.ParseInLocation("2006-01-02", req.FechaCierre, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Fecha de cierre inválida, use el formato YYYY-MM-DD"})
+			return
+		}
+		if fecha.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "La fecha de cierre no puede ser futura"})
+			return
+		}
+		fechaCierre = fecha
+	}
+
+	var control models.ControlOperativo
+	if err := h.db.First(&control, uint(controlID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
+		return
+	}
+
+	err = services.ConActor(h.db, user).Transaction(func(tx *gorm.DB) error {
+		return h.workflowService.Cerrar(tx, &control, user, req.MotivoCierre, strings.TrimSpace(req.NotaCierre), fechaCierre)
+	})
+	if err != nil {
+		responderErrorTransicion(c, err)
+		return
+	}
+
+	go h.notificationService.NotificarControlCerrado(control.ID, control.CreatedByID, req.MotivoCierre)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Caso cerrado exitosamente",
+		"control": control,
+	})
+}
+
+// ReabrirControl permite al coordinador devolver un caso cerrado a su estado anterior
+func (h *ControlOperativoHandler) ReabrirControl(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
+		return
+	}
+
+	var req models.ReabrirControlRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	var control models.ControlOperativo
+	if err := h.db.First(&control, uint(controlID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
+		return
+	}
+
+	err = services.ConActor(h.db, user).Transaction(func(tx *gorm.DB) error {
+		if err := h.workflowService.Reabrir(tx, &control, user, strings.TrimSpace(req.Motivo)); err != nil {
+			return err
+		}
+		// Si vuelve a revisión del profesor, el plazo del concepto se cuenta de nuevo
+		if control.EstadoFlujo == services.EstadoPendienteProfesor {
+			return h.slaService.AsignarFechaLimite(tx, &control, time.Now())
+		}
+		return nil
+	})
+	if err != nil {
+		responderErrorTransicion(c, err)
+		return
+	}
+
+	go h.notificationService.NotificarControlReabierto(control.ID, control.CreatedByID, control.ProfesorAsignadoID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Caso reabierto exitosamente",
+		"control": control,
+	})
+}
+
+// DesactivarControl oculta un control de los listados; el motivo es obligatorio
+func (h *ControlOperativoHandler) DesactivarControl(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
+		return
+	}
+
+	var req models.DesactivarControlRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Motivo) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El motivo de desactivación es obligatorio"})
+		return
+	}
+	motivo := strings.TrimSpace(req.Motivo)
+
+	var control models.ControlOperativo
+	if err := h.db.First(&control, uint(controlID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
+		return
+	}
+
+	err = services.ConActor(h.db, user).Transaction(func(tx *gorm.DB) error {
+		return h.workflowService.Desactivar(tx, &control, user, motivo)
+	})
+	if err != nil {
+		responderErrorTransicion(c, err)
+		return
+	}
+
+	go h.notificationService.NotificarCambioActivacion(control.ID, control.CreatedByID, control.ProfesorAsignadoID, false, motivo)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Control desactivado exitosamente",
+		"control": control,
+	})
+}
+
+// ListarDesactivados retorna los controles desactivados para que el coordinador pueda restaurarlos
+// Desactivar o restaurar cambia la lista, así que la respuesta no se guarda en cache
+func (h *ControlOperativoHandler) ListarDesactivados(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	pagination := services.PaginationParams{
+		Page:   1,
+		Limit:  20,
+		Sort:   "desactivado_at",
+		Order:  "desc",
+		Search: c.Query("search"),
+	}
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		pagination.Page = page
+	}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit > 0 && limit <= 100 {
+		pagination.Limit = limit
+	}
+
+	filters := services.FilterParams{
+		Activo:          &[]bool{false}[0],
+		IncluirCerrados: true,
+	}
+
+	result, err := h.queryService.GetControlesOperativos(pagination, filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo controles desactivados"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RestaurarControl vuelve a activar un control desactivado
+func (h *ControlOperativoHandler) RestaurarControl(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
+		return
+	}
+
+	var req models.RestaurarControlRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+	motivo := strings.TrimSpace(req.Motivo)
+
+	var control models.ControlOperativo
+	if err := h.db.First(&control, uint(controlID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
+		return
+	}
+
+	err = services.ConActor(h.db, user).Transaction(func(tx *gorm.DB) error {
+		if err := h.workflowService.Restaurar(tx, &control, user, motivo); err != nil {
+			return err
+		}
+		// El tiempo que estuvo desactivado no cuenta contra el plazo del profesor
+		if control.EstadoFlujo == services.EstadoPendienteProfesor {
+			return h.slaService.AsignarFechaLimite(tx, &control, time.Now())
+		}
+		return nil
+	})
+	if err != nil {
+		responderErrorTransicion(c, err)
+		return
+	}
+
+	go h.notificationService.NotificarCambioActivacion(control.ID, control.CreatedByID, control.ProfesorAsignadoID, true, motivo)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Control restaurado exitosamente",
+		"control": control,
+	})
+}
+
+// UploadTempFile maneja la subida temporal de archivos
+func (h *ControlOperativoHandler) UploadTempFile(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	// Obtener el archivo del formulario
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No se pudo obtener el archivo"})
+		return
+	}
+
+	// Validar que sea PDF o un formato que el servidor pueda convertir a PDF
+	if !h.pdfGenerator.AceptaAdjunto(file.Filename) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Solo se permiten archivos PDF, imágenes JPG o PNG y documentos Word (DOCX)"})
+		return
+	}
+
+	// Validar tamaño (5MB máximo)
+	if file.Size > 5*1024*1024 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El archivo excede el límite de 5MB"})
+		return
+	}
+
+	// Validar que el contenido corresponda a la extensión
+	contenido, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No se pudo leer el archivo"})
+		return
+	}
+	cabecera := make([]byte, 512)
+	n, _ := io.ReadFull(contenido, cabecera)
+	contenido.Close()
+	if err := pdf.ValidarContenidoAdjunto(file.Filename, cabecera[:n]); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Archivo inválido: " + err.Error()})
+		return
+	}
+
+	// Crear directorio temporal si no existe
+	tempDir := "storage/uploads/temp"
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creando directorio temporal"})
+		return
+	}
+
+	// Generar nombre único para el archivo
+	timestamp := time.Now().Unix()
+	filename := fmt.Sprintf("%d_%d_%s", user.ID, timestamp, file.Filename)
+	filepath := filepath.Join(tempDir, filename)
+
+	// Guardar archivo temporalmente
+	if err := c.SaveUploadedFile(file, filepath); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error guardando archivo"})
+		return
+	}
+
+	respuesta := gin.H{
+		"message":  "Archivo subido exitosamente",
+		"filename": filename,
+		"size":     file.Size,
+		"original": file.Filename,
+	}
+
+	// Un PDF cifrado o dañado, una imagen que no se puede convertir o un ZIP que no es un documento
+	// de Word no podría anexarse al PDF del control, así que se rechaza de una vez
+	switch strings.ToLower(path.Ext(file.Filename)) {
+	case ".pdf":
+		datos, err := os.ReadFile(filepath)
+		if err != nil {
+			os.Remove(filepath)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error leyendo archivo"})
+			return
+		}
+		paginas, err := pdf.ContarPaginas(datos)
+		if err != nil {
+			os.Remove(filepath)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Archivo inválido: " + err.Error()})
+			return
+		}
+		respuesta["paginas"] = paginas
+	case ".jpg", ".jpeg", ".png":
+		datos, err := os.ReadFile(filepath)
+		if err != nil {
+			os.Remove(filepath)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error leyendo archivo"})
+			return
+		}
+		if err := pdf.ValidarImagenAdjunto(datos); err != nil {
+			os.Remove(filepath)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Archivo inválido: " + err.Error()})
+			return
+		}
+	case ".docx":
+		datos, err := os.ReadFile(filepath)
+		if err != nil {
+			os.Remove(filepath)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error leyendo archivo"})
+			return
+		}
+		if err := pdf.ValidarDocumentoWord(datos); err != nil {
+			os.Remove(filepath)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Archivo inválido: " + err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, respuesta)
+}
+
+// procesarDocumentosAdjuntos mueve a la ubicación final los archivos temporales que subió el usuario y
+// convierte a PDF las imágenes y documentos Word para poder anexarlos al PDF del control
+func (h *ControlOperativoHandler) procesarDocumentosAdjuntos(controlID uint, userID uint, documentos []string) {
+	finalDir := fmt.Sprintf("storage/uploads/control-operativo/%d", controlID)
+
+	for _, filename := range documentos {
+		// Solo se asocian archivos que el mismo usuario subió; los de otros se tratan como inexistentes
+		movido, err := services.MoverArchivoTemporal(filename, userID, finalDir)
+		if err != nil {
+			fmt.Printf("Error asociando archivo %s: %v\n", filename, err)
+			continue
+		}
+
+		// Crear registro en base de datos
+		documento := models.DocumentoAdjunto{
+			ControlOperativoID: controlID,
+			NombreOriginal:     movido.NombreOriginal,
+			NombreArchivo:      movido.Nombre,
+			TipoArchivo:        pdf.TipoMIMEAdjunto(movido.Nombre),
+			TamanoBytes:        movido.TamanoBytes,
+			RutaArchivo:        movido.Ruta,
+		}
+
+		// Un adjunto que no se pudo convertir se conserva, pero no se anexa al PDF del control
+		if rutaPDF, err := h.pdfGenerator.ConvertirAdjunto(movido.Ruta); err != nil {
+			fmt.Printf("Error convirtiendo documento %s a PDF: %v\n", movido.Nombre, err)
+		} else {
+			documento.ConvertidoPDF = true
+			documento.RutaPDF = rutaPDF
 		}
 
 		if err := h.db.Create(&documento).Error; err != nil {
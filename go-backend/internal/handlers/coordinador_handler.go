@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,12 +18,18 @@ import (
 type CoordinadorHandler struct {
 	db                  *gorm.DB
 	notificationService *services.NotificationService
+	workflowService     *services.WorkflowService
+	asignacionService   *services.AsignacionService
+	conciliacionService *services.ConciliacionService
 }
 
-func NewCoordinadorHandler(db *gorm.DB, notificationService *services.NotificationService) *CoordinadorHandler {
+func NewCoordinadorHandler(db *gorm.DB, notificationService *services.NotificationService, workflowService *services.WorkflowService, asignacionService *services.AsignacionService, conciliacionService *services.ConciliacionService) *CoordinadorHandler {
 	return &CoordinadorHandler{
 		db:                  db,
 		notificationService: notificationService,
+		workflowService:     workflowService,
+		asignacionService:   asignacionService,
+		conciliacionService: conciliacionService,
 	}
 }
 
@@ -103,7 +111,7 @@ func (h *CoordinadorHandler) CambiarEstadoUsuario(c *gin.Context) {
 	targetUser.Activo = req.Activo
 	targetUser.UpdatedAt = time.Now()
 
-	if err := h.db.Save(&targetUser).Error; err != nil {
+	if err := services.ConActor(h.db, user).Save(&targetUser).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar usuario"})
 		return
 	}
@@ -116,10 +124,19 @@ func (h *CoordinadorHandler) CambiarEstadoUsuario(c *gin.Context) {
 		h.db.Model(&models.Profesor{}).Where("user_id = ?", targetUser.ID).Update("activo", req.Activo)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	respuesta := gin.H{
 		"message": "Estado del usuario actualizado",
 		"usuario": targetUser,
-	})
+	}
+
+	// Al desactivar un profesor se informan los casos que quedan sin revisor para reasignarlos
+	if targetUser.Role == "profesor" && !req.Activo {
+		if pendientes, err := h.asignacionService.ControlesActivosDeProfesor(targetUser.ID); err == nil && len(pendientes) > 0 {
+			respuesta["controles_por_reasignar"] = pendientes
+		}
+	}
+
+	c.JSON(http.StatusOK, respuesta)
 }
 
 func (h *CoordinadorHandler) ListarControlesCompletos(c *gin.Context) {
@@ -187,14 +204,7 @@ func (h *CoordinadorHandler) AsignarResultado(c *gin.Context) {
 	}
 
 	// Validar que el estado resultado sea válido
-	estadosValidos := map[string]bool{
-		"asesoria_consulta":      true,
-		"auto_reparto":           true,
-		"reparto":                true,
-		"solicitud_conciliacion": true,
-	}
-
-	if !estadosValidos[req.EstadoResultado] {
+	if !services.EsEstadoResultadoValido(req.EstadoResultado) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Estado resultado no válido"})
 		return
 	}
@@ -205,17 +215,19 @@ func (h *CoordinadorHandler) AsignarResultado(c *gin.Context) {
 		return
 	}
 
-	if control.EstadoFlujo != "completo" && control.EstadoFlujo != "con_resultado" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "El control debe estar completo antes de asignar resultado"})
-		return
-	}
-
-	control.EstadoResultado = &req.EstadoResultado
-	control.EstadoFlujo = "con_resultado"
-	control.UpdatedAt = time.Now()
-
-	if err := h.db.Save(&control).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al asignar resultado"})
+	err := services.ConActor(h.db, user).Transaction(func(tx *gorm.DB) error {
+		if err := h.workflowService.Transicionar(tx, &control, services.EstadoConResultado, user,
+			fmt.Sprintf("Resultado asignado por coordinador: %s", req.EstadoResultado),
+			map[string]interface{}{"estado_resultado": req.EstadoResultado}); err != nil {
+			return err
+		}
+		if req.EstadoResultado == services.EstadoResultadoSolicitudConciliacion {
+			return h.conciliacionService.Iniciar(tx, &control)
+		}
+		return nil
+	})
+	if err != nil {
+		responderErrorTransicion(c, err)
 		return
 	}
 
@@ -289,15 +301,7 @@ func (h *CoordinadorHandler) EditarEstadoResultado(c *gin.Context) {
 	}
 
 	// Validar estados permitidos
-	estadosPermitidos := []string{"asesoria_consulta", "reparto", "auto_reparto", "solicitud_conciliacion"}
-	estadoValido := false
-	for _, estado := range estadosPermitidos {
-		if req.EstadoResultado == estado {
-			estadoValido = true
-			break
-		}
-	}
-	if !estadoValido {
+	if !services.EsEstadoResultadoValido(req.EstadoResultado) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Estado resultado no válido"})
 		return
 	}
@@ -309,22 +313,25 @@ func (h *CoordinadorHandler) EditarEstadoResultado(c *gin.Context) {
 		return
 	}
 
-	// Los coordinadores pueden editar el estado de cualquier control, independientemente del estado actual
 	estadoAnterior := "sin estado"
 	if control.EstadoResultado != nil {
 		estadoAnterior = *control.EstadoResultado
 	}
 
-	// Actualizar estado resultado
-	control.EstadoResultado = &req.EstadoResultado
-	
-	// Si el control no tenía resultado, cambiar flujo a con_resultado
-	if control.EstadoFlujo == "completo" {
-		control.EstadoFlujo = "con_resultado"
-	}
-
-	if err := h.db.Save(&control).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando estado"})
+	// El resultado solo puede editarse una vez el profesor emitió su concepto
+	err := services.ConActor(h.db, user).Transaction(func(tx *gorm.DB) error {
+		if err := h.workflowService.Transicionar(tx, &control, services.EstadoConResultado, user,
+			fmt.Sprintf("Estado resultado editado por coordinador: %s → %s", estadoAnterior, req.EstadoResultado),
+			map[string]interface{}{"estado_resultado": req.EstadoResultado}); err != nil {
+			return err
+		}
+		if req.EstadoResultado == services.EstadoResultadoSolicitudConciliacion {
+			return h.conciliacionService.Iniciar(tx, &control)
+		}
+		return nil
+	})
+	if err != nil {
+		responderErrorTransicion(c, err)
 		return
 	}
 
@@ -373,13 +380,23 @@ func (h *CoordinadorHandler) ObtenerEstadisticasCompletas(c *gin.Context) {
 	var args []interface{}
 	
 	if ano != "" {
-		whereClause += " AND fecha_ano = ?"
-		args = append(args, ano)
+		valor, err := strconv.Atoi(ano)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Año inválido"})
+			return
+		}
+		whereClause += " AND EXTRACT(YEAR FROM fecha_atencion) = ?"
+		args = append(args, valor)
 	}
 	
 	if mes != "" {
-		whereClause += " AND fecha_mes = ?"
-		args = append(args, mes)
+		valor, err := strconv.Atoi(mes)
+		if err != nil || valor < 1 || valor > 12 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Mes inválido"})
+			return
+		}
+		whereClause += " AND EXTRACT(MONTH FROM fecha_atencion) = ?"
+		args = append(args, valor)
 	}
 
 	// 1. ESTADÍSTICAS GENERALES
@@ -387,11 +404,13 @@ func (h *CoordinadorHandler) ObtenerEstadisticasCompletas(c *gin.Context) {
 	var controlesPendientes int64
 	var controlesCompletos int64
 	var controlesConResultado int64
+	var controlesEnCorreccion int64
 	
 	h.db.Model(&models.ControlOperativo{}).Where(whereClause, args...).Count(&totalControles)
 	h.db.Model(&models.ControlOperativo{}).Where(whereClause+" AND estado_flujo = 'pendiente_profesor'", args...).Count(&controlesPendientes)
 	h.db.Model(&models.ControlOperativo{}).Where(whereClause+" AND estado_flujo = 'completo'", args...).Count(&controlesCompletos)
 	h.db.Model(&models.ControlOperativo{}).Where(whereClause+" AND estado_flujo = 'con_resultado'", args...).Count(&controlesConResultado)
+	h.db.Model(&models.ControlOperativo{}).Where(whereClause+" AND estado_flujo = 'requiere_correcciones'", args...).Count(&controlesEnCorreccion)
 
 	// 2. DISTRIBUCIÓN POR ÁREAS JURÍDICAS (EXHAUSTIVA)
 	var distribucionAreas []struct {
@@ -508,13 +527,13 @@ func (h *CoordinadorHandler) ObtenerEstadisticasCompletas(c *gin.Context) {
 
 	rowsTendencias, err := h.db.Raw(`
 		SELECT 
-			fecha_mes as mes,
-			fecha_ano as ano,
+			EXTRACT(MONTH FROM fecha_atencion)::int as mes,
+			EXTRACT(YEAR FROM fecha_atencion)::int as ano,
 			COUNT(*) as cantidad
 		FROM control_operativos 
-		WHERE activo = true AND fecha_mes IS NOT NULL AND fecha_ano IS NOT NULL
-		GROUP BY fecha_ano, fecha_mes
-		ORDER BY fecha_ano DESC, fecha_mes DESC
+		WHERE activo = true AND fecha_atencion IS NOT NULL
+		GROUP BY ano, mes
+		ORDER BY ano DESC, mes DESC
 		LIMIT 12
 	`).Rows()
 
@@ -606,6 +625,7 @@ func (h *CoordinadorHandler) ObtenerEstadisticasCompletas(c *gin.Context) {
 			"controles_pendientes":  controlesPendientes,
 			"controles_completos":   controlesCompletos,
 			"controles_con_resultado": controlesConResultado,
+			"controles_en_correccion": controlesEnCorreccion,
 			"estudiantes_activos":   estudiantesActivos,
 			"profesores_activos":    profesoresActivos,
 		},
@@ -620,4 +640,114 @@ func (h *CoordinadorHandler) ObtenerEstadisticasCompletas(c *gin.Context) {
 			"ano": ano,
 		},
 	})
-}
\ No newline at end of file
+}
+
+// responderErrorReasignacion traduce los errores de reasignación a respuestas HTTP
+func responderErrorReasignacion(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrProfesorNoDisponible):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
+	case errors.Is(err, services.ErrTransicionNoPermitida):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reasignando profesor"})
+	}
+}
+
+// ReasignarProfesor cambia el profesor responsable de un control
+func (h *CoordinadorHandler) ReasignarProfesor(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
+		return
+	}
+
+	var req models.ReasignarProfesorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	var reasignaciones []services.Reasignacion
+	err = services.ConActor(h.db, user).Transaction(func(tx *gorm.DB) error {
+		var err error
+		reasignaciones, err = h.asignacionService.ReasignarVarios(tx, []uint{uint(controlID)}, req.ProfesorID, user, req.Motivo)
+		return err
+	})
+	if err != nil {
+		responderErrorReasignacion(c, err)
+		return
+	}
+
+	if len(reasignaciones) == 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "El control ya está asignado a ese profesor"})
+		return
+	}
+
+	go h.notificationService.NotificarReasignacion(reasignaciones[0])
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Profesor reasignado exitosamente",
+		"reasignacion": reasignaciones[0],
+	})
+}
+
+// ReasignarProfesorMasivo mueve varios controles a otro profesor, por ejemplo cuando un profesor sale a licencia
+func (h *CoordinadorHandler) ReasignarProfesorMasivo(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var req models.ReasignacionMasivaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	controlIDs := req.ControlIDs
+	if len(controlIDs) == 0 && req.ProfesorAnteriorID != nil {
+		ids, err := h.asignacionService.ControlesActivosDeProfesor(*req.ProfesorAnteriorID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo controles del profesor"})
+			return
+		}
+		controlIDs = ids
+	}
+	if len(controlIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Debes indicar control_ids o un profesor_anterior_id con casos en revisión"})
+		return
+	}
+
+	var reasignaciones []services.Reasignacion
+	err := services.ConActor(h.db, user).Transaction(func(tx *gorm.DB) error {
+		var err error
+		reasignaciones, err = h.asignacionService.ReasignarVarios(tx, controlIDs, req.ProfesorID, user, req.Motivo)
+		return err
+	})
+	if err != nil {
+		responderErrorReasignacion(c, err)
+		return
+	}
+
+	go func() {
+		for _, reasignacion := range reasignaciones {
+			h.notificationService.NotificarReasignacion(reasignacion)
+		}
+	}()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        fmt.Sprintf("%d controles reasignados exitosamente", len(reasignaciones)),
+		"total":          len(reasignaciones),
+		"reasignaciones": reasignaciones,
+	})
+}
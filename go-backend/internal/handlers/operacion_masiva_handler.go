@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"consultorio-juridico/internal/models"
+	"consultorio-juridico/internal/services"
+	"consultorio-juridico/pkg/auth"
+)
+
+// OperacionMasivaHandler expone las operaciones de coordinación sobre muchos controles a la vez
+type OperacionMasivaHandler struct {
+	operacionMasivaService *services.OperacionMasivaService
+}
+
+func NewOperacionMasivaHandler(operacionMasivaService *services.OperacionMasivaService) *OperacionMasivaHandler {
+	return &OperacionMasivaHandler{operacionMasivaService: operacionMasivaService}
+}
+
+// responderOperacionMasiva retorna el reporte por control o traduce el error de la operación
+func responderOperacionMasiva(c *gin.Context, reporte *services.ReporteOperacionMasiva, err error) {
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, reporte)
+	case errors.Is(err, services.ErrSeleccionMasivaInvalida), errors.Is(err, services.ErrProfesorNoDisponible):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error ejecutando la operación masiva"})
+	}
+}
+
+// usuarioOperacionMasiva obtiene el coordinador autenticado y decodifica la solicitud
+func usuarioOperacionMasiva(c *gin.Context, req interface{}) (*models.User, bool) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return nil, false
+	}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return nil, false
+	}
+	return user, true
+}
+
+// AsignarResultados establece el mismo estado resultado en varios controles, por ejemplo al cierre de semestre
+func (h *OperacionMasivaHandler) AsignarResultados(c *gin.Context) {
+	var req services.ResultadoMasivoRequest
+	user, ok := usuarioOperacionMasiva(c, &req)
+	if !ok {
+		return
+	}
+
+	reporte, err := h.operacionMasivaService.AsignarResultados(req, user)
+	responderOperacionMasiva(c, reporte, err)
+}
+
+// ReasignarProfesor mueve a otro profesor los controles indicados por IDs o filtros
+func (h *OperacionMasivaHandler) ReasignarProfesor(c *gin.Context) {
+	var req services.ReasignacionMasivaFiltrosRequest
+	user, ok := usuarioOperacionMasiva(c, &req)
+	if !ok {
+		return
+	}
+
+	reporte, err := h.operacionMasivaService.ReasignarProfesor(req, user)
+	responderOperacionMasiva(c, reporte, err)
+}
+
+// DesactivarControles oculta varios controles de los listados con un mismo motivo
+func (h *OperacionMasivaHandler) DesactivarControles(c *gin.Context) {
+	var req services.DesactivacionMasivaRequest
+	user, ok := usuarioOperacionMasiva(c, &req)
+	if !ok {
+		return
+	}
+
+	reporte, err := h.operacionMasivaService.Desactivar(req, user)
+	responderOperacionMasiva(c, reporte, err)
+}
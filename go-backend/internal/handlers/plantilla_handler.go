@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"consultorio-juridico/internal/models"
+	"consultorio-juridico/internal/services"
+	"consultorio-juridico/pkg/auth"
+)
+
+type PlantillaHandler struct {
+	plantillaService *services.PlantillaService
+}
+
+func NewPlantillaHandler(plantillaService *services.PlantillaService) *PlantillaHandler {
+	return &PlantillaHandler{plantillaService: plantillaService}
+}
+
+// responderErrorPlantillaArea traduce los errores de administración de plantillas
+func responderErrorPlantillaArea(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrPlantillaNoEncontrada):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Plantilla no encontrada"})
+	case errors.Is(err, services.ErrPlantillaInvalida):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPlantillaDuplicada):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error guardando plantilla"})
+	}
+}
+
+// ListarPlantillas retorna las plantillas activas para guiar la recepción del caso
+func (h *PlantillaHandler) ListarPlantillas(c *gin.Context) {
+	// Las plantillas se validan al crear el control, así que siempre deben leerse actualizadas
+	c.Header("Cache-Control", "no-store")
+
+	plantillas, err := h.plantillaService.Listar(true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo plantillas"})
+		return
+	}
+	c.JSON(http.StatusOK, plantillas)
+}
+
+// ObtenerPlantillaArea retorna la plantilla activa del área indicada en ?area=
+func (h *PlantillaHandler) ObtenerPlantillaArea(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	area := strings.TrimSpace(c.Query("area"))
+	if area == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El área es requerida"})
+		return
+	}
+
+	plantilla, err := h.plantillaService.ObtenerPorArea(area)
+	if errors.Is(err, services.ErrPlantillaNoEncontrada) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "El área no tiene plantilla"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo plantilla"})
+		return
+	}
+	c.JSON(http.StatusOK, plantilla)
+}
+
+// ListarTodasPlantillas retorna también las plantillas inactivas para administrarlas
+func (h *PlantillaHandler) ListarTodasPlantillas(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	plantillas, err := h.plantillaService.Listar(false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo plantillas"})
+		return
+	}
+	c.JSON(http.StatusOK, plantillas)
+}
+
+// CrearPlantilla registra la plantilla de un área
+func (h *PlantillaHandler) CrearPlantilla(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var req models.PlantillaAreaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	plantilla, err := h.plantillaService.Crear(&req, user)
+	if err != nil {
+		responderErrorPlantillaArea(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Plantilla creada exitosamente",
+		"plantilla": plantilla,
+	})
+}
+
+// ActualizarPlantilla reemplaza preguntas, texto sugerido y campos de la plantilla
+func (h *PlantillaHandler) ActualizarPlantilla(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de plantilla inválido"})
+		return
+	}
+
+	var req models.PlantillaAreaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	plantilla, err := h.plantillaService.Actualizar(uint(id), &req, user)
+	if err != nil {
+		responderErrorPlantillaArea(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Plantilla actualizada exitosamente",
+		"plantilla": plantilla,
+	})
+}
+
+// EliminarPlantilla borra la plantilla de un área
+func (h *PlantillaHandler) EliminarPlantilla(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de plantilla inválido"})
+		return
+	}
+
+	if err := h.plantillaService.Eliminar(uint(id), user); err != nil {
+		responderErrorPlantillaArea(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Plantilla eliminada exitosamente"})
+}
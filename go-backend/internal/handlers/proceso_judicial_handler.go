@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+	"consultorio-juridico/internal/services"
+	"consultorio-juridico/pkg/auth"
+)
+
+type ProcesoJudicialHandler struct {
+	db                     *gorm.DB
+	procesoJudicialService *services.ProcesoJudicialService
+}
+
+func NewProcesoJudicialHandler(db *gorm.DB, procesoJudicialService *services.ProcesoJudicialService) *ProcesoJudicialHandler {
+	return &ProcesoJudicialHandler{
+		db:                     db,
+		procesoJudicialService: procesoJudicialService,
+	}
+}
+
+// responderErrorProceso traduce los errores del proceso judicial a respuestas HTTP
+func responderErrorProceso(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrProcesoNoEncontrado), errors.Is(err, services.ErrActuacionNoEncontrada):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrProcesoNoAplica):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrProcesoInvalido):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrActorNoAutorizado):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Solo el autor o un coordinador pueden eliminar esta actuación"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error procesando el proceso judicial"})
+	}
+}
+
+// cargarControlProceso obtiene el control de la ruta y verifica que el usuario pueda verlo
+// El proceso cambia con cada actuación, así que sus respuestas no se guardan en cache
+func (h *ProcesoJudicialHandler) cargarControlProceso(c *gin.Context) (*models.User, *models.ControlOperativo, bool) {
+	c.Header("Cache-Control", "no-store")
+
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return nil, nil, false
+	}
+
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
+		return nil, nil, false
+	}
+
+	var control models.ControlOperativo
+	if err := h.db.First(&control, uint(controlID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
+		return nil, nil, false
+	}
+
+	if !puedeAccederControl(user, &control) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para ver este control"})
+		return nil, nil, false
+	}
+	return user, &control, true
+}
+
+// ObtenerProcesoJudicial retorna la radicación y las actuaciones del caso
+func (h *ProcesoJudicialHandler) ObtenerProcesoJudicial(c *gin.Context) {
+	_, control, ok := h.cargarControlProceso(c)
+	if !ok {
+		return
+	}
+
+	proceso, err := h.procesoJudicialService.Obtener(control.ID)
+	if err != nil {
+		responderErrorProceso(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"control_id": control.ID, "proceso": proceso})
+}
+
+// GuardarProcesoJudicial registra o corrige los datos de radicación (despacho, radicado, fecha, tipo)
+func (h *ProcesoJudicialHandler) GuardarProcesoJudicial(c *gin.Context) {
+	user, control, ok := h.cargarControlProceso(c)
+	if !ok {
+		return
+	}
+
+	var req models.ProcesoJudicialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	proceso, err := h.procesoJudicialService.Guardar(control, req, user)
+	if err != nil {
+		responderErrorProceso(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Proceso judicial guardado exitosamente",
+		"proceso": proceso,
+	})
+}
+
+// AgregarActuacion registra una actuación procesal del caso
+func (h *ProcesoJudicialHandler) AgregarActuacion(c *gin.Context) {
+	user, control, ok := h.cargarControlProceso(c)
+	if !ok {
+		return
+	}
+
+	var req models.ActuacionProcesalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	proceso, err := h.procesoJudicialService.Obtener(control.ID)
+	if err != nil {
+		responderErrorProceso(c, err)
+		return
+	}
+
+	actuacion, err := h.procesoJudicialService.AgregarActuacion(proceso, req, user)
+	if err != nil {
+		responderErrorProceso(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Actuación registrada exitosamente",
+		"actuacion": actuacion,
+	})
+}
+
+// EliminarActuacion borra una actuación registrada por error
+func (h *ProcesoJudicialHandler) EliminarActuacion(c *gin.Context) {
+	user, control, ok := h.cargarControlProceso(c)
+	if !ok {
+		return
+	}
+
+	actuacionID, err := strconv.ParseUint(c.Param("actuacionId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de actuación inválido"})
+		return
+	}
+
+	proceso, err := h.procesoJudicialService.Obtener(control.ID)
+	if err != nil {
+		responderErrorProceso(c, err)
+		return
+	}
+
+	if err := h.procesoJudicialService.EliminarActuacion(proceso, uint(actuacionID), user); err != nil {
+		responderErrorProceso(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Actuación eliminada exitosamente"})
+}
+
+// ListarProcesosActivos muestra al coordinador los procesos judiciales activos de cada estudiante
+func (h *ProcesoJudicialHandler) ListarProcesosActivos(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	var estudianteID uint64
+	if valor := c.Query("estudiante_id"); valor != "" {
+		id, err := strconv.ParseUint(valor, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ID de estudiante inválido"})
+			return
+		}
+		estudianteID = id
+	}
+
+	estudiantes, err := h.procesoJudicialService.ListarActivosPorEstudiante(uint(estudianteID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo procesos judiciales"})
+		return
+	}
+
+	total := 0
+	for _, estudiante := range estudiantes {
+		total += len(estudiante.Procesos)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"estudiantes":    estudiantes,
+		"total_procesos": total,
+	})
+}
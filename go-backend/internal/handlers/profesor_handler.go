@@ -3,9 +3,7 @@ package handlers
 import (
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -18,12 +16,14 @@ import (
 type ProfesorHandler struct {
 	db                  *gorm.DB
 	notificationService *services.NotificationService
+	workflowService     *services.WorkflowService
 }
 
-func NewProfesorHandler(db *gorm.DB, notificationService *services.NotificationService) *ProfesorHandler {
+func NewProfesorHandler(db *gorm.DB, notificationService *services.NotificationService, workflowService *services.WorkflowService) *ProfesorHandler {
 	return &ProfesorHandler{
 		db:                  db,
 		notificationService: notificationService,
+		workflowService:     workflowService,
 	}
 }
 
@@ -103,6 +103,7 @@ func (h *ProfesorHandler) ObtenerControlesAsignados(c *gin.Context) {
 		controlMap := map[string]interface{}{
 			"id":                          control.ID,
 			"ciudad":                      control.Ciudad,
+			"fecha_atencion":              control.FechaAtencion,
 			"fecha_dia":                   control.FechaDia,
 			"fecha_mes":                   control.FechaMes,
 			"fecha_ano":                   control.FechaAno,
@@ -113,6 +114,7 @@ func (h *ProfesorHandler) ObtenerControlesAsignados(c *gin.Context) {
 			"correo_electronico":         control.CorreoElectronico,
 			"nombre_consultante":         control.NombreConsultante,
 			"edad":                       control.Edad,
+			"fecha_nacimiento":           control.FechaNacimiento,
 			"fecha_nacimiento_dia":       control.FechaNacimientoDia,
 			"fecha_nacimiento_mes":       control.FechaNacimientoMes,
 			"fecha_nacimiento_ano":       control.FechaNacimientoAno,
@@ -167,43 +169,70 @@ func (h *ProfesorHandler) CompletarConcepto(c *gin.Context) {
 		return
 	}
 
-	// UPDATE directo sin SELECT previo - MÁXIMO RENDIMIENTO
-	result := h.db.Model(&models.ControlOperativo{}).
-		Where("id = ? AND profesor_asignado_id = ? AND activo = true", controlID, user.ID).
-		Updates(map[string]interface{}{
-			"concepto_asesor": request.ConceptoAsesor,
-			"estado_flujo":    "completo",
-			"updated_at":      time.Now(),
-		})
-
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al guardar concepto"})
+	var control models.ControlOperativo
+	if err := h.db.Where("id = ? AND profesor_asignado_id = ? AND activo = true", controlID, user.ID).First(&control).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control no encontrado o no asignado"})
 		return
 	}
 
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Control no encontrado o no asignado"})
+	err := services.ConActor(h.db, user).Transaction(func(tx *gorm.DB) error {
+		return h.workflowService.Transicionar(tx, &control, services.EstadoCompleto, user,
+			"Concepto del asesor registrado",
+			map[string]interface{}{"concepto_asesor": request.ConceptoAsesor})
+	})
+	if err != nil {
+		responderErrorTransicion(c, err)
 		return
 	}
 
 	// Notificación async (no bloqueante)
-	go func() {
-		// Solo buscar created_by_id para notificación
-		var createdByID uint
-		h.db.Model(&models.ControlOperativo{}).
-			Select("created_by_id").
-			Where("id = ?", controlID).
-			Scan(&createdByID)
-		
-		if createdByID > 0 {
-			controlIDUint, _ := strconv.ParseUint(controlID, 10, 32)
-			h.notificationService.NotificarControlCompletadoAEstudiante(uint(controlIDUint), createdByID)
-		}
-	}()
+	go h.notificationService.NotificarControlCompletadoAEstudiante(control.ID, control.CreatedByID)
 
 	// Respuesta inmediata
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Concepto guardado exitosamente",
 		"status":  "completed",
 	})
-}
\ No newline at end of file
+}
+
+// DevolverControl envía el control al estudiante para que corrija su concepto
+func (h *ProfesorHandler) DevolverControl(c *gin.Context) {
+	controlID := c.Param("id")
+	var request models.DevolverControlRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil || strings.TrimSpace(request.Observaciones) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Las observaciones son obligatorias"})
+		return
+	}
+
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var control models.ControlOperativo
+	if err := h.db.Where("id = ? AND profesor_asignado_id = ? AND activo = true", controlID, user.ID).First(&control).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control no encontrado o no asignado"})
+		return
+	}
+
+	observaciones := strings.TrimSpace(request.Observaciones)
+	var ronda *models.RondaCorreccion
+	err := services.ConActor(h.db, user).Transaction(func(tx *gorm.DB) error {
+		var err error
+		ronda, err = h.workflowService.DevolverAEstudiante(tx, &control, user, observaciones)
+		return err
+	})
+	if err != nil {
+		responderErrorTransicion(c, err)
+		return
+	}
+
+	go h.notificationService.NotificarControlDevueltoAEstudiante(control.ID, control.CreatedByID, observaciones)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Control devuelto al estudiante para correcciones",
+		"ronda":   ronda,
+	})
+}
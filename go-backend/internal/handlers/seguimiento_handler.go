@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+	"consultorio-juridico/internal/services"
+	"consultorio-juridico/pkg/auth"
+)
+
+type SeguimientoHandler struct {
+	db                 *gorm.DB
+	seguimientoService *services.SeguimientoService
+}
+
+func NewSeguimientoHandler(db *gorm.DB, seguimientoService *services.SeguimientoService) *SeguimientoHandler {
+	return &SeguimientoHandler{
+		db:                 db,
+		seguimientoService: seguimientoService,
+	}
+}
+
+// cargarControl obtiene el control de la ruta y verifica que el usuario pueda verlo
+// Los seguimientos cambian con cada registro, así que sus respuestas no se guardan en cache
+func (h *SeguimientoHandler) cargarControl(c *gin.Context) (*models.User, *models.ControlOperativo, bool) {
+	c.Header("Cache-Control", "no-store")
+
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return nil, nil, false
+	}
+
+	controlID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de control inválido"})
+		return nil, nil, false
+	}
+
+	var control models.ControlOperativo
+	if err := h.db.First(&control, uint(controlID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Control operativo no encontrado"})
+		return nil, nil, false
+	}
+
+	if !puedeAccederControl(user, &control) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para ver este control"})
+		return nil, nil, false
+	}
+	return user, &control, true
+}
+
+// cargarSeguimiento obtiene la actuación de la ruta; solo su autor o un coordinador pueden modificarla
+func (h *SeguimientoHandler) cargarSeguimiento(c *gin.Context) (*models.User, *models.Seguimiento, bool) {
+	user, control, ok := h.cargarControl(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	seguimientoID, err := strconv.ParseUint(c.Param("seguimientoId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de seguimiento inválido"})
+		return nil, nil, false
+	}
+
+	seguimiento, err := h.seguimientoService.Obtener(control.ID, uint(seguimientoID))
+	if errors.Is(err, services.ErrSeguimientoNoEncontrado) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Seguimiento no encontrado"})
+		return nil, nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo seguimiento"})
+		return nil, nil, false
+	}
+
+	if user.Role != "coordinador" && seguimiento.AutorID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Solo el autor o un coordinador pueden modificar este seguimiento"})
+		return nil, nil, false
+	}
+	return user, seguimiento, true
+}
+
+// ListarSeguimientos retorna las actuaciones registradas sobre el control
+func (h *SeguimientoHandler) ListarSeguimientos(c *gin.Context) {
+	_, control, ok := h.cargarControl(c)
+	if !ok {
+		return
+	}
+
+	seguimientos, err := h.seguimientoService.Listar(control.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo seguimientos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"control_id":   control.ID,
+		"tipos":        services.TiposSeguimiento,
+		"seguimientos": seguimientos,
+	})
+}
+
+// CrearSeguimiento registra una actuación (llamada, carta, audiencia...) sobre el control
+func (h *SeguimientoHandler) CrearSeguimiento(c *gin.Context) {
+	user, control, ok := h.cargarControl(c)
+	if !ok {
+		return
+	}
+
+	var req models.SeguimientoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	seguimiento, err := h.seguimientoService.Crear(control.ID, req, user)
+	if err != nil {
+		responderErrorSeguimiento(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Seguimiento registrado exitosamente",
+		"seguimiento": seguimiento,
+	})
+}
+
+// ActualizarSeguimiento modifica una actuación existente
+func (h *SeguimientoHandler) ActualizarSeguimiento(c *gin.Context) {
+	user, seguimiento, ok := h.cargarSeguimiento(c)
+	if !ok {
+		return
+	}
+
+	var req models.SeguimientoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	if err := h.seguimientoService.Actualizar(seguimiento, req, user); err != nil {
+		responderErrorSeguimiento(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Seguimiento actualizado exitosamente",
+		"seguimiento": seguimiento,
+	})
+}
+
+// EliminarSeguimiento borra una actuación y sus soportes
+func (h *SeguimientoHandler) EliminarSeguimiento(c *gin.Context) {
+	_, seguimiento, ok := h.cargarSeguimiento(c)
+	if !ok {
+		return
+	}
+
+	if err := h.seguimientoService.Eliminar(seguimiento); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error eliminando seguimiento"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Seguimiento eliminado exitosamente"})
+}
+
+// responderErrorSeguimiento distingue los errores de validación de los de base de datos
+func responderErrorSeguimiento(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrSeguimientoInvalido) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Error guardando seguimiento"})
+}
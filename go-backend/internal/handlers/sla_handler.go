@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"consultorio-juridico/internal/services"
+	"consultorio-juridico/pkg/auth"
+)
+
+type SLAHandler struct {
+	slaService *services.SLAService
+}
+
+func NewSLAHandler(slaService *services.SLAService) *SLAHandler {
+	return &SLAHandler{
+		slaService: slaService,
+	}
+}
+
+// ObtenerConfiguracion retorna los plazos vigentes para el concepto del asesor
+// Los plazos cambian al guardar la configuración, así que la respuesta no se guarda en cache
+func (h *SLAHandler) ObtenerConfiguracion(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	c.JSON(http.StatusOK, h.slaService.ObtenerConfiguracion())
+}
+
+// ActualizarConfiguracion guarda los plazos por área y los días de aviso previo
+func (h *SLAHandler) ActualizarConfiguracion(c *gin.Context) {
+	user, exists := auth.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var req services.ConfiguracionSLA
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+	if req.DiasPorArea == nil {
+		req.DiasPorArea = map[string]int{}
+	}
+
+	if err := h.slaService.GuardarConfiguracion(req, user); err != nil {
+		if errors.Is(err, services.ErrConfiguracionSLAInvalida) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error guardando configuración de SLA"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Configuración de SLA actualizada",
+		"configuracion": h.slaService.ObtenerConfiguracion(),
+	})
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"consultorio-juridico/pkg/terminos"
+)
+
+// TerminosHandler expone el calendario de días hábiles y el cálculo de términos legales
+type TerminosHandler struct{}
+
+func NewTerminosHandler() *TerminosHandler {
+	return &TerminosHandler{}
+}
+
+// ListarTiposTermino retorna el catálogo de términos legales
+func (h *TerminosHandler) ListarTiposTermino(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"tipos": terminos.Tipos})
+}
+
+// ListarFestivos retorna los festivos nacionales del año indicado (por defecto el actual)
+func (h *TerminosHandler) ListarFestivos(c *gin.Context) {
+	ano := time.Now().Year()
+	if valor := c.Query("ano"); valor != "" {
+		parsed, err := strconv.Atoi(valor)
+		if err != nil || parsed < 1900 || parsed > 2200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Año inválido"})
+			return
+		}
+		ano = parsed
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"ano":      ano,
+		"festivos": terminos.Festivos(ano),
+	})
+}
+
+// CalcularTermino retorna la fecha de vencimiento de un término desde la fecha indicada.
+// Se indica un tipo del catálogo o una cantidad libre de días hábiles
+func (h *TerminosHandler) CalcularTermino(c *gin.Context) {
+	desde, err := time.Parse("2006-01-02", c.Query("desde"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "desde es requerido en formato AAAA-MM-DD"})
+		return
+	}
+
+	var vencimiento terminos.Vencimiento
+	if tipo := c.Query("tipo"); tipo != "" {
+		vencimiento, err = terminos.Calcular(tipo, desde)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else {
+		dias, err := strconv.Atoi(c.Query("dias"))
+		if err != nil || dias <= 0 || dias > 365 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Indique un tipo de término o una cantidad de días hábiles entre 1 y 365"})
+			return
+		}
+		vencimiento = terminos.Vencimiento{
+			Tipo:             terminos.TipoTermino{Clave: "personalizado", Nombre: "Término personalizado", Dias: dias},
+			FechaInicio:      desde,
+			FechaVencimiento: terminos.SumarDiasHabiles(desde, dias),
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"vencimiento":            vencimiento,
+		"dias_habiles_restantes": terminos.DiasHabilesEntre(time.Now(), vencimiento.FechaVencimiento),
+	})
+}
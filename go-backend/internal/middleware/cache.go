@@ -12,6 +12,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -120,8 +121,9 @@ func CacheMiddleware(ttl time.Duration, excludePaths ...string) gin.HandlerFunc
 		// Procesar petición
 		c.Next()
 
-		// Solo cachear respuestas exitosas
-		if writer.status == http.StatusOK && writer.body.Len() > 0 {
+		// Solo cachear respuestas exitosas que el handler no marcó como no almacenables
+		noStore := strings.Contains(c.Writer.Header().Get("Cache-Control"), "no-store")
+		if writer.status == http.StatusOK && writer.body.Len() > 0 && !noStore {
 			// Capturar headers relevantes
 			headers := make(map[string]string)
 			for k, v := range c.Writer.Header() {
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// Auditoria guarda un cambio a nivel de campo sobre una tabla auditada
+type Auditoria struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Tabla         string    `gorm:"type:varchar(100);not null;index:idx_auditorias_registro" json:"tabla"`
+	RegistroID    uint      `gorm:"not null;index:idx_auditorias_registro" json:"registro_id"`
+	Operacion     string    `gorm:"type:varchar(20);not null" json:"operacion"`
+	Campo         string    `gorm:"type:varchar(100)" json:"campo"`
+	ValorAnterior *string   `gorm:"type:text" json:"valor_anterior"`
+	ValorNuevo    *string   `gorm:"type:text" json:"valor_nuevo"`
+	UsuarioID     *uint     `gorm:"index" json:"usuario_id"`
+	UsuarioRol    string    `gorm:"type:varchar(20)" json:"usuario_rol"`
+	CreatedAt     time.Time `gorm:"index" json:"created_at"`
+	Usuario       *User     `gorm:"foreignKey:UsuarioID" json:"usuario,omitempty"`
+}
+
+// TableName especifica el nombre de tabla para GORM
+func (Auditoria) TableName() string {
+	return "auditorias"
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// BorradorControl guarda el formulario de un control mientras el estudiante lo diligencia.
+// Los datos no se validan hasta que el borrador se envía y se convierte en control operativo
+type BorradorControl struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CreadoPorID uint      `gorm:"not null;index" json:"creado_por_id"`
+	Datos       JSONMap   `gorm:"type:jsonb;not null" json:"datos"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `gorm:"index" json:"updated_at"`
+}
+
+// TableName especifica el nombre de tabla para GORM
+func (BorradorControl) TableName() string {
+	return "borradores_control"
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// Cita es una reunión programada con el consultante sobre un caso
+type Cita struct {
+	ID                    uint       `gorm:"primaryKey" json:"id"`
+	ControlOperativoID    uint       `gorm:"not null;index" json:"control_operativo_id"`
+	Inicio                time.Time  `gorm:"not null;index" json:"inicio"`
+	Fin                   time.Time  `gorm:"not null" json:"fin"`
+	Lugar                 string     `gorm:"type:varchar(255);not null" json:"lugar"`
+	Descripcion           string     `gorm:"type:text" json:"descripcion"`
+	Participantes         string     `gorm:"type:text" json:"participantes"` // asistentes externos (consultante, acompañantes)
+	EstudianteID          uint       `gorm:"not null;index" json:"estudiante_id"`
+	ProfesorID            *uint      `gorm:"index" json:"profesor_id"`
+	CreadoPorID           uint       `gorm:"not null" json:"creado_por_id"`
+	Estado                string     `gorm:"type:varchar(20);default:'programada';index" json:"estado"`
+	MotivoCancelacion     string     `gorm:"type:text" json:"motivo_cancelacion,omitempty"`
+	RecordatorioEnviadoAt *time.Time `json:"-"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+	Estudiante            User       `gorm:"foreignKey:EstudianteID" json:"estudiante,omitempty"`
+	Profesor              *User      `gorm:"foreignKey:ProfesorID" json:"profesor,omitempty"`
+}
+
+type CitaRequest struct {
+	Inicio        time.Time `json:"inicio" binding:"required"`
+	Fin           time.Time `json:"fin" binding:"required"`
+	Lugar         string    `json:"lugar" binding:"required"`
+	Descripcion   string    `json:"descripcion"`
+	Participantes string    `json:"participantes"`
+}
+
+type CancelarCitaRequest struct {
+	Motivo string `json:"motivo" binding:"required"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+)
+
+// Comentario es un mensaje del hilo de discusión entre estudiante, profesor y coordinación sobre un control
+type Comentario struct {
+	ID                 uint                `gorm:"primaryKey" json:"id"`
+	ControlOperativoID uint                `gorm:"not null;index" json:"control_operativo_id"`
+	AutorID            uint                `gorm:"not null" json:"autor_id"`
+	Contenido          string              `gorm:"type:text;not null" json:"contenido"`
+	CreatedAt          time.Time           `json:"created_at"`
+	Autor              User                `gorm:"foreignKey:AutorID" json:"autor,omitempty"`
+	Adjuntos           []ComentarioAdjunto `gorm:"foreignKey:ComentarioID;constraint:OnDelete:CASCADE" json:"adjuntos,omitempty"`
+	Menciones          []ComentarioMencion `gorm:"foreignKey:ComentarioID;constraint:OnDelete:CASCADE" json:"menciones,omitempty"`
+	Lecturas           []ComentarioLectura `gorm:"foreignKey:ComentarioID;constraint:OnDelete:CASCADE" json:"lecturas,omitempty"`
+}
+
+// ComentarioAdjunto es un archivo compartido en el hilo
+type ComentarioAdjunto struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	ComentarioID   uint      `gorm:"not null;index" json:"comentario_id"`
+	NombreOriginal string    `gorm:"type:varchar(255)" json:"nombre_original"`
+	NombreArchivo  string    `gorm:"type:varchar(255)" json:"nombre_archivo"`
+	TipoArchivo    string    `gorm:"type:varchar(50)" json:"tipo_archivo"`
+	TamanoBytes    int64     `json:"tamano_bytes"`
+	RutaArchivo    string    `gorm:"type:varchar(500)" json:"-"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ComentarioMencion registra a un usuario mencionado con @nombre_usuario
+type ComentarioMencion struct {
+	ID           uint `gorm:"primaryKey" json:"id"`
+	ComentarioID uint `gorm:"not null;uniqueIndex:idx_comentario_mencion" json:"comentario_id"`
+	UserID       uint `gorm:"not null;uniqueIndex:idx_comentario_mencion" json:"user_id"`
+	User         User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName especifica el nombre de tabla para GORM
+func (ComentarioMencion) TableName() string {
+	return "comentario_menciones"
+}
+
+// ComentarioLectura es la confirmación de lectura de un comentario por un usuario
+type ComentarioLectura struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ComentarioID uint      `gorm:"not null;uniqueIndex:idx_comentario_lectura" json:"comentario_id"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_comentario_lectura;index" json:"user_id"`
+	LeidoAt      time.Time `gorm:"not null" json:"leido_at"`
+	User         User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+type ComentarioRequest struct {
+	Contenido string   `json:"contenido" binding:"required"`
+	Adjuntos  []string `json:"adjuntos,omitempty"` // nombres devueltos por /api/upload/temp
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+)
+
+// Conciliacion acompaña el trámite de un control cuyo resultado es solicitud_conciliacion
+type Conciliacion struct {
+	ID                 uint                `gorm:"primaryKey" json:"id"`
+	ControlOperativoID uint                `gorm:"not null;uniqueIndex" json:"control_operativo_id"`
+	Estado             string              `gorm:"type:varchar(20);default:'en_tramite'" json:"estado"`
+	FechaAudiencia     *time.Time          `json:"fecha_audiencia"`
+	LugarAudiencia     string              `gorm:"type:varchar(255)" json:"lugar_audiencia"`
+	Conciliador        string              `gorm:"type:varchar(200)" json:"conciliador"`
+	Resultado          *string             `gorm:"type:varchar(30)" json:"resultado"`
+	Acuerdos           string              `gorm:"type:text" json:"acuerdos"`
+	Observaciones      string              `gorm:"type:text" json:"observaciones"`
+	FechaResultado     *time.Time          `json:"fecha_resultado"`
+	RegistradoPorID    *uint               `json:"registrado_por_id"`
+	CreatedAt          time.Time           `json:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at"`
+	Partes             []ConciliacionParte `gorm:"foreignKey:ConciliacionID;constraint:OnDelete:CASCADE" json:"partes"`
+}
+
+// TableName especifica el nombre de tabla para GORM
+func (Conciliacion) TableName() string {
+	return "conciliaciones"
+}
+
+// ConciliacionParte es un convocante o convocado de la audiencia
+type ConciliacionParte struct {
+	ID                uint   `gorm:"primaryKey" json:"id"`
+	ConciliacionID    uint   `gorm:"not null;index" json:"conciliacion_id"`
+	Rol               string `gorm:"type:varchar(20);not null" json:"rol"` // convocante, convocado
+	Nombre            string `gorm:"type:varchar(200);not null" json:"nombre"`
+	TipoDocumento     string `gorm:"type:varchar(50)" json:"tipo_documento"`
+	NumeroDocumento   string `gorm:"type:varchar(50)" json:"numero_documento"`
+	Direccion         string `gorm:"type:varchar(255)" json:"direccion"`
+	Telefono          string `gorm:"type:varchar(50)" json:"telefono"`
+	CorreoElectronico string `gorm:"type:varchar(200)" json:"correo_electronico"`
+	Asistio           *bool  `json:"asistio"`
+}
+
+type ConciliacionParteRequest struct {
+	Rol               string `json:"rol" binding:"required"`
+	Nombre            string `json:"nombre" binding:"required"`
+	TipoDocumento     string `json:"tipo_documento"`
+	NumeroDocumento   string `json:"numero_documento"`
+	Direccion         string `json:"direccion"`
+	Telefono          string `json:"telefono"`
+	CorreoElectronico string `json:"correo_electronico"`
+}
+
+type ConciliacionRequest struct {
+	FechaAudiencia *time.Time                 `json:"fecha_audiencia"`
+	LugarAudiencia string                     `json:"lugar_audiencia"`
+	Conciliador    string                     `json:"conciliador"`
+	Partes         []ConciliacionParteRequest `json:"partes"`
+}
+
+type ResultadoConciliacionRequest struct {
+	Resultado     string        `json:"resultado" binding:"required"`
+	Acuerdos      string        `json:"acuerdos"`
+	Observaciones string        `json:"observaciones"`
+	Asistencia    map[uint]bool `json:"asistencia"` // id de la parte → asistió
+}
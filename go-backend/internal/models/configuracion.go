@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// ConfiguracionSistema guarda parámetros que los coordinadores pueden ajustar sin desplegar
+type ConfiguracionSistema struct {
+	Clave            string    `gorm:"primaryKey;type:varchar(100)" json:"clave"`
+	Valor            string    `gorm:"type:text" json:"valor"`
+	ActualizadoPorID *uint     `json:"actualizado_por_id"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// TableName especifica el nombre de tabla para GORM
+func (ConfiguracionSistema) TableName() string {
+	return "configuracion_sistema"
+}
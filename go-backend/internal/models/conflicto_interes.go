@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+)
+
+// Contraparte es una parte contraria del consultante en el caso
+type Contraparte struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	ControlOperativoID uint      `gorm:"not null;index" json:"control_operativo_id"`
+	Nombre             string    `gorm:"type:varchar(255);not null" json:"nombre"`
+	TipoDocumento      string    `gorm:"type:varchar(10)" json:"tipo_documento"`
+	NumeroDocumento    string    `gorm:"type:varchar(20);index" json:"numero_documento"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// ConflictoInteres registra que una parte del caso aparece del otro lado en un caso existente.
+// Mientras no sea reconocido por un coordinador el caso no avanza en el flujo
+type ConflictoInteres struct {
+	ID                   uint       `gorm:"primaryKey" json:"id"`
+	ControlOperativoID   uint       `gorm:"not null;uniqueIndex:idx_conflicto_control" json:"control_operativo_id"`
+	ControlRelacionadoID uint       `gorm:"not null;uniqueIndex:idx_conflicto_control" json:"control_relacionado_id"`
+	Tipo                 string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_conflicto_control" json:"tipo"`
+	Parte                string     `gorm:"type:varchar(255)" json:"parte"`
+	Documento            string     `gorm:"type:varchar(40)" json:"documento"`
+	Reconocido           bool       `gorm:"default:false" json:"reconocido"`
+	ReconocidoPorID      *uint      `json:"reconocido_por_id"`
+	ReconocidoAt         *time.Time `json:"reconocido_at"`
+	Observaciones        string     `gorm:"type:text" json:"observaciones"`
+	CreatedAt            time.Time  `json:"created_at"`
+	ReconocidoPor        *User      `gorm:"foreignKey:ReconocidoPorID" json:"reconocido_por,omitempty"`
+}
+
+// TableName especifica el nombre de tabla para GORM
+func (ConflictoInteres) TableName() string {
+	return "conflictos_interes"
+}
+
+// ContraparteRequest es una contraparte enviada con el formulario del control
+type ContraparteRequest struct {
+	Nombre          string `json:"nombre" binding:"required"`
+	TipoDocumento   string `json:"tipo_documento"`
+	NumeroDocumento string `json:"numero_documento"`
+}
+
+// ReconocerConflictoRequest es la decisión del coordinador sobre los conflictos del caso
+type ReconocerConflictoRequest struct {
+	Observaciones string `json:"observaciones" binding:"required"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// Consultante es la persona atendida; se identifica por tipo y número de documento
+// y conserva los datos más recientes registrados en sus controles
+type Consultante struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	TipoDocumento      string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_consultantes_documento" json:"tipo_documento"`
+	NumeroDocumento    string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_consultantes_documento" json:"numero_documento"`
+	Nombre             string    `gorm:"type:varchar(255)" json:"nombre"`
+	FechaNacimiento    *time.Time `gorm:"type:date" json:"fecha_nacimiento"`
+	LugarNacimiento    string    `gorm:"type:varchar(255)" json:"lugar_nacimiento"`
+	Sexo               string    `gorm:"type:varchar(20)" json:"sexo"`
+	LugarExpedicion    string    `gorm:"type:varchar(255)" json:"lugar_expedicion"`
+	Direccion          string    `gorm:"type:varchar(255)" json:"direccion"`
+	Barrio             string    `gorm:"type:varchar(100)" json:"barrio"`
+	Estrato            int       `json:"estrato"`
+	NumeroTelefonico   string    `gorm:"type:varchar(20)" json:"numero_telefonico"`
+	NumeroCelular      string    `gorm:"type:varchar(20)" json:"numero_celular"`
+	CorreoElectronico  string    `gorm:"type:varchar(255)" json:"correo_electronico"`
+	EstadoCivil        string    `gorm:"type:varchar(50)" json:"estado_civil"`
+	Escolaridad        string    `gorm:"type:varchar(100)" json:"escolaridad"`
+	ProfesionOficio    string    `gorm:"type:varchar(100)" json:"profesion_oficio"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
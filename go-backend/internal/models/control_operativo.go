@@ -2,14 +2,17 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type ControlOperativo struct {
 	ID                       uint      `gorm:"primaryKey" json:"id"`
 	Ciudad                   string    `gorm:"type:varchar(100)" json:"ciudad"`
-	FechaDia                 int       `gorm:"not null" json:"fecha_dia"`
-	FechaMes                 int       `gorm:"not null" json:"fecha_mes"`
-	FechaAno                 int       `gorm:"not null" json:"fecha_ano"`
+	FechaAtencion            *time.Time `gorm:"type:date;index" json:"fecha_atencion"`
+	FechaDia                 int       `gorm:"-" json:"fecha_dia"`
+	FechaMes                 int       `gorm:"-" json:"fecha_mes"`
+	FechaAno                 int       `gorm:"-" json:"fecha_ano"`
 	NombreDocenteResponsable string    `gorm:"type:varchar(255)" json:"nombre_docente_responsable"`
 	NombreEstudiante         string    `gorm:"type:varchar(255)" json:"nombre_estudiante"`
 	AreaConsulta             string    `gorm:"type:varchar(100)" json:"area_consulta"`
@@ -17,9 +20,10 @@ type ControlOperativo struct {
 	CorreoElectronico        string    `gorm:"type:varchar(255)" json:"correo_electronico"`
 	NombreConsultante        string    `gorm:"type:varchar(255)" json:"nombre_consultante"`
 	Edad                     int       `json:"edad"`
-	FechaNacimientoDia       int       `json:"fecha_nacimiento_dia"`
-	FechaNacimientoMes       int       `json:"fecha_nacimiento_mes"`
-	FechaNacimientoAno       int       `json:"fecha_nacimiento_ano"`
+	FechaNacimiento          *time.Time `gorm:"type:date" json:"fecha_nacimiento"`
+	FechaNacimientoDia       int       `gorm:"-" json:"fecha_nacimiento_dia"`
+	FechaNacimientoMes       int       `gorm:"-" json:"fecha_nacimiento_mes"`
+	FechaNacimientoAno       int       `gorm:"-" json:"fecha_nacimiento_ano"`
 	LugarNacimiento          string    `gorm:"type:varchar(255)" json:"lugar_nacimiento"`
 	Sexo                     string    `gorm:"type:varchar(20)" json:"sexo"`
 	TipoDocumento            string    `gorm:"type:varchar(10)" json:"tipo_documento"`
@@ -43,10 +47,72 @@ type ControlOperativo struct {
 	UpdatedAt                time.Time `json:"updated_at"`
 	CreatedByID              uint      `gorm:"not null" json:"created_by"`
 	ProfesorAsignadoID       *uint     `gorm:"index" json:"profesor_asignado_id"`
+	ConsultanteID            *uint      `gorm:"index" json:"consultante_id"`
+	FechaLimiteConcepto      *time.Time `gorm:"index" json:"fecha_limite_concepto"`
+	SLARecordatorioAt        *time.Time `json:"sla_recordatorio_at"`
+	SLAEscaladoAt            *time.Time `json:"sla_escalado_at"`
+	MotivoCierre             *string    `gorm:"type:varchar(50)" json:"motivo_cierre"`
+	FechaCierre              *time.Time `json:"fecha_cierre"`
+	NotaCierre               string     `gorm:"type:text" json:"nota_cierre"`
+	MotivoDesactivacion      string     `gorm:"type:text" json:"motivo_desactivacion,omitempty"`
+	DesactivadoAt            *time.Time `json:"desactivado_at,omitempty"`
+	DesactivadoPorID         *uint      `json:"desactivado_por_id,omitempty"`
+	ConflictoPendiente       bool       `gorm:"default:false;index" json:"conflicto_pendiente"`
+	RespuestasPlantilla      RespuestasPlantilla `gorm:"type:jsonb" json:"respuestas_plantilla,omitempty"`
 	CreatedBy                User      `gorm:"foreignKey:CreatedByID" json:"created_by_user,omitempty"`
 	ProfesorAsignado         *User     `gorm:"foreignKey:ProfesorAsignadoID" json:"profesor_asignado,omitempty"`
+	Consultante              *Consultante `gorm:"foreignKey:ConsultanteID" json:"consultante,omitempty"`
 	DocumentosAdjuntos       []DocumentoAdjunto `gorm:"foreignKey:ControlOperativoID" json:"documentos_adjuntos,omitempty"`
 	Notificaciones           []Notificacion     `gorm:"foreignKey:ControlOperativoID" json:"notificaciones,omitempty"`
+	Seguimientos             []Seguimiento      `gorm:"foreignKey:ControlOperativoID" json:"seguimientos,omitempty"`
+	Contrapartes             []Contraparte      `gorm:"foreignKey:ControlOperativoID;constraint:OnDelete:CASCADE" json:"contrapartes,omitempty"`
+}
+
+// FechaDesdePartes arma una fecha a partir de año, mes y día; retorna nil si está incompleta
+// o no existe en el calendario
+func FechaDesdePartes(ano, mes, dia int) *time.Time {
+	if ano <= 0 || mes < 1 || mes > 12 || dia < 1 {
+		return nil
+	}
+	fecha := time.Date(ano, time.Month(mes), dia, 0, 0, 0, 0, time.UTC)
+	if fecha.Day() != dia || int(fecha.Month()) != mes {
+		return nil
+	}
+	return &fecha
+}
+
+// partesFecha separa una fecha en año, mes y día; una fecha nula queda en ceros
+func partesFecha(fecha *time.Time) (int, int, int) {
+	if fecha == nil {
+		return 0, 0, 0
+	}
+	return fecha.Year(), int(fecha.Month()), fecha.Day()
+}
+
+// SincronizarFechas lleva a las columnas de fecha lo diligenciado por día, mes y año
+func (c *ControlOperativo) SincronizarFechas() {
+	c.FechaAtencion = FechaDesdePartes(c.FechaAno, c.FechaMes, c.FechaDia)
+	c.FechaNacimiento = FechaDesdePartes(c.FechaNacimientoAno, c.FechaNacimientoMes, c.FechaNacimientoDia)
+}
+
+// AsignarFechaNacimiento cambia la fecha de nacimiento manteniendo sus partes, para que BeforeSave
+// no la reemplace con las partes anteriores
+func (c *ControlOperativo) AsignarFechaNacimiento(fecha *time.Time) {
+	c.FechaNacimiento = fecha
+	c.FechaNacimientoAno, c.FechaNacimientoMes, c.FechaNacimientoDia = partesFecha(fecha)
+}
+
+// BeforeSave hook de GORM para guardar las fechas diligenciadas por partes
+func (c *ControlOperativo) BeforeSave(tx *gorm.DB) error {
+	c.SincronizarFechas()
+	return nil
+}
+
+// AfterFind hook de GORM que expone las fechas también por partes, como las recibe la API
+func (c *ControlOperativo) AfterFind(tx *gorm.DB) error {
+	c.FechaAno, c.FechaMes, c.FechaDia = partesFecha(c.FechaAtencion)
+	c.FechaNacimientoAno, c.FechaNacimientoMes, c.FechaNacimientoDia = partesFecha(c.FechaNacimiento)
+	return nil
 }
 
 type DocumentoAdjunto struct {
@@ -75,22 +141,67 @@ type Notificacion struct {
 	ControlOperativo   ControlOperativo `gorm:"foreignKey:ControlOperativoID" json:"control_operativo,omitempty"`
 }
 
+// ControlOperativoTransicion registra cada cambio de EstadoFlujo de un control
+type ControlOperativoTransicion struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	ControlOperativoID uint      `gorm:"not null;index" json:"control_operativo_id"`
+	EstadoAnterior     string    `gorm:"type:varchar(50)" json:"estado_anterior"`
+	EstadoNuevo        string    `gorm:"type:varchar(50);not null" json:"estado_nuevo"`
+	ActorID            uint      `gorm:"not null" json:"actor_id"`
+	ActorRol           string    `gorm:"type:varchar(20)" json:"actor_rol"`
+	Comentario         string    `gorm:"type:text" json:"comentario"`
+	CreatedAt          time.Time `json:"created_at"`
+	Actor              User      `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+}
+
+// ControlOperativoVersion guarda los datos de una revisión anterior de un control
+type ControlOperativoVersion struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	ControlOperativoID uint      `gorm:"not null;uniqueIndex:idx_control_version" json:"control_operativo_id"`
+	Version            int       `gorm:"not null;uniqueIndex:idx_control_version" json:"version"`
+	Datos              JSONMap   `gorm:"type:jsonb;not null" json:"datos"`
+	EditadoPorID       uint      `gorm:"not null" json:"editado_por_id"`
+	CreatedAt          time.Time `json:"created_at"`
+	EditadoPor         User      `gorm:"foreignKey:EditadoPorID" json:"editado_por,omitempty"`
+}
+
+// RondaCorreccion registra cada devolución del profesor y el reenvío del estudiante
+type RondaCorreccion struct {
+	ID                  uint       `gorm:"primaryKey" json:"id"`
+	ControlOperativoID  uint       `gorm:"not null;uniqueIndex:idx_control_ronda" json:"control_operativo_id"`
+	Ronda               int        `gorm:"not null;uniqueIndex:idx_control_ronda" json:"ronda"`
+	Observaciones       string     `gorm:"type:text;not null" json:"observaciones"`
+	ProfesorID          uint       `gorm:"not null" json:"profesor_id"`
+	FechaDevolucion     time.Time  `json:"fecha_devolucion"`
+	RespuestaEstudiante string     `gorm:"type:text" json:"respuesta_estudiante"`
+	FechaReenvio        *time.Time `json:"fecha_reenvio"`
+	Profesor            User       `gorm:"foreignKey:ProfesorID" json:"profesor,omitempty"`
+}
+
+// TableName especifica el nombre de tabla para GORM
+func (RondaCorreccion) TableName() string {
+	return "rondas_correccion"
+}
+
 // DTOs para requests
+
+// ControlOperativoRequest es el formulario del control; las reglas entre campos (fechas, edad y
+// documento) se registran en pkg/validacion
 type ControlOperativoRequest struct {
 	Ciudad                   string `json:"ciudad" binding:"required"`
-	FechaDia                 int    `json:"fecha_dia" binding:"required"`
-	FechaMes                 int    `json:"fecha_mes" binding:"required"`
-	FechaAno                 int    `json:"fecha_ano" binding:"required"`
-	NombreDocenteResponsable string `json:"nombre_docente_responsable" binding:"required"`
+	FechaDia                 int    `json:"fecha_dia" binding:"required,min=1,max=31"`
+	FechaMes                 int    `json:"fecha_mes" binding:"required,min=1,max=12"`
+	FechaAno                 int    `json:"fecha_ano" binding:"required,min=1990"`
+	NombreDocenteResponsable string `json:"nombre_docente_responsable"`
 	NombreEstudiante         string `json:"nombre_estudiante" binding:"required"`
 	AreaConsulta             string `json:"area_consulta" binding:"required"`
 	RemitidoPor              string `json:"remitido_por"`
-	CorreoElectronico        string `json:"correo_electronico"`
+	CorreoElectronico        string `json:"correo_electronico" binding:"omitempty,email"`
 	NombreConsultante        string `json:"nombre_consultante" binding:"required"`
-	Edad                     int    `json:"edad"`
-	FechaNacimientoDia       int    `json:"fecha_nacimiento_dia"`
-	FechaNacimientoMes       int    `json:"fecha_nacimiento_mes"`
-	FechaNacimientoAno       int    `json:"fecha_nacimiento_ano"`
+	Edad                     int    `json:"edad" binding:"omitempty,min=0,max=120"`
+	FechaNacimientoDia       int    `json:"fecha_nacimiento_dia" binding:"omitempty,min=1,max=31"`
+	FechaNacimientoMes       int    `json:"fecha_nacimiento_mes" binding:"omitempty,min=1,max=12"`
+	FechaNacimientoAno       int    `json:"fecha_nacimiento_ano" binding:"omitempty,min=1900"`
 	LugarNacimiento          string `json:"lugar_nacimiento"`
 	Sexo                     string `json:"sexo"`
 	TipoDocumento            string `json:"tipo_documento"`
@@ -98,15 +209,19 @@ type ControlOperativoRequest struct {
 	LugarExpedicion          string `json:"lugar_expedicion"`
 	Direccion                string `json:"direccion"`
 	Barrio                   string `json:"barrio"`
-	Estrato                  int    `json:"estrato"`
-	NumeroTelefonico         string `json:"numero_telefonico"`
-	NumeroCelular            string `json:"numero_celular"`
+	Estrato                  int    `json:"estrato" binding:"omitempty,min=1,max=6"`
+	NumeroTelefonico         string `json:"numero_telefonico" binding:"omitempty,telefono"`
+	NumeroCelular            string `json:"numero_celular" binding:"omitempty,celular"`
 	EstadoCivil              string `json:"estado_civil"`
 	Escolaridad              string `json:"escolaridad"`
 	ProfesionOficio          string `json:"profesion_oficio"`
 	DescripcionCaso          string `json:"descripcion_caso" binding:"required"`
 	ConceptoEstudiante       string   `json:"concepto_estudiante" binding:"required"`
 	ProfesorID               *uint    `json:"profesor_id"`
+	ConsultanteID            *uint    `json:"consultante_id"` // consultante existente; completa los datos no enviados
+	ConfirmarDuplicado       bool     `json:"confirmar_duplicado"` // crear aunque existan casos similares recientes
+	Contrapartes             []ContraparteRequest `json:"contrapartes" binding:"omitempty,dive"` // en edición, nil conserva las registradas
+	RespuestasPlantilla      map[string]interface{} `json:"respuestas_plantilla"` // campos adicionales de la plantilla del área; en edición, nil conserva las registradas
 	DocumentosAdjuntos       []string `json:"documentos_adjuntos,omitempty"`
 }
 
@@ -114,6 +229,45 @@ type ConceptoAsesorRequest struct {
 	ConceptoAsesor string `json:"concepto_asesor" binding:"required"`
 }
 
+type DevolverControlRequest struct {
+	Observaciones string `json:"observaciones" binding:"required"`
+}
+
+type ReenviarControlRequest struct {
+	Respuesta string `json:"respuesta"`
+}
+
+type ReasignarProfesorRequest struct {
+	ProfesorID uint   `json:"profesor_id" binding:"required"`
+	Motivo     string `json:"motivo"`
+}
+
+// ReasignacionMasivaRequest acepta una lista de controles o todos los casos en revisión de un profesor
+type ReasignacionMasivaRequest struct {
+	ControlIDs         []uint `json:"control_ids"`
+	ProfesorAnteriorID *uint  `json:"profesor_anterior_id"`
+	ProfesorID         uint   `json:"profesor_id" binding:"required"`
+	Motivo             string `json:"motivo"`
+}
+
+type CerrarControlRequest struct {
+	MotivoCierre string `json:"motivo_cierre" binding:"required"`
+	NotaCierre   string `json:"nota_cierre"`
+	FechaCierre  string `json:"fecha_cierre"` // YYYY-MM-DD, por defecto hoy
+}
+
+type DesactivarControlRequest struct {
+	Motivo string `json:"motivo" binding:"required"`
+}
+
+type RestaurarControlRequest struct {
+	Motivo string `json:"motivo"`
+}
+
+type ReabrirControlRequest struct {
+	Motivo string `json:"motivo"`
+}
+
 type EstadoResultadoRequest struct {
 	EstadoResultado string `json:"estado_resultado" binding:"required"`
 }
\ No newline at end of file
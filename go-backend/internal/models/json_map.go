@@ -0,0 +1,68 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONMap almacena un objeto JSON en una columna jsonb
+type JSONMap map[string]interface{}
+
+// Value implementa driver.Valuer
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	datos, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(datos), nil
+}
+
+// Scan implementa sql.Scanner
+func (m *JSONMap) Scan(value interface{}) error {
+	if value == nil {
+		*m = nil
+		return nil
+	}
+
+	var datos []byte
+	switch v := value.(type) {
+	case []byte:
+		datos = v
+	case string:
+		datos = []byte(v)
+	default:
+		return fmt.Errorf("tipo no soportado para JSONMap: %T", value)
+	}
+
+	resultado := JSONMap{}
+	if err := json.Unmarshal(datos, &resultado); err != nil {
+		return err
+	}
+	*m = resultado
+	return nil
+}
+
+// valorJSON serializa un valor para guardarlo en una columna jsonb
+func valorJSON(valor interface{}) (driver.Value, error) {
+	datos, err := json.Marshal(valor)
+	if err != nil {
+		return nil, err
+	}
+	return string(datos), nil
+}
+
+// escanearJSON decodifica el contenido de una columna jsonb en destino
+func escanearJSON(value interface{}, destino interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, destino)
+	case string:
+		return json.Unmarshal([]byte(v), destino)
+	default:
+		return fmt.Errorf("tipo no soportado para jsonb: %T", value)
+	}
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// CampoPlantilla es un dato adicional que el estudiante debe diligenciar en los casos del área
+type CampoPlantilla struct {
+	Clave     string   `json:"clave" binding:"required"`
+	Etiqueta  string   `json:"etiqueta" binding:"required"`
+	Tipo      string   `json:"tipo" binding:"required"` // texto, texto_largo, numero, fecha, opcion, si_no
+	Requerido bool     `json:"requerido"`
+	Opciones  []string `json:"opciones,omitempty"` // solo para tipo opcion
+	Ayuda     string   `json:"ayuda,omitempty"`
+}
+
+// CamposPlantilla se guarda como jsonb
+type CamposPlantilla []CampoPlantilla
+
+// Value implementa driver.Valuer
+func (c CamposPlantilla) Value() (driver.Value, error) {
+	if c == nil {
+		c = CamposPlantilla{}
+	}
+	return valorJSON(c)
+}
+
+// Scan implementa sql.Scanner
+func (c *CamposPlantilla) Scan(value interface{}) error {
+	*c = nil
+	if value == nil {
+		return nil
+	}
+	return escanearJSON(value, c)
+}
+
+// ListaTexto guarda una lista de textos como jsonb
+type ListaTexto []string
+
+// Value implementa driver.Valuer
+func (l ListaTexto) Value() (driver.Value, error) {
+	if l == nil {
+		l = ListaTexto{}
+	}
+	return valorJSON(l)
+}
+
+// Scan implementa sql.Scanner
+func (l *ListaTexto) Scan(value interface{}) error {
+	*l = nil
+	if value == nil {
+		return nil
+	}
+	return escanearJSON(value, l)
+}
+
+// PlantillaArea guía la recepción de los casos de un área de consulta: preguntas orientadoras,
+// texto sugerido para la descripción y campos adicionales que se validan al crear el control
+type PlantillaArea struct {
+	ID                  uint            `gorm:"primaryKey" json:"id"`
+	AreaConsulta        string          `gorm:"type:varchar(100);not null;uniqueIndex" json:"area_consulta"`
+	PreguntasGuia       ListaTexto      `gorm:"type:jsonb" json:"preguntas_guia"`
+	TextoPredeterminado string          `gorm:"type:text" json:"texto_predeterminado"`
+	Campos              CamposPlantilla `gorm:"type:jsonb" json:"campos"`
+	Activa              bool            `gorm:"default:true" json:"activa"`
+	ActualizadoPorID    *uint           `json:"actualizado_por_id"`
+	CreatedAt           time.Time       `json:"created_at"`
+	UpdatedAt           time.Time       `json:"updated_at"`
+}
+
+// TableName especifica el nombre de tabla para GORM
+func (PlantillaArea) TableName() string {
+	return "plantillas_area"
+}
+
+// RespuestaPlantilla es lo que el estudiante respondió a un campo de la plantilla. Guarda la
+// etiqueta para que el control siga legible aunque la plantilla cambie después
+type RespuestaPlantilla struct {
+	Clave    string `json:"clave"`
+	Etiqueta string `json:"etiqueta"`
+	Valor    string `json:"valor"`
+}
+
+// RespuestasPlantilla se guarda como jsonb en el control operativo
+type RespuestasPlantilla []RespuestaPlantilla
+
+// Value implementa driver.Valuer
+func (r RespuestasPlantilla) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return valorJSON(r)
+}
+
+// Scan implementa sql.Scanner
+func (r *RespuestasPlantilla) Scan(value interface{}) error {
+	*r = nil
+	if value == nil {
+		return nil
+	}
+	return escanearJSON(value, r)
+}
+
+type PlantillaAreaRequest struct {
+	AreaConsulta        string           `json:"area_consulta" binding:"required"`
+	PreguntasGuia       []string         `json:"preguntas_guia"`
+	TextoPredeterminado string           `json:"texto_predeterminado"`
+	Campos              []CampoPlantilla `json:"campos" binding:"omitempty,dive"`
+	Activa              *bool            `json:"activa"` // por defecto activa
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// ProcesoJudicial registra la radicación ante un despacho de los casos con resultado reparto o auto_reparto
+type ProcesoJudicial struct {
+	ID                 uint                `gorm:"primaryKey" json:"id"`
+	ControlOperativoID uint                `gorm:"not null;uniqueIndex" json:"control_operativo_id"`
+	Despacho           string              `gorm:"type:varchar(200);not null" json:"despacho"`
+	Radicado           string              `gorm:"type:varchar(23);not null;index" json:"radicado"`
+	FechaRadicacion    time.Time           `gorm:"type:date;not null" json:"fecha_radicacion"`
+	TipoProceso        string              `gorm:"type:varchar(100);not null" json:"tipo_proceso"`
+	Estado             string              `gorm:"type:varchar(20);default:'activo';index" json:"estado"`
+	FechaTerminacion   *time.Time          `gorm:"type:date" json:"fecha_terminacion"`
+	Observaciones      string              `gorm:"type:text" json:"observaciones"`
+	CreadoPorID        uint                `gorm:"not null" json:"creado_por_id"`
+	CreatedAt          time.Time           `json:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at"`
+	Actuaciones        []ActuacionProcesal `gorm:"foreignKey:ProcesoJudicialID;constraint:OnDelete:CASCADE" json:"actuaciones"`
+}
+
+// TableName especifica el nombre de tabla para GORM
+func (ProcesoJudicial) TableName() string {
+	return "procesos_judiciales"
+}
+
+// ActuacionProcesal es una actuación del despacho o de las partes dentro del proceso
+type ActuacionProcesal struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	ProcesoJudicialID uint      `gorm:"not null;index" json:"proceso_judicial_id"`
+	Fecha             time.Time `gorm:"type:date;not null" json:"fecha"`
+	Actuacion         string    `gorm:"type:varchar(200);not null" json:"actuacion"`
+	Anotacion         string    `gorm:"type:text" json:"anotacion"`
+	RegistradoPorID   uint      `gorm:"not null" json:"registrado_por_id"`
+	CreatedAt         time.Time `json:"created_at"`
+	RegistradoPor     User      `gorm:"foreignKey:RegistradoPorID" json:"registrado_por,omitempty"`
+}
+
+// TableName especifica el nombre de tabla para GORM
+func (ActuacionProcesal) TableName() string {
+	return "actuaciones_procesales"
+}
+
+type ProcesoJudicialRequest struct {
+	Despacho         string  `json:"despacho" binding:"required"`
+	Radicado         string  `json:"radicado" binding:"required"`
+	FechaRadicacion  string  `json:"fecha_radicacion" binding:"required"` // YYYY-MM-DD
+	TipoProceso      string  `json:"tipo_proceso" binding:"required"`
+	Estado           string  `json:"estado"`
+	FechaTerminacion *string `json:"fecha_terminacion"`
+	Observaciones    string  `json:"observaciones"`
+}
+
+type ActuacionProcesalRequest struct {
+	Fecha     string `json:"fecha" binding:"required"` // YYYY-MM-DD
+	Actuacion string `json:"actuacion" binding:"required"`
+	Anotacion string `json:"anotacion"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// Seguimiento registra una actuación posterior al concepto del asesor (llamadas, cartas, audiencias...)
+type Seguimiento struct {
+	ID                 uint                 `gorm:"primaryKey" json:"id"`
+	ControlOperativoID uint                 `gorm:"not null;index" json:"control_operativo_id"`
+	Tipo               string               `gorm:"type:varchar(50);not null" json:"tipo"`
+	Fecha              time.Time            `gorm:"type:date;not null" json:"fecha"`
+	Descripcion        string               `gorm:"type:text;not null" json:"descripcion"`
+	TipoTermino        string               `gorm:"type:varchar(50)" json:"tipo_termino,omitempty"`
+	FechaVencimiento   *time.Time           `gorm:"type:date;index" json:"fecha_vencimiento,omitempty"`
+	AutorID            uint                 `gorm:"not null" json:"autor_id"`
+	CreatedAt          time.Time            `json:"created_at"`
+	UpdatedAt          time.Time            `json:"updated_at"`
+	Autor              User                 `gorm:"foreignKey:AutorID" json:"autor,omitempty"`
+	Adjuntos           []SeguimientoAdjunto `gorm:"foreignKey:SeguimientoID;constraint:OnDelete:CASCADE" json:"adjuntos,omitempty"`
+}
+
+// SeguimientoAdjunto es un archivo soporte de una actuación
+type SeguimientoAdjunto struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	SeguimientoID  uint      `gorm:"not null;index" json:"seguimiento_id"`
+	NombreOriginal string    `gorm:"type:varchar(255)" json:"nombre_original"`
+	NombreArchivo  string    `gorm:"type:varchar(255)" json:"nombre_archivo"`
+	TipoArchivo    string    `gorm:"type:varchar(50)" json:"tipo_archivo"`
+	TamanoBytes    int64     `json:"tamano_bytes"`
+	RutaArchivo    string    `gorm:"type:varchar(500)" json:"ruta_archivo"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type SeguimientoRequest struct {
+	Tipo        string   `json:"tipo" binding:"required"`
+	Fecha       string   `json:"fecha" binding:"required"` // YYYY-MM-DD
+	Descripcion string   `json:"descripcion" binding:"required"`
+	TipoTermino string   `json:"tipo_termino,omitempty"` // término legal que empieza a correr con la actuación
+	Adjuntos    []string `json:"adjuntos,omitempty"` // nombres devueltos por /api/upload/temp
+}
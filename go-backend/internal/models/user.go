@@ -20,6 +20,7 @@ type User struct {
 	Apellidos          string     `gorm:"type:varchar(100)" json:"apellidos"`
 	NumeroCelular      string     `gorm:"type:varchar(20)" json:"numero_celular"`
 	Sede               string     `gorm:"type:varchar(100)" json:"sede"`
+	TokenCalendario    *string    `gorm:"type:varchar(64);uniqueIndex" json:"-"`
 	CreatedAt          time.Time  `json:"created_at"`
 	UpdatedAt          time.Time  `json:"updated_at"`
 }
@@ -41,18 +42,19 @@ type Estudiante struct {
 }
 
 type Profesor struct {
-	ID              uint      `gorm:"primaryKey" json:"id"`
-	UserID          uint      `gorm:"unique" json:"user_id"`
-	TipoDocumento   string    `json:"tipo_documento"`
-	NumeroDocumento string    `gorm:"unique" json:"numero_documento"`
-	Nombres         string    `json:"nombres"`
-	Apellidos       string    `json:"apellidos"`
-	NumeroCelular   string    `json:"numero_celular"`
-	Sede            string    `json:"sede"`
-	Activo          bool      `gorm:"default:true" json:"activo"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
-	User            User      `gorm:"foreignKey:UserID" json:"user"`
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	UserID            uint      `gorm:"unique" json:"user_id"`
+	TipoDocumento     string    `json:"tipo_documento"`
+	NumeroDocumento   string    `gorm:"unique" json:"numero_documento"`
+	Nombres           string    `json:"nombres"`
+	Apellidos         string    `json:"apellidos"`
+	NumeroCelular     string    `json:"numero_celular"`
+	Sede              string    `json:"sede"`
+	AreasEspecialidad string    `gorm:"type:varchar(255)" json:"areas_especialidad"` // separadas por coma; vacío = cualquier área
+	Activo            bool      `gorm:"default:true" json:"activo"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	User              User      `gorm:"foreignKey:UserID" json:"user"`
 }
 
 type UserResponse struct {
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DirectorioTemporal es donde /api/upload/temp deja los archivos antes de asociarlos a un registro
+const DirectorioTemporal = "storage/uploads/temp"
+
+// ErrArchivoTemporalNoEncontrado indica que el archivo no se subió o ya fue asociado a otro registro
+var ErrArchivoTemporalNoEncontrado = errors.New("archivo temporal no encontrado")
+
+// prefijoTemporal es el "<usuario>_<unix>_" que /api/upload/temp antepone al nombre subido
+var prefijoTemporal = regexp.MustCompile(`^[0-9]+_[0-9]+_`)
+
+// ArchivoMovido describe un archivo temporal que ya quedó en su ubicación final
+type ArchivoMovido struct {
+	Nombre         string
+	NombreOriginal string // nombre con el que el usuario subió el archivo
+	Ruta           string
+	TamanoBytes    int64
+}
+
+// archivoTemporalDe limpia el nombre recibido y verifica que el archivo temporal lo haya subido el
+// usuario indicado; los archivos de otros usuarios se tratan como inexistentes
+func archivoTemporalDe(nombre string, userID uint) (string, error) {
+	// Evita que el nombre recibido apunte fuera del directorio temporal
+	nombre = filepath.Base(nombre)
+	if !strings.HasPrefix(nombre, fmt.Sprintf("%d_", userID)) || !prefijoTemporal.MatchString(nombre) {
+		return "", fmt.Errorf("%w: %s", ErrArchivoTemporalNoEncontrado, nombre)
+	}
+	if _, err := os.Stat(filepath.Join(DirectorioTemporal, nombre)); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrArchivoTemporalNoEncontrado, nombre)
+	}
+	return nombre, nil
+}
+
+// MoverArchivoTemporal mueve un archivo que el usuario subió a /api/upload/temp al directorio indicado
+func MoverArchivoTemporal(nombre string, userID uint, directorioFinal string) (*ArchivoMovido, error) {
+	nombre, err := archivoTemporalDe(nombre, userID)
+	if err != nil {
+		return nil, err
+	}
+	origen := filepath.Join(DirectorioTemporal, nombre)
+
+	if err := os.MkdirAll(directorioFinal, 0755); err != nil {
+		return nil, fmt.Errorf("error creando directorio %s: %w", directorioFinal, err)
+	}
+
+	destino := filepath.Join(directorioFinal, nombre)
+	if err := os.Rename(origen, destino); err != nil {
+		return nil, fmt.Errorf("error moviendo archivo %s: %w", nombre, err)
+	}
+
+	info, err := os.Stat(destino)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo info del archivo %s: %w", nombre, err)
+	}
+	return &ArchivoMovido{
+		Nombre:         nombre,
+		NombreOriginal: prefijoTemporal.ReplaceAllString(nombre, ""),
+		Ruta:           destino,
+		TamanoBytes:    info.Size(),
+	}, nil
+}
+
+// DevolverArchivosTemporales regresa al directorio temporal los archivos movidos por una operación
+// que no se guardó, para que el usuario pueda volver a asociarlos
+func DevolverArchivosTemporales(movidos []ArchivoMovido) {
+	for _, movido := range movidos {
+		if err := os.Rename(movido.Ruta, filepath.Join(DirectorioTemporal, movido.Nombre)); err != nil {
+			log.Printf("Warning: no se pudo devolver %s al directorio temporal: %v", movido.Nombre, err)
+		}
+	}
+}
+
+// VerificarArchivosTemporales confirma que todos los archivos siguen en el directorio temporal y que
+// los subió el usuario indicado, para no registrar nada si alguno falta
+func VerificarArchivosTemporales(nombres []string, userID uint) error {
+	for _, nombre := range nombres {
+		if _, err := archivoTemporalDe(nombre, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+)
+
+var ErrProfesorNoDisponible = errors.New("el profesor no existe o está inactivo")
+
+// Reasignacion describe el cambio de profesor de un control
+type Reasignacion struct {
+	ControlID          uint   `json:"control_id"`
+	EstudianteID       uint   `json:"-"`
+	ProfesorAnteriorID *uint  `json:"profesor_anterior_id"`
+	ProfesorAnterior   string `json:"profesor_anterior"`
+	ProfesorNuevoID    uint   `json:"profesor_nuevo_id"`
+	ProfesorNuevo      string `json:"profesor_nuevo"`
+}
+
+// AsignacionService gestiona qué profesor es responsable de cada control
+type AsignacionService struct {
+	db                   *gorm.DB
+	workflowService      *WorkflowService
+	configuracionService *ConfiguracionService
+}
+
+func NewAsignacionService(db *gorm.DB, workflowService *WorkflowService, configuracionService *ConfiguracionService) *AsignacionService {
+	return &AsignacionService{
+		db:                   db,
+		workflowService:      workflowService,
+		configuracionService: configuracionService,
+	}
+}
+
+// NombreProfesor arma el nombre con el que el profesor aparece en NombreDocenteResponsable
+func NombreProfesor(profesor *models.User) string {
+	return fmt.Sprintf("%s %s", strings.TrimSpace(profesor.Nombres), strings.TrimSpace(profesor.Apellidos))
+}
+
+// ObtenerProfesorActivo busca un profesor habilitado para recibir casos
+func (s *AsignacionService) ObtenerProfesorActivo(tx *gorm.DB, profesorID uint) (*models.User, error) {
+	var profesor models.User
+	err := tx.Where("id = ? AND role = 'profesor' AND activo = true", profesorID).First(&profesor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProfesorNoDisponible
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profesor, nil
+}
+
+// Reasignar cambia el profesor responsable del control dentro de la transacción indicada
+// y deja constancia del cambio en el historial del caso
+func (s *AsignacionService) Reasignar(tx *gorm.DB, control *models.ControlOperativo, profesor *models.User, actor *models.User, motivo string) (*Reasignacion, error) {
+	if !control.Activo {
+		return nil, fmt.Errorf("%w: el control #%d está inactivo", ErrTransicionNoPermitida, control.ID)
+	}
+
+	reasignacion := &Reasignacion{
+		ControlID:          control.ID,
+		EstudianteID:       control.CreatedByID,
+		ProfesorAnteriorID: control.ProfesorAsignadoID,
+		ProfesorAnterior:   control.NombreDocenteResponsable,
+		ProfesorNuevoID:    profesor.ID,
+		ProfesorNuevo:      NombreProfesor(profesor),
+	}
+
+	if err := tx.Model(&models.ControlOperativo{}).
+		Where("id = ?", control.ID).
+		Updates(map[string]interface{}{
+			"profesor_asignado_id":       profesor.ID,
+			"nombre_docente_responsable": reasignacion.ProfesorNuevo,
+			"updated_at":                 time.Now(),
+		}).Error; err != nil {
+		return nil, fmt.Errorf("error reasignando control #%d: %w", control.ID, err)
+	}
+
+	comentario := fmt.Sprintf("Profesor reasignado: %s → %s", reasignacion.ProfesorAnterior, reasignacion.ProfesorNuevo)
+	if motivo != "" {
+		comentario += " (" + motivo + ")"
+	}
+	if err := s.workflowService.RegistrarTransicion(tx, control.ID, control.EstadoFlujo, control.EstadoFlujo, actor, comentario); err != nil {
+		return nil, err
+	}
+
+	control.ProfesorAsignadoID = &profesor.ID
+	control.NombreDocenteResponsable = reasignacion.ProfesorNuevo
+	return reasignacion, nil
+}
+
+// ReasignarVarios cambia el profesor de varios controles en una sola transacción; si alguno
+// falla no se aplica ningún cambio. Los controles que ya pertenecen al profesor se omiten
+func (s *AsignacionService) ReasignarVarios(tx *gorm.DB, controlIDs []uint, profesorID uint, actor *models.User, motivo string) ([]Reasignacion, error) {
+	profesor, err := s.ObtenerProfesorActivo(tx, profesorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Un control repetido en la solicitud se reasigna una sola vez
+	vistos := map[uint]bool{}
+	ids := make([]uint, 0, len(controlIDs))
+	for _, id := range controlIDs {
+		if !vistos[id] {
+			vistos[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	var controles []models.ControlOperativo
+	if err := tx.Where("id IN ?", ids).Order("id ASC").Find(&controles).Error; err != nil {
+		return nil, err
+	}
+	if len(controles) != len(ids) {
+		return nil, fmt.Errorf("%w: algunos controles no existen", gorm.ErrRecordNotFound)
+	}
+
+	reasignaciones := []Reasignacion{}
+	for i := range controles {
+		control := &controles[i]
+		if control.ProfesorAsignadoID != nil && *control.ProfesorAsignadoID == profesor.ID {
+			continue
+		}
+		reasignacion, err := s.Reasignar(tx, control, profesor, actor, motivo)
+		if err != nil {
+			return nil, err
+		}
+		reasignaciones = append(reasignaciones, *reasignacion)
+	}
+	return reasignaciones, nil
+}
+
+// ControlesActivosDeProfesor retorna los controles activos que el profesor aún tiene en revisión
+func (s *AsignacionService) ControlesActivosDeProfesor(profesorID uint) ([]uint, error) {
+	var ids []uint
+	err := s.db.Model(&models.ControlOperativo{}).
+		Where("profesor_asignado_id = ? AND activo = true AND estado_flujo IN ?", profesorID,
+			[]string{EstadoPendienteProfesor, EstadoRequiereCorrecciones}).
+		Order("id ASC").
+		Pluck("id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,374 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"consultorio-juridico/internal/models"
+)
+
+type auditoriaActorKey struct{}
+
+const auditoriaAntesKey = "auditoria:antes"
+
+// Tablas cuyos cambios se registran en la auditoría
+var tablasAuditadas = map[string]bool{
+	"control_operativos": true,
+	"calificaciones":     true,
+	"users":              true,
+}
+
+// Campos que no aportan información o que nunca deben quedar en la auditoría
+var camposIgnoradosAuditoria = map[string]bool{
+	"created_at":          true,
+	"updated_at":          true,
+	"verification_code":   true,
+	"verification_expiry": true,
+	"sla_recordatorio_at": true,
+	"sla_escalado_at":     true,
+	"token_calendario":    true,
+}
+
+// Campos cuyo cambio se registra sin guardar el valor
+var camposSensiblesAuditoria = map[string]bool{
+	"password_hash": true,
+}
+
+// AuditoriaFiltros parámetros de filtrado del historial de auditoría
+type AuditoriaFiltros struct {
+	Tabla      string
+	RegistroID uint
+	UsuarioID  uint
+	Campo      string
+	Operacion  string
+	DateFrom   string
+	DateTo     string
+}
+
+// AuditService registra mediante callbacks de GORM quién cambió qué campos
+type AuditService struct {
+	db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// ConActor asocia el usuario que realiza la operación a las escrituras hechas con la sesión retornada
+func ConActor(db *gorm.DB, actor *models.User) *gorm.DB {
+	return db.WithContext(context.WithValue(db.Statement.Context, auditoriaActorKey{}, actor))
+}
+
+// RegistrarCallbacks instala los callbacks de auditoría dentro de la transacción de cada escritura
+func (s *AuditService) RegistrarCallbacks() error {
+	callbacks := s.db.Callback()
+
+	if err := callbacks.Update().Before("gorm:update").
+		Register("auditoria:antes_actualizar", s.capturarEstadoAnterior); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").
+		Register("auditoria:despues_actualizar", s.registrarActualizacion); err != nil {
+		return err
+	}
+	return callbacks.Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").
+		Register("auditoria:despues_crear", s.registrarCreacion)
+}
+
+func esTablaAuditada(tx *gorm.DB) bool {
+	return tx.Error == nil && tx.Statement.Schema != nil && tablasAuditadas[tx.Statement.Schema.Table]
+}
+
+// capturarEstadoAnterior lee las filas que la actualización va a modificar
+func (s *AuditService) capturarEstadoAnterior(tx *gorm.DB) {
+	if !esTablaAuditada(tx) {
+		return
+	}
+
+	stmt := tx.Statement
+	query := tx.Session(&gorm.Session{NewDB: true}).Table(stmt.Schema.Table)
+	condiciones := 0
+
+	if where, ok := stmt.Clauses["WHERE"]; ok {
+		if expr, ok := where.Expression.(clause.Where); ok && len(expr.Exprs) > 0 {
+			query = query.Clauses(expr)
+			condiciones++
+		}
+	}
+
+	// Save y Updates sobre un modelo con llave primaria agregan la condición dentro de gorm:update
+	if stmt.ReflectValue.Kind() == reflect.Struct && stmt.Schema.PrioritizedPrimaryField != nil {
+		if id, isZero := stmt.Schema.PrioritizedPrimaryField.ValueOf(stmt.Context, stmt.ReflectValue); !isZero {
+			query = query.Where(stmt.Schema.PrioritizedPrimaryField.DBName+" = ?", id)
+			condiciones++
+		}
+	}
+
+	if condiciones == 0 {
+		return
+	}
+
+	var filas []map[string]interface{}
+	if err := query.Find(&filas).Error; err != nil {
+		log.Printf("Warning: auditoría no pudo leer estado anterior de %s: %v", stmt.Schema.Table, err)
+		return
+	}
+	tx.InstanceSet(auditoriaAntesKey, filas)
+}
+
+// registrarActualizacion compara las filas antes y después y guarda un registro por campo modificado
+func (s *AuditService) registrarActualizacion(tx *gorm.DB) {
+	if !esTablaAuditada(tx) {
+		return
+	}
+
+	valor, ok := tx.InstanceGet(auditoriaAntesKey)
+	if !ok {
+		return
+	}
+	antes, _ := valor.([]map[string]interface{})
+	if len(antes) == 0 {
+		return
+	}
+
+	tabla := tx.Statement.Schema.Table
+	ids := make([]interface{}, 0, len(antes))
+	for _, fila := range antes {
+		ids = append(ids, fila["id"])
+	}
+
+	sesion := tx.Session(&gorm.Session{NewDB: true})
+	var despues []map[string]interface{}
+	if err := sesion.Table(tabla).Where("id IN ?", ids).Find(&despues).Error; err != nil {
+		log.Printf("Warning: auditoría no pudo leer estado nuevo de %s: %v", tabla, err)
+		return
+	}
+
+	despuesPorID := make(map[string]map[string]interface{}, len(despues))
+	for _, fila := range despues {
+		despuesPorID[valorAuditoria(fila["id"])] = fila
+	}
+
+	usuarioID, usuarioRol := actorDesdeContexto(tx.Statement.Context)
+	var registros []models.Auditoria
+	for _, filaAntes := range antes {
+		filaDespues, ok := despuesPorID[valorAuditoria(filaAntes["id"])]
+		if !ok {
+			continue
+		}
+		registroID := registroIDAuditoria(filaAntes["id"])
+
+		for campo, anterior := range filaAntes {
+			if camposIgnoradosAuditoria[campo] {
+				continue
+			}
+			nuevo := filaDespues[campo]
+			valorAnterior, valorNuevo := valorAuditoriaPtr(anterior), valorAuditoriaPtr(nuevo)
+			if iguales(valorAnterior, valorNuevo) {
+				continue
+			}
+			if camposSensiblesAuditoria[campo] {
+				oculto := "********"
+				valorAnterior, valorNuevo = &oculto, &oculto
+			}
+
+			registros = append(registros, models.Auditoria{
+				Tabla:         tabla,
+				RegistroID:    registroID,
+				Operacion:     "actualizar",
+				Campo:         campo,
+				ValorAnterior: valorAnterior,
+				ValorNuevo:    valorNuevo,
+				UsuarioID:     usuarioID,
+				UsuarioRol:    usuarioRol,
+			})
+		}
+	}
+
+	if len(registros) == 0 {
+		return
+	}
+	if err := sesion.Create(&registros).Error; err != nil {
+		log.Printf("Warning: error guardando auditoría de %s: %v", tabla, err)
+	}
+}
+
+// registrarCreacion deja constancia de quién creó cada registro auditado
+func (s *AuditService) registrarCreacion(tx *gorm.DB) {
+	if !esTablaAuditada(tx) || tx.Statement.Schema.PrioritizedPrimaryField == nil {
+		return
+	}
+
+	stmt := tx.Statement
+	campoID := stmt.Schema.PrioritizedPrimaryField
+	usuarioID, usuarioRol := actorDesdeContexto(stmt.Context)
+
+	var registros []models.Auditoria
+	agregar := func(rv reflect.Value) {
+		id, isZero := campoID.ValueOf(stmt.Context, rv)
+		if isZero {
+			return
+		}
+		registros = append(registros, models.Auditoria{
+			Tabla:      stmt.Schema.Table,
+			RegistroID: registroIDAuditoria(id),
+			Operacion:  "crear",
+			UsuarioID:  usuarioID,
+			UsuarioRol: usuarioRol,
+		})
+	}
+
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Struct:
+		agregar(stmt.ReflectValue)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			agregar(reflect.Indirect(stmt.ReflectValue.Index(i)))
+		}
+	}
+
+	if len(registros) == 0 {
+		return
+	}
+	if err := tx.Session(&gorm.Session{NewDB: true}).Create(&registros).Error; err != nil {
+		log.Printf("Warning: error guardando auditoría de %s: %v", stmt.Schema.Table, err)
+	}
+}
+
+// Listar retorna el historial de auditoría paginado según los filtros
+func (s *AuditService) Listar(filtros AuditoriaFiltros, pagination PaginationParams) (*PaginationResult, error) {
+	if pagination.Page <= 0 {
+		pagination.Page = 1
+	}
+	if pagination.Limit <= 0 || pagination.Limit > 100 {
+		pagination.Limit = 50
+	}
+
+	query := s.db.Model(&models.Auditoria{})
+	if filtros.Tabla != "" {
+		query = query.Where("tabla = ?", filtros.Tabla)
+	}
+	if filtros.RegistroID > 0 {
+		query = query.Where("registro_id = ?", filtros.RegistroID)
+	}
+	if filtros.UsuarioID > 0 {
+		query = query.Where("usuario_id = ?", filtros.UsuarioID)
+	}
+	if filtros.Campo != "" {
+		query = query.Where("campo = ?", filtros.Campo)
+	}
+	if filtros.Operacion != "" {
+		query = query.Where("operacion = ?", filtros.Operacion)
+	}
+	if filtros.DateFrom != "" {
+		if dateFrom, err := time.Parse("2006-01-02", filtros.DateFrom); err == nil {
+			query = query.Where("created_at >= ?", dateFrom)
+		}
+	}
+	if filtros.DateTo != "" {
+		if dateTo, err := time.Parse("2006-01-02", filtros.DateTo); err == nil {
+			query = query.Where("created_at < ?", dateTo.Add(24*time.Hour))
+		}
+	}
+
+	var totalRecords int64
+	if err := query.Count(&totalRecords).Error; err != nil {
+		return nil, fmt.Errorf("error contando auditoría: %v", err)
+	}
+
+	totalPages := int((totalRecords + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	var registros []models.Auditoria
+	err := query.
+		Preload("Usuario", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, nombres, apellidos, email, role")
+		}).
+		Order("created_at DESC, id DESC").
+		Limit(pagination.Limit).
+		Offset(offset).
+		Find(&registros).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo auditoría: %v", err)
+	}
+
+	return &PaginationResult{
+		Data:         registros,
+		CurrentPage:  pagination.Page,
+		PerPage:      pagination.Limit,
+		TotalPages:   totalPages,
+		TotalRecords: totalRecords,
+		HasNext:      pagination.Page < totalPages,
+		HasPrev:      pagination.Page > 1,
+		From:         offset + 1,
+		To:           offset + len(registros),
+	}, nil
+}
+
+func actorDesdeContexto(ctx context.Context) (*uint, string) {
+	if ctx == nil {
+		return nil, ""
+	}
+	actor, ok := ctx.Value(auditoriaActorKey{}).(*models.User)
+	if !ok || actor == nil {
+		return nil, ""
+	}
+	id := actor.ID
+	return &id, actor.Role
+}
+
+func registroIDAuditoria(valor interface{}) uint {
+	switch v := valor.(type) {
+	case int64:
+		return uint(v)
+	case int32:
+		return uint(v)
+	case int:
+		return uint(v)
+	case uint:
+		return v
+	case uint64:
+		return uint(v)
+	case uint32:
+		return uint(v)
+	}
+	return 0
+}
+
+func valorAuditoria(valor interface{}) string {
+	switch v := valor.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprint(valor)
+}
+
+func valorAuditoriaPtr(valor interface{}) *string {
+	if valor == nil {
+		return nil
+	}
+	texto := valorAuditoria(valor)
+	return &texto
+}
+
+func iguales(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+)
+
+const (
+	ClaveBorradoresDiasRetencion = "borradores.dias_retencion"
+	BorradoresDiasRetencion      = 30
+)
+
+var ErrBorradorNoEncontrado = errors.New("borrador no encontrado")
+
+// BorradorService guarda los formularios de control a medio diligenciar y depura los abandonados
+type BorradorService struct {
+	db                   *gorm.DB
+	configuracionService *ConfiguracionService
+}
+
+func NewBorradorService(db *gorm.DB, configuracionService *ConfiguracionService) *BorradorService {
+	return &BorradorService{
+		db:                   db,
+		configuracionService: configuracionService,
+	}
+}
+
+// datosBorrador convierte la solicitud parcial en el mapa que se almacena
+func datosBorrador(req *models.ControlOperativoRequest) (models.JSONMap, error) {
+	contenido, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var datos models.JSONMap
+	if err := json.Unmarshal(contenido, &datos); err != nil {
+		return nil, err
+	}
+	return datos, nil
+}
+
+// Solicitud reconstruye el formulario guardado en el borrador
+func (s *BorradorService) Solicitud(borrador *models.BorradorControl) (*models.ControlOperativoRequest, error) {
+	contenido, err := json.Marshal(borrador.Datos)
+	if err != nil {
+		return nil, err
+	}
+	var req models.ControlOperativoRequest
+	if err := json.Unmarshal(contenido, &req); err != nil {
+		return nil, fmt.Errorf("error leyendo borrador #%d: %w", borrador.ID, err)
+	}
+	return &req, nil
+}
+
+// Crear guarda un borrador nuevo del usuario
+func (s *BorradorService) Crear(req *models.ControlOperativoRequest, autor *models.User) (*models.BorradorControl, error) {
+	datos, err := datosBorrador(req)
+	if err != nil {
+		return nil, err
+	}
+
+	borrador := models.BorradorControl{CreadoPorID: autor.ID, Datos: datos}
+	if err := s.db.Create(&borrador).Error; err != nil {
+		return nil, fmt.Errorf("error creando borrador: %w", err)
+	}
+	return &borrador, nil
+}
+
+// Actualizar reemplaza el contenido del borrador con el formulario más reciente
+func (s *BorradorService) Actualizar(borrador *models.BorradorControl, req *models.ControlOperativoRequest) error {
+	datos, err := datosBorrador(req)
+	if err != nil {
+		return err
+	}
+
+	borrador.Datos = datos
+	borrador.UpdatedAt = time.Now()
+	if err := s.db.Model(borrador).Updates(map[string]interface{}{
+		"datos":      datos,
+		"updated_at": borrador.UpdatedAt,
+	}).Error; err != nil {
+		return fmt.Errorf("error actualizando borrador: %w", err)
+	}
+	return nil
+}
+
+// Obtener busca un borrador del usuario indicado
+func (s *BorradorService) Obtener(borradorID, autorID uint) (*models.BorradorControl, error) {
+	var borrador models.BorradorControl
+	err := s.db.Where("id = ? AND creado_por_id = ?", borradorID, autorID).First(&borrador).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBorradorNoEncontrado
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &borrador, nil
+}
+
+// Listar retorna los borradores del usuario, del más reciente al más antiguo
+func (s *BorradorService) Listar(autorID uint) ([]models.BorradorControl, error) {
+	var borradores []models.BorradorControl
+	err := s.db.Where("creado_por_id = ?", autorID).Order("updated_at DESC").Find(&borradores).Error
+	return borradores, err
+}
+
+// Eliminar borra el borrador dentro de la transacción indicada
+func (s *BorradorService) Eliminar(tx *gorm.DB, borrador *models.BorradorControl) error {
+	result := tx.Delete(&models.BorradorControl{}, borrador.ID)
+	if result.Error != nil {
+		return result.Error
+	}
+	// Otra petición ya envió o eliminó el borrador
+	if result.RowsAffected == 0 {
+		return ErrBorradorNoEncontrado
+	}
+	return nil
+}
+
+// Descartar elimina el borrador a petición de su autor
+func (s *BorradorService) Descartar(borrador *models.BorradorControl) error {
+	if err := s.Eliminar(s.db, borrador); err != nil && !errors.Is(err, ErrBorradorNoEncontrado) {
+		return fmt.Errorf("error eliminando borrador: %w", err)
+	}
+	return nil
+}
+
+// DiasRetencion retorna cuántos días sin modificarse puede permanecer un borrador
+func (s *BorradorService) DiasRetencion() int {
+	return s.configuracionService.ObtenerEntero(ClaveBorradoresDiasRetencion, BorradoresDiasRetencion)
+}
+
+// EstablecerDiasRetencion cambia la antigüedad máxima de los borradores
+func (s *BorradorService) EstablecerDiasRetencion(dias int, actor *models.User) error {
+	if dias <= 0 {
+		return fmt.Errorf("dias_retencion debe ser mayor que cero")
+	}
+	return s.configuracionService.Establecer(ClaveBorradoresDiasRetencion, strconv.Itoa(dias), actor)
+}
+
+// IniciarScheduler depura periódicamente los borradores abandonados en segundo plano
+func (s *BorradorService) IniciarScheduler(intervalo time.Duration) {
+	ticker := time.NewTicker(intervalo)
+	go func() {
+		s.LimpiarAntiguos()
+		for range ticker.C {
+			s.LimpiarAntiguos()
+		}
+	}()
+}
+
+// LimpiarAntiguos elimina los borradores que no se modifican hace más de los días de retención
+func (s *BorradorService) LimpiarAntiguos() {
+	limite := time.Now().AddDate(0, 0, -s.DiasRetencion())
+	result := s.db.Where("updated_at < ?", limite).Delete(&models.BorradorControl{})
+	if result.Error != nil {
+		log.Printf("Warning: no se pudieron depurar los borradores: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("🧹 Borradores: %d borradores sin cambios desde %s eliminados", result.RowsAffected, limite.Format("2006-01-02"))
+	}
+}
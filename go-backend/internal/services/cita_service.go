@@ -0,0 +1,327 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+)
+
+const (
+	ClaveCitasHorasRecordatorio = "citas.horas_recordatorio"
+
+	CitasHorasRecordatorio = 24
+
+	EstadoCitaProgramada = "programada"
+	EstadoCitaCancelada  = "cancelada"
+
+	// Llave del bloqueo de Postgres que serializa la verificación de cruces de horario
+	bloqueoAgendaCitas = 7310002
+)
+
+var (
+	ErrCitaNoEncontrada = errors.New("cita no encontrada")
+	ErrCitaInvalida     = errors.New("cita inválida")
+	ErrCitaSolapada     = errors.New("la cita se cruza con otra ya programada")
+)
+
+// CitaService programa las reuniones con el consultante y evita cruces de agenda
+type CitaService struct {
+	db                   *gorm.DB
+	configuracionService *ConfiguracionService
+	notificationService  *NotificationService
+}
+
+func NewCitaService(db *gorm.DB, configuracionService *ConfiguracionService, notificationService *NotificationService) *CitaService {
+	return &CitaService{
+		db:                   db,
+		configuracionService: configuracionService,
+		notificationService:  notificationService,
+	}
+}
+
+// validarHorario verifica que la cita termine después de empezar
+func validarHorario(req *models.CitaRequest) error {
+	req.Lugar = strings.TrimSpace(req.Lugar)
+	if req.Lugar == "" {
+		return fmt.Errorf("%w: el lugar es requerido", ErrCitaInvalida)
+	}
+	if !req.Fin.After(req.Inicio) {
+		return fmt.Errorf("%w: la hora de fin debe ser posterior a la de inicio", ErrCitaInvalida)
+	}
+	return nil
+}
+
+// ParticipantesCita retorna los usuarios del sistema que asisten a la cita
+func ParticipantesCita(cita *models.Cita) []uint {
+	participantes := []uint{cita.EstudianteID}
+	if cita.ProfesorID != nil {
+		participantes = append(participantes, *cita.ProfesorID)
+	}
+	return participantes
+}
+
+// destinatariosCita excluye al usuario que realizó el cambio
+func destinatariosCita(cita *models.Cita, actor *models.User) []uint {
+	var destinatarios []uint
+	for _, userID := range ParticipantesCita(cita) {
+		if actor == nil || userID != actor.ID {
+			destinatarios = append(destinatarios, userID)
+		}
+	}
+	return destinatarios
+}
+
+// EsParticipante indica si el usuario asiste a la cita
+func EsParticipante(cita *models.Cita, user *models.User) bool {
+	for _, userID := range ParticipantesCita(cita) {
+		if userID == user.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// verificarCruce busca una cita programada del estudiante o del profesor que se cruce con el horario
+func (s *CitaService) verificarCruce(tx *gorm.DB, cita *models.Cita) error {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", bloqueoAgendaCitas).Error; err != nil {
+		return err
+	}
+
+	query := tx.Where("estado = ? AND inicio < ? AND fin > ? AND id <> ?", EstadoCitaProgramada, cita.Fin, cita.Inicio, cita.ID)
+	if cita.ProfesorID != nil {
+		query = query.Where("(estudiante_id = ? OR profesor_id = ?)", cita.EstudianteID, *cita.ProfesorID)
+	} else {
+		query = query.Where("estudiante_id = ?", cita.EstudianteID)
+	}
+
+	var existente models.Cita
+	err := query.Order("inicio ASC").First(&existente).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	quien := "el estudiante"
+	if existente.EstudianteID != cita.EstudianteID {
+		quien = "el profesor"
+	}
+	return fmt.Errorf("%w: %s ya tiene la cita #%d de %s a %s", ErrCitaSolapada, quien, existente.ID,
+		existente.Inicio.Local().Format("02/01/2006 15:04"), existente.Fin.Local().Format("15:04"))
+}
+
+// Programar crea una cita para el estudiante y el profesor asignados al control
+func (s *CitaService) Programar(control *models.ControlOperativo, req models.CitaRequest, actor *models.User) (*models.Cita, error) {
+	if err := validarHorario(&req); err != nil {
+		return nil, err
+	}
+
+	cita := models.Cita{
+		ControlOperativoID: control.ID,
+		Inicio:             req.Inicio,
+		Fin:                req.Fin,
+		Lugar:              req.Lugar,
+		Descripcion:        strings.TrimSpace(req.Descripcion),
+		Participantes:      strings.TrimSpace(req.Participantes),
+		EstudianteID:       control.CreatedByID,
+		ProfesorID:         control.ProfesorAsignadoID,
+		CreadoPorID:        actor.ID,
+		Estado:             EstadoCitaProgramada,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.verificarCruce(tx, &cita); err != nil {
+			return err
+		}
+		return tx.Create(&cita).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notificationService.NotificarCita(&cita, "cita_programada", destinatariosCita(&cita, actor))
+	return &cita, nil
+}
+
+// Reprogramar cambia el horario o el lugar de una cita programada
+func (s *CitaService) Reprogramar(cita *models.Cita, req models.CitaRequest, actor *models.User) error {
+	if cita.Estado != EstadoCitaProgramada {
+		return fmt.Errorf("%w: la cita está %s", ErrCitaInvalida, cita.Estado)
+	}
+	if err := validarHorario(&req); err != nil {
+		return err
+	}
+
+	cita.Inicio = req.Inicio
+	cita.Fin = req.Fin
+	cita.Lugar = req.Lugar
+	cita.Descripcion = strings.TrimSpace(req.Descripcion)
+	cita.Participantes = strings.TrimSpace(req.Participantes)
+	cita.RecordatorioEnviadoAt = nil
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.verificarCruce(tx, cita); err != nil {
+			return err
+		}
+		return tx.Model(&models.Cita{}).Where("id = ?", cita.ID).Updates(map[string]interface{}{
+			"inicio":                  cita.Inicio,
+			"fin":                     cita.Fin,
+			"lugar":                   cita.Lugar,
+			"descripcion":             cita.Descripcion,
+			"participantes":           cita.Participantes,
+			"recordatorio_enviado_at": nil,
+			"updated_at":              time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notificationService.NotificarCita(cita, "cita_reprogramada", destinatariosCita(cita, actor))
+	return nil
+}
+
+// Cancelar libera el horario de la cita conservando el registro
+func (s *CitaService) Cancelar(cita *models.Cita, actor *models.User, motivo string) error {
+	if cita.Estado != EstadoCitaProgramada {
+		return fmt.Errorf("%w: la cita ya está %s", ErrCitaInvalida, cita.Estado)
+	}
+
+	result := s.db.Model(&models.Cita{}).
+		Where("id = ? AND estado = ?", cita.ID, EstadoCitaProgramada).
+		Updates(map[string]interface{}{
+			"estado":             EstadoCitaCancelada,
+			"motivo_cancelacion": motivo,
+			"updated_at":         time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: la cita cambió mientras se cancelaba", ErrCitaInvalida)
+	}
+
+	cita.Estado = EstadoCitaCancelada
+	cita.MotivoCancelacion = motivo
+	s.notificationService.NotificarCita(cita, "cita_cancelada", destinatariosCita(cita, actor))
+	return nil
+}
+
+// Obtener busca una cita por ID
+func (s *CitaService) Obtener(citaID uint) (*models.Cita, error) {
+	var cita models.Cita
+	err := s.db.First(&cita, citaID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCitaNoEncontrada
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cita, nil
+}
+
+// ListarPorControl retorna las citas del caso en orden cronológico
+func (s *CitaService) ListarPorControl(controlID uint) ([]models.Cita, error) {
+	var citas []models.Cita
+	err := s.db.Where("control_operativo_id = ?", controlID).
+		Order("inicio ASC").
+		Find(&citas).Error
+	return citas, err
+}
+
+// ListarPorUsuario retorna las citas en las que participa el usuario dentro del rango indicado
+func (s *CitaService) ListarPorUsuario(userID uint, desde, hasta *time.Time, incluirCanceladas bool) ([]models.Cita, error) {
+	query := s.db.Where("(estudiante_id = ? OR profesor_id = ?)", userID, userID)
+	if desde != nil {
+		query = query.Where("fin >= ?", *desde)
+	}
+	if hasta != nil {
+		query = query.Where("inicio <= ?", *hasta)
+	}
+	if !incluirCanceladas {
+		query = query.Where("estado = ?", EstadoCitaProgramada)
+	}
+
+	var citas []models.Cita
+	err := query.Order("inicio ASC").Find(&citas).Error
+	return citas, err
+}
+
+// RegenerarTokenCalendario crea un nuevo token para el feed .ics; el anterior deja de funcionar
+func (s *CitaService) RegenerarTokenCalendario(user *models.User) (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(bytes)
+
+	if err := ConActor(s.db, user).Model(&models.User{}).
+		Where("id = ?", user.ID).
+		Update("token_calendario", token).Error; err != nil {
+		return "", fmt.Errorf("error guardando token de calendario: %w", err)
+	}
+	return token, nil
+}
+
+// UsuarioPorTokenCalendario identifica al dueño de un feed .ics
+func (s *CitaService) UsuarioPorTokenCalendario(token string) (*models.User, error) {
+	var user models.User
+	if token == "" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if err := s.db.Where("token_calendario = ? AND activo = true", token).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// IniciarScheduler revisa periódicamente las citas próximas para enviar recordatorios
+func (s *CitaService) IniciarScheduler(intervalo time.Duration) {
+	ticker := time.NewTicker(intervalo)
+	go func() {
+		s.EnviarRecordatorios()
+		for range ticker.C {
+			s.EnviarRecordatorios()
+		}
+	}()
+}
+
+// EnviarRecordatorios avisa a los participantes de las citas que empiezan dentro de la ventana configurada
+func (s *CitaService) EnviarRecordatorios() {
+	ahora := time.Now()
+	horas := s.configuracionService.ObtenerEntero(ClaveCitasHorasRecordatorio, CitasHorasRecordatorio)
+
+	var proximas []models.Cita
+	if err := s.db.Where("estado = ? AND recordatorio_enviado_at IS NULL AND inicio > ? AND inicio <= ?",
+		EstadoCitaProgramada, ahora, ahora.Add(time.Duration(horas)*time.Hour)).
+		Find(&proximas).Error; err != nil {
+		log.Printf("Warning: no se pudieron consultar las citas próximas: %v", err)
+		return
+	}
+
+	enviados := 0
+	for i := range proximas {
+		cita := &proximas[i]
+		result := s.db.Model(&models.Cita{}).
+			Where("id = ? AND recordatorio_enviado_at IS NULL", cita.ID).
+			UpdateColumn("recordatorio_enviado_at", ahora)
+		if result.Error != nil || result.RowsAffected != 1 {
+			continue
+		}
+		s.notificationService.NotificarCita(cita, "cita_recordatorio", ParticipantesCita(cita))
+		enviados++
+	}
+
+	if enviados > 0 {
+		log.Printf("📅 Citas: %d recordatorios enviados", enviados)
+	}
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+)
+
+var (
+	ErrComentarioInvalido  = errors.New("comentario inválido")
+	ErrAdjuntoNoEncontrado = errors.New("adjunto no encontrado")
+)
+
+// patronMencion reconoce @nombre_usuario sin confundirlo con una dirección de correo
+var patronMencion = regexp.MustCompile(`(?:^|[^\w@])@([\w.-]*\w)`)
+
+// camposUsuarioHilo limita los datos del usuario que se exponen en el hilo
+const camposUsuarioHilo = "id, nombre_usuario, nombres, apellidos, role"
+
+// ComentarioService gestiona el hilo de discusión de cada control operativo
+type ComentarioService struct {
+	db *gorm.DB
+}
+
+func NewComentarioService(db *gorm.DB) *ComentarioService {
+	return &ComentarioService{db: db}
+}
+
+// directorioComentario es donde se guardan los archivos compartidos en un comentario
+func directorioComentario(controlID, comentarioID uint) string {
+	return fmt.Sprintf("storage/uploads/control-operativo/%d/comentarios/%d", controlID, comentarioID)
+}
+
+// ResolverMenciones retorna los usuarios activos mencionados con @nombre_usuario en el contenido
+func (s *ComentarioService) ResolverMenciones(contenido string) ([]models.User, error) {
+	var nombres []string
+	vistos := map[string]bool{}
+	for _, coincidencia := range patronMencion.FindAllStringSubmatch(contenido, -1) {
+		nombre := coincidencia[1]
+		if !vistos[nombre] {
+			vistos[nombre] = true
+			nombres = append(nombres, nombre)
+		}
+	}
+	if len(nombres) == 0 {
+		return nil, nil
+	}
+
+	var usuarios []models.User
+	err := s.db.Where("nombre_usuario IN ? AND activo = true", nombres).Find(&usuarios).Error
+	return usuarios, err
+}
+
+// Listar retorna el hilo del control en orden cronológico con menciones y confirmaciones de lectura
+func (s *ComentarioService) Listar(controlID uint) ([]models.Comentario, error) {
+	usuario := func(db *gorm.DB) *gorm.DB {
+		return db.Select(camposUsuarioHilo)
+	}
+
+	var comentarios []models.Comentario
+	err := s.db.Where("control_operativo_id = ?", controlID).
+		Preload("Autor", usuario).
+		Preload("Adjuntos").
+		Preload("Menciones.User", usuario).
+		Preload("Lecturas.User", usuario).
+		Order("created_at ASC, id ASC").
+		Find(&comentarios).Error
+	return comentarios, err
+}
+
+// Crear publica un comentario con sus menciones y asocia los archivos subidos a /api/upload/temp
+func (s *ComentarioService) Crear(controlID uint, req models.ComentarioRequest, mencionados []models.User, autor *models.User) (*models.Comentario, error) {
+	contenido := strings.TrimSpace(req.Contenido)
+	if contenido == "" {
+		return nil, fmt.Errorf("%w: el contenido es requerido", ErrComentarioInvalido)
+	}
+
+	comentario := models.Comentario{
+		ControlOperativoID: controlID,
+		AutorID:            autor.ID,
+		Contenido:          contenido,
+	}
+	if err := VerificarArchivosTemporales(req.Adjuntos, autor.ID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrComentarioInvalido, err)
+	}
+
+	var movidos []ArchivoMovido
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comentario).Error; err != nil {
+			return err
+		}
+		for _, usuario := range mencionados {
+			if usuario.ID == autor.ID {
+				continue
+			}
+			mencion := models.ComentarioMencion{ComentarioID: comentario.ID, UserID: usuario.ID}
+			if err := tx.Create(&mencion).Error; err != nil {
+				return err
+			}
+			comentario.Menciones = append(comentario.Menciones, mencion)
+		}
+		var err error
+		movidos, err = s.agregarAdjuntos(tx, &comentario, req.Adjuntos, autor.ID)
+		return err
+	})
+	if err != nil {
+		// Los archivos ya movidos vuelven al directorio temporal para poder asociarlos de nuevo
+		DevolverArchivosTemporales(movidos)
+		if comentario.ID != 0 {
+			os.RemoveAll(directorioComentario(controlID, comentario.ID))
+		}
+		if errors.Is(err, ErrComentarioInvalido) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error creando comentario: %w", err)
+	}
+	return &comentario, nil
+}
+
+// agregarAdjuntos mueve los archivos temporales al directorio del comentario y los registra en la
+// transacción indicada, con el nombre con el que el usuario los subió. Retorna los archivos que
+// alcanzaron a moverse, para devolverlos si la transacción falla
+func (s *ComentarioService) agregarAdjuntos(tx *gorm.DB, comentario *models.Comentario, archivos []string, userID uint) ([]ArchivoMovido, error) {
+	directorio := directorioComentario(comentario.ControlOperativoID, comentario.ID)
+	var movidos []ArchivoMovido
+	for _, nombre := range archivos {
+		movido, err := MoverArchivoTemporal(nombre, userID, directorio)
+		if err != nil {
+			if errors.Is(err, ErrArchivoTemporalNoEncontrado) {
+				return movidos, fmt.Errorf("%w: %v", ErrComentarioInvalido, err)
+			}
+			return movidos, err
+		}
+		movidos = append(movidos, *movido)
+
+		tipo := mime.TypeByExtension(filepath.Ext(movido.Nombre))
+		if tipo == "" {
+			tipo = "application/octet-stream"
+		}
+		adjunto := models.ComentarioAdjunto{
+			ComentarioID:   comentario.ID,
+			NombreOriginal: movido.NombreOriginal,
+			NombreArchivo:  movido.Nombre,
+			TipoArchivo:    tipo,
+			TamanoBytes:    movido.TamanoBytes,
+			RutaArchivo:    movido.Ruta,
+		}
+		if err := tx.Create(&adjunto).Error; err != nil {
+			return movidos, fmt.Errorf("error guardando adjunto del comentario: %w", err)
+		}
+		comentario.Adjuntos = append(comentario.Adjuntos, adjunto)
+	}
+	return movidos, nil
+}
+
+// ObtenerAdjunto busca un archivo compartido en el hilo del control indicado
+func (s *ComentarioService) ObtenerAdjunto(controlID, comentarioID, adjuntoID uint) (*models.ComentarioAdjunto, error) {
+	var adjunto models.ComentarioAdjunto
+	err := s.db.Joins("JOIN comentarios ON comentarios.id = comentario_adjuntos.comentario_id").
+		Where("comentario_adjuntos.id = ? AND comentario_adjuntos.comentario_id = ? AND comentarios.control_operativo_id = ?",
+			adjuntoID, comentarioID, controlID).
+		First(&adjunto).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAdjuntoNoEncontrado
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &adjunto, nil
+}
+
+// MarcarLeidos registra la lectura de todos los comentarios ajenos del hilo y marca como leídas
+// las notificaciones del hilo para el usuario
+func (s *ComentarioService) MarcarLeidos(controlID, userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO comentario_lecturas (comentario_id, user_id, leido_at)
+			SELECT id, ?, ? FROM comentarios WHERE control_operativo_id = ? AND autor_id <> ?
+			ON CONFLICT (comentario_id, user_id) DO NOTHING`,
+			userID, time.Now(), controlID, userID).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Notificacion{}).
+			Where("control_operativo_id = ? AND user_id = ? AND leida = false AND tipo_notificacion IN ?",
+				controlID, userID, []string{"nuevo_comentario", "mencion_comentario"}).
+			Update("leida", true).Error
+	})
+}
+
+// ContarNoLeidos cuenta los comentarios ajenos del hilo que el usuario aún no ha leído
+func (s *ComentarioService) ContarNoLeidos(controlID, userID uint) (int64, error) {
+	var total int64
+	err := s.db.Model(&models.Comentario{}).
+		Where("control_operativo_id = ? AND autor_id <> ?", controlID, userID).
+		Where("NOT EXISTS (SELECT 1 FROM comentario_lecturas WHERE comentario_lecturas.comentario_id = comentarios.id AND comentario_lecturas.user_id = ?)", userID).
+		Count(&total).Error
+	return total, err
+}
+
+// Participantes retorna los usuarios que siguen el hilo: el estudiante creador, el profesor
+// asignado y quienes ya comentaron
+func (s *ComentarioService) Participantes(control *models.ControlOperativo) ([]uint, error) {
+	var autores []uint
+	if err := s.db.Model(&models.Comentario{}).
+		Where("control_operativo_id = ?", control.ID).
+		Distinct().
+		Pluck("autor_id", &autores).Error; err != nil {
+		return nil, err
+	}
+
+	participantes := []uint{control.CreatedByID}
+	if control.ProfesorAsignadoID != nil {
+		participantes = append(participantes, *control.ProfesorAsignadoID)
+	}
+	return append(participantes, autores...), nil
+}
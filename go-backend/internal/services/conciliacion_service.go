@@ -0,0 +1,252 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+)
+
+const (
+	EstadoResultadoSolicitudConciliacion = "solicitud_conciliacion"
+
+	EstadoConciliacionEnTramite  = "en_tramite"
+	EstadoConciliacionFinalizada = "finalizada"
+
+	RolConvocante = "convocante"
+	RolConvocado  = "convocado"
+
+	ResultadoAcuerdoTotal   = "acuerdo_total"
+	ResultadoAcuerdoParcial = "acuerdo_parcial"
+	ResultadoNoAcuerdo      = "no_acuerdo"
+	ResultadoInasistencia   = "inasistencia"
+)
+
+// ResultadosConciliacion son los posibles desenlaces de la audiencia
+var ResultadosConciliacion = map[string]string{
+	ResultadoAcuerdoTotal:   "Acuerdo total",
+	ResultadoAcuerdoParcial: "Acuerdo parcial",
+	ResultadoNoAcuerdo:      "No acuerdo",
+	ResultadoInasistencia:   "Inasistencia",
+}
+
+var (
+	ErrConciliacionNoEncontrada = errors.New("el control no tiene trámite de conciliación")
+	ErrConciliacionInvalida     = errors.New("datos de conciliación inválidos")
+	ErrConciliacionFinalizada   = errors.New("la conciliación ya tiene resultado registrado")
+)
+
+// HayAcuerdo indica si el resultado se documenta con acta de conciliación en lugar de constancia
+func HayAcuerdo(resultado string) bool {
+	return resultado == ResultadoAcuerdoTotal || resultado == ResultadoAcuerdoParcial
+}
+
+// ConciliacionService gestiona el trámite de conciliación de los controles que lo requieren
+type ConciliacionService struct {
+	db              *gorm.DB
+	workflowService *WorkflowService
+}
+
+func NewConciliacionService(db *gorm.DB, workflowService *WorkflowService) *ConciliacionService {
+	return &ConciliacionService{
+		db:              db,
+		workflowService: workflowService,
+	}
+}
+
+// Iniciar abre el trámite con el consultante como convocante; si ya existe no hace nada
+func (s *ConciliacionService) Iniciar(tx *gorm.DB, control *models.ControlOperativo) error {
+	var total int64
+	if err := tx.Model(&models.Conciliacion{}).Where("control_operativo_id = ?", control.ID).Count(&total).Error; err != nil {
+		return err
+	}
+	if total > 0 {
+		return nil
+	}
+
+	telefono := control.NumeroCelular
+	if telefono == "" {
+		telefono = control.NumeroTelefonico
+	}
+	conciliacion := models.Conciliacion{
+		ControlOperativoID: control.ID,
+		Estado:             EstadoConciliacionEnTramite,
+		Partes: []models.ConciliacionParte{{
+			Rol:               RolConvocante,
+			Nombre:            control.NombreConsultante,
+			TipoDocumento:     control.TipoDocumento,
+			NumeroDocumento:   control.NumeroDocumento,
+			Direccion:         control.Direccion,
+			Telefono:          telefono,
+			CorreoElectronico: control.CorreoElectronico,
+		}},
+	}
+	if err := tx.Create(&conciliacion).Error; err != nil {
+		return fmt.Errorf("error iniciando conciliación: %w", err)
+	}
+	return nil
+}
+
+// Obtener retorna el trámite del control; los controles que ya tenían resultado
+// solicitud_conciliacion antes de existir el trámite lo abren en la primera consulta
+func (s *ConciliacionService) Obtener(control *models.ControlOperativo) (*models.Conciliacion, error) {
+	var conciliacion models.Conciliacion
+	consultar := func() error {
+		return s.db.Where("control_operativo_id = ?", control.ID).
+			Preload("Partes", func(db *gorm.DB) *gorm.DB {
+				return db.Order("rol ASC, id ASC")
+			}).
+			First(&conciliacion).Error
+	}
+
+	err := consultar()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if control.EstadoResultado == nil || *control.EstadoResultado != EstadoResultadoSolicitudConciliacion {
+			return nil, ErrConciliacionNoEncontrada
+		}
+		if err := s.Iniciar(s.db, control); err != nil {
+			return nil, err
+		}
+		err = consultar()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &conciliacion, nil
+}
+
+// Actualizar programa la audiencia y, si se envían, reemplaza las partes convocadas
+func (s *ConciliacionService) Actualizar(conciliacion *models.Conciliacion, req models.ConciliacionRequest, actor *models.User) error {
+	if conciliacion.Estado == EstadoConciliacionFinalizada {
+		return ErrConciliacionFinalizada
+	}
+
+	var partes []models.ConciliacionParte
+	for _, parte := range req.Partes {
+		rol := strings.ToLower(strings.TrimSpace(parte.Rol))
+		if rol != RolConvocante && rol != RolConvocado {
+			return fmt.Errorf("%w: rol de parte desconocido %s", ErrConciliacionInvalida, parte.Rol)
+		}
+		if strings.TrimSpace(parte.Nombre) == "" {
+			return fmt.Errorf("%w: el nombre de cada parte es requerido", ErrConciliacionInvalida)
+		}
+		partes = append(partes, models.ConciliacionParte{
+			ConciliacionID:    conciliacion.ID,
+			Rol:               rol,
+			Nombre:            strings.TrimSpace(parte.Nombre),
+			TipoDocumento:     parte.TipoDocumento,
+			NumeroDocumento:   strings.TrimSpace(parte.NumeroDocumento),
+			Direccion:         parte.Direccion,
+			Telefono:          parte.Telefono,
+			CorreoElectronico: parte.CorreoElectronico,
+		})
+	}
+
+	return ConActor(s.db, actor).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(conciliacion).Updates(map[string]interface{}{
+			"fecha_audiencia": req.FechaAudiencia,
+			"lugar_audiencia": strings.TrimSpace(req.LugarAudiencia),
+			"conciliador":     strings.TrimSpace(req.Conciliador),
+			"updated_at":      time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		conciliacion.FechaAudiencia = req.FechaAudiencia
+		conciliacion.LugarAudiencia = strings.TrimSpace(req.LugarAudiencia)
+		conciliacion.Conciliador = strings.TrimSpace(req.Conciliador)
+
+		if req.Partes == nil {
+			return nil
+		}
+		if err := tx.Where("conciliacion_id = ?", conciliacion.ID).Delete(&models.ConciliacionParte{}).Error; err != nil {
+			return err
+		}
+		if len(partes) > 0 {
+			if err := tx.Create(&partes).Error; err != nil {
+				return err
+			}
+		}
+		conciliacion.Partes = partes
+		return nil
+	})
+}
+
+// RegistrarResultado cierra el trámite con el resultado de la audiencia y lo deja en el historial del caso
+func (s *ConciliacionService) RegistrarResultado(control *models.ControlOperativo, conciliacion *models.Conciliacion, req models.ResultadoConciliacionRequest, actor *models.User) error {
+	if conciliacion.Estado == EstadoConciliacionFinalizada && actor.Role != "coordinador" {
+		return ErrConciliacionFinalizada
+	}
+	if _, ok := ResultadosConciliacion[req.Resultado]; !ok {
+		return fmt.Errorf("%w: resultado desconocido %s", ErrConciliacionInvalida, req.Resultado)
+	}
+	if conciliacion.FechaAudiencia == nil {
+		return fmt.Errorf("%w: primero debe programarse la audiencia", ErrConciliacionInvalida)
+	}
+
+	convocantes, convocados := 0, 0
+	for i := range conciliacion.Partes {
+		parte := &conciliacion.Partes[i]
+		if asistio, ok := req.Asistencia[parte.ID]; ok {
+			parte.Asistio = &asistio
+		}
+		if parte.Rol == RolConvocante {
+			convocantes++
+		} else {
+			convocados++
+		}
+	}
+	if convocantes == 0 || convocados == 0 {
+		return fmt.Errorf("%w: se requiere al menos un convocante y un convocado", ErrConciliacionInvalida)
+	}
+
+	acuerdos := strings.TrimSpace(req.Acuerdos)
+	if HayAcuerdo(req.Resultado) && acuerdos == "" {
+		return fmt.Errorf("%w: deben describirse los acuerdos alcanzados", ErrConciliacionInvalida)
+	}
+	if req.Resultado == ResultadoInasistencia {
+		ausente := false
+		for _, parte := range conciliacion.Partes {
+			if parte.Asistio != nil && !*parte.Asistio {
+				ausente = true
+			}
+		}
+		if !ausente {
+			return fmt.Errorf("%w: indique qué parte no asistió a la audiencia", ErrConciliacionInvalida)
+		}
+	}
+
+	ahora := time.Now()
+	return ConActor(s.db, actor).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(conciliacion).Updates(map[string]interface{}{
+			"estado":            EstadoConciliacionFinalizada,
+			"resultado":         req.Resultado,
+			"acuerdos":          acuerdos,
+			"observaciones":     strings.TrimSpace(req.Observaciones),
+			"fecha_resultado":   ahora,
+			"registrado_por_id": actor.ID,
+			"updated_at":        ahora,
+		}).Error; err != nil {
+			return err
+		}
+		for _, parte := range conciliacion.Partes {
+			if err := tx.Model(&models.ConciliacionParte{}).Where("id = ?", parte.ID).
+				Update("asistio", parte.Asistio).Error; err != nil {
+				return err
+			}
+		}
+
+		conciliacion.Estado = EstadoConciliacionFinalizada
+		conciliacion.Resultado = &req.Resultado
+		conciliacion.Acuerdos = acuerdos
+		conciliacion.Observaciones = strings.TrimSpace(req.Observaciones)
+		conciliacion.FechaResultado = &ahora
+		conciliacion.RegistradoPorID = &actor.ID
+
+		return s.workflowService.RegistrarTransicion(tx, control.ID, control.EstadoFlujo, control.EstadoFlujo, actor,
+			fmt.Sprintf("Resultado de conciliación: %s", ResultadosConciliacion[req.Resultado]))
+	})
+}
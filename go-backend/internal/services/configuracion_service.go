@@ -0,0 +1,61 @@
+package services
+
+import (
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"consultorio-juridico/internal/models"
+)
+
+// ConfiguracionService lee y guarda los parámetros ajustables por los coordinadores
+type ConfiguracionService struct {
+	db *gorm.DB
+}
+
+func NewConfiguracionService(db *gorm.DB) *ConfiguracionService {
+	return &ConfiguracionService{db: db}
+}
+
+// Obtener retorna el valor de la clave o el valor por defecto si no se ha configurado
+func (s *ConfiguracionService) Obtener(clave, defecto string) string {
+	var configuracion models.ConfiguracionSistema
+	if err := s.db.Where("clave = ?", clave).First(&configuracion).Error; err != nil {
+		return defecto
+	}
+	return configuracion.Valor
+}
+
+// ObtenerEntero retorna el valor numérico de la clave o el valor por defecto
+func (s *ConfiguracionService) ObtenerEntero(clave string, defecto int) int {
+	valor, err := strconv.Atoi(s.Obtener(clave, ""))
+	if err != nil {
+		return defecto
+	}
+	return valor
+}
+
+// Establecer crea o actualiza el valor de la clave
+func (s *ConfiguracionService) Establecer(clave, valor string, actor *models.User) error {
+	return s.EstablecerEn(s.db, clave, valor, actor)
+}
+
+// EstablecerEn crea o actualiza el valor de la clave dentro de la transacción indicada, para guardar
+// varias claves relacionadas a la vez
+func (s *ConfiguracionService) EstablecerEn(tx *gorm.DB, clave, valor string, actor *models.User) error {
+	configuracion := models.ConfiguracionSistema{
+		Clave:     clave,
+		Valor:     valor,
+		UpdatedAt: time.Now(),
+	}
+	if actor != nil {
+		configuracion.ActualizadoPorID = &actor.ID
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "clave"}},
+		DoUpdates: clause.AssignmentColumns([]string{"valor", "actualizado_por_id", "updated_at"}),
+	}).Create(&configuracion).Error
+}
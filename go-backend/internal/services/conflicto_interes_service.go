@@ -0,0 +1,250 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+)
+
+// Tipos de conflicto de interés
+const (
+	ConflictoConsultanteEsContraparte = "consultante_es_contraparte"
+	ConflictoContraparteEsConsultante = "contraparte_es_consultante"
+)
+
+var (
+	ErrSinConflictoPendiente = errors.New("el control no tiene conflictos de interés pendientes")
+	// ErrConflictoPendiente envuelve ErrTransicionNoPermitida para que los manejadores del flujo lo traten igual
+	ErrConflictoPendiente = fmt.Errorf("%w: el caso tiene un conflicto de interés pendiente de revisión por coordinación", ErrTransicionNoPermitida)
+)
+
+// ConflictoInteresService detecta cuando el consultorio asesoraría a ambas partes de una disputa
+type ConflictoInteresService struct {
+	db *gorm.DB
+}
+
+func NewConflictoInteresService(db *gorm.DB) *ConflictoInteresService {
+	return &ConflictoInteresService{db: db}
+}
+
+// normalizarNombre permite comparar nombres sin importar mayúsculas ni espacios repetidos
+func normalizarNombre(nombre string) string {
+	return strings.ToLower(strings.Join(strings.Fields(nombre), " "))
+}
+
+// nombreSQL aplica en SQL la normalización de normalizarNombre y además ignora las tildes, de modo
+// que "José  Pérez" y "jose perez" coincidan
+func nombreSQL(columna string) string {
+	return "unaccent(LOWER(REGEXP_REPLACE(TRIM(" + columna + "), '\\s+', ' ', 'g')))"
+}
+
+// GuardarContrapartes reemplaza las contrapartes del control. Los documentos se guardan
+// normalizados para compararlos con los de los consultantes
+func (s *ConflictoInteresService) GuardarContrapartes(tx *gorm.DB, controlID uint, contrapartes []models.ContraparteRequest) error {
+	if err := tx.Where("control_operativo_id = ?", controlID).Delete(&models.Contraparte{}).Error; err != nil {
+		return err
+	}
+
+	for _, req := range contrapartes {
+		nombre := strings.Join(strings.Fields(req.Nombre), " ")
+		if nombre == "" {
+			continue
+		}
+		tipo, numero := NormalizarDocumento(req.TipoDocumento, req.NumeroDocumento)
+		if numero == "" {
+			tipo = ""
+		}
+		contraparte := models.Contraparte{
+			ControlOperativoID: controlID,
+			Nombre:             nombre,
+			TipoDocumento:      tipo,
+			NumeroDocumento:    numero,
+		}
+		if err := tx.Create(&contraparte).Error; err != nil {
+			return fmt.Errorf("error guardando contraparte: %w", err)
+		}
+	}
+	return nil
+}
+
+// coincidencia es un caso activo donde una parte del control aparece del otro lado
+type coincidencia struct {
+	ControlID uint
+	Tipo      string
+	Parte     string
+	Documento string
+}
+
+// buscarCoincidencias compara el consultante y las contrapartes del control con los demás casos
+// activos. Se compara por documento y, cuando la parte no lo tiene, por nombre
+func (s *ConflictoInteresService) buscarCoincidencias(tx *gorm.DB, control *models.ControlOperativo) ([]coincidencia, error) {
+	var resultado []coincidencia
+
+	tipo, numero := NormalizarDocumento(control.TipoDocumento, control.NumeroDocumento)
+	nombre := normalizarNombre(control.NombreConsultante)
+
+	// El consultante figura como contraparte en otro caso
+	if numero != "" || nombre != "" {
+		var ids []uint
+		err := tx.Model(&models.Contraparte{}).
+			Joins("JOIN control_operativos ON control_operativos.id = contrapartes.control_operativo_id").
+			Where("control_operativos.activo = true AND contrapartes.control_operativo_id <> ?", control.ID).
+			Where("((? <> '' AND contrapartes.tipo_documento = ? AND contrapartes.numero_documento = ?) OR (contrapartes.numero_documento = '' AND ? <> '' AND "+nombreSQL("contrapartes.nombre")+" = unaccent(?)))",
+				numero, tipo, numero, nombre, nombre).
+			Distinct().
+			Pluck("contrapartes.control_operativo_id", &ids).Error
+		if err != nil {
+			return nil, err
+		}
+		documento := strings.TrimSpace(tipo + " " + numero)
+		for _, id := range ids {
+			resultado = append(resultado, coincidencia{ControlID: id, Tipo: ConflictoConsultanteEsContraparte, Parte: control.NombreConsultante, Documento: documento})
+		}
+	}
+
+	// Alguna contraparte fue consultante en otro caso
+	var contrapartes []models.Contraparte
+	if err := tx.Where("control_operativo_id = ?", control.ID).Find(&contrapartes).Error; err != nil {
+		return nil, err
+	}
+	for _, contraparte := range contrapartes {
+		query := tx.Model(&models.ControlOperativo{}).
+			Where("activo = true AND id <> ?", control.ID)
+		if contraparte.NumeroDocumento != "" {
+			// Los controles anteriores a los consultantes no están vinculados a uno, así que también se
+			// compara el número guardado en el control con la misma normalización del formulario
+			query = query.Where("(consultante_id IN (?) OR UPPER(REGEXP_REPLACE(numero_documento, '[^[:alnum:]]', '', 'g')) = ?)",
+				tx.Model(&models.Consultante{}).Select("id").
+					Where("tipo_documento = ? AND numero_documento = ?", contraparte.TipoDocumento, contraparte.NumeroDocumento),
+				contraparte.NumeroDocumento)
+		} else {
+			query = query.Where(nombreSQL("nombre_consultante")+" = unaccent(?)", normalizarNombre(contraparte.Nombre))
+		}
+
+		var ids []uint
+		if err := query.Pluck("id", &ids).Error; err != nil {
+			return nil, err
+		}
+		documento := strings.TrimSpace(contraparte.TipoDocumento + " " + contraparte.NumeroDocumento)
+		for _, id := range ids {
+			resultado = append(resultado, coincidencia{ControlID: id, Tipo: ConflictoContraparteEsConsultante, Parte: contraparte.Nombre, Documento: documento})
+		}
+	}
+
+	return resultado, nil
+}
+
+// Evaluar verifica el control contra los casos existentes y registra los conflictos encontrados.
+// Los conflictos que ya fueron reconocidos se conservan; si aparece uno nuevo el control queda
+// pendiente de revisión. Retorna los conflictos nuevos
+func (s *ConflictoInteresService) Evaluar(tx *gorm.DB, control *models.ControlOperativo) ([]models.ConflictoInteres, error) {
+	coincidencias, err := s.buscarCoincidencias(tx, control)
+	if err != nil {
+		return nil, err
+	}
+
+	var existentes []models.ConflictoInteres
+	if err := tx.Where("control_operativo_id = ?", control.ID).Find(&existentes).Error; err != nil {
+		return nil, err
+	}
+	previos := map[string]models.ConflictoInteres{}
+	for _, conflicto := range existentes {
+		previos[fmt.Sprintf("%s|%d", conflicto.Tipo, conflicto.ControlRelacionadoID)] = conflicto
+	}
+
+	vigentes := map[string]bool{}
+	var nuevos []models.ConflictoInteres
+	for _, c := range coincidencias {
+		llave := fmt.Sprintf("%s|%d", c.Tipo, c.ControlID)
+		if vigentes[llave] {
+			continue
+		}
+		vigentes[llave] = true
+		if _, ok := previos[llave]; ok {
+			continue
+		}
+		nuevos = append(nuevos, models.ConflictoInteres{
+			ControlOperativoID:   control.ID,
+			ControlRelacionadoID: c.ControlID,
+			Tipo:                 c.Tipo,
+			Parte:                c.Parte,
+			Documento:            c.Documento,
+		})
+	}
+
+	// Los conflictos que dejaron de existir (p. ej. se corrigió un documento) se descartan
+	for llave, conflicto := range previos {
+		if !vigentes[llave] {
+			if err := tx.Delete(&models.ConflictoInteres{}, conflicto.ID).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(nuevos) > 0 {
+		if err := tx.Create(&nuevos).Error; err != nil {
+			return nil, fmt.Errorf("error registrando conflicto de interés: %w", err)
+		}
+	}
+
+	var pendientes int64
+	if err := tx.Model(&models.ConflictoInteres{}).
+		Where("control_operativo_id = ? AND reconocido = false", control.ID).
+		Count(&pendientes).Error; err != nil {
+		return nil, err
+	}
+	control.ConflictoPendiente = pendientes > 0
+	if err := tx.Model(&models.ControlOperativo{}).Where("id = ?", control.ID).
+		UpdateColumn("conflicto_pendiente", control.ConflictoPendiente).Error; err != nil {
+		return nil, err
+	}
+
+	return nuevos, nil
+}
+
+// Listar retorna los conflictos registrados para el control
+func (s *ConflictoInteresService) Listar(controlID uint) ([]models.ConflictoInteres, error) {
+	var conflictos []models.ConflictoInteres
+	err := s.db.Preload("ReconocidoPor").
+		Where("control_operativo_id = ?", controlID).
+		Order("created_at ASC").
+		Find(&conflictos).Error
+	return conflictos, err
+}
+
+// Reconocer registra que un coordinador revisó los conflictos pendientes y el caso puede continuar
+func (s *ConflictoInteresService) Reconocer(tx *gorm.DB, control *models.ControlOperativo, coordinador *models.User, observaciones string) error {
+	if !control.ConflictoPendiente {
+		return ErrSinConflictoPendiente
+	}
+
+	ahora := time.Now()
+	if err := tx.Model(&models.ConflictoInteres{}).
+		Where("control_operativo_id = ? AND reconocido = false", control.ID).
+		Updates(map[string]interface{}{
+			"reconocido":        true,
+			"reconocido_por_id": coordinador.ID,
+			"reconocido_at":     ahora,
+			"observaciones":     observaciones,
+		}).Error; err != nil {
+		return err
+	}
+
+	control.ConflictoPendiente = false
+	return tx.Model(&models.ControlOperativo{}).Where("id = ?", control.ID).
+		UpdateColumn("conflicto_pendiente", false).Error
+}
+
+// ListarPendientes retorna los controles activos que esperan la revisión de coordinación
+func (s *ConflictoInteresService) ListarPendientes() ([]models.ControlOperativo, error) {
+	var controles []models.ControlOperativo
+	err := s.db.Preload("CreatedBy").Preload("Contrapartes").
+		Where("activo = true AND conflicto_pendiente = true").
+		Order("created_at ASC").
+		Find(&controles).Error
+	return controles, err
+}
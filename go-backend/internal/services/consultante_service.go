@@ -0,0 +1,357 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"consultorio-juridico/internal/models"
+	"consultorio-juridico/pkg/validacion"
+)
+
+// ClaveMigracionConsultantes marca que los controles existentes ya se agruparon en consultantes
+const ClaveMigracionConsultantes = "migracion.consultantes"
+
+var ErrConsultanteNoEncontrado = errors.New("consultante no encontrado")
+
+// NormalizarDocumento aplica la misma normalización que la validación del formulario, para que
+// "C.C. 1.023.456" y "CC 1023456" correspondan al mismo consultante
+func NormalizarDocumento(tipo, numero string) (string, string) {
+	return validacion.NormalizarTipoDocumento(tipo), validacion.NormalizarNumeroDocumento(numero)
+}
+
+// ResumenControlConsultante es un caso anterior del consultante
+type ResumenControlConsultante struct {
+	ID                 uint      `json:"id"`
+	CreatedAt          time.Time `json:"created_at"`
+	AreaConsulta       string    `json:"area_consulta"`
+	NombreEstudiante   string    `json:"nombre_estudiante"`
+	DocenteResponsable string    `json:"nombre_docente_responsable"`
+	EstadoFlujo        string    `json:"estado_flujo"`
+	EstadoResultado    *string   `json:"estado_resultado"`
+	Activo             bool      `json:"activo"`
+}
+
+// ConsultanteService mantiene el registro único de consultantes y su historial de casos
+type ConsultanteService struct {
+	db *gorm.DB
+}
+
+func NewConsultanteService(db *gorm.DB) *ConsultanteService {
+	return &ConsultanteService{db: db}
+}
+
+// datosDesdeControl toma los datos personales del consultante registrados en el control
+func datosDesdeControl(control *models.ControlOperativo, tipo, numero string) models.Consultante {
+	return models.Consultante{
+		TipoDocumento:      tipo,
+		NumeroDocumento:    numero,
+		Nombre:             strings.TrimSpace(control.NombreConsultante),
+		FechaNacimiento:    control.FechaNacimiento,
+		LugarNacimiento:    control.LugarNacimiento,
+		Sexo:               control.Sexo,
+		LugarExpedicion:    control.LugarExpedicion,
+		Direccion:          control.Direccion,
+		Barrio:             control.Barrio,
+		Estrato:            control.Estrato,
+		NumeroTelefonico:   control.NumeroTelefonico,
+		NumeroCelular:      control.NumeroCelular,
+		CorreoElectronico:  control.CorreoElectronico,
+		EstadoCivil:        control.EstadoCivil,
+		Escolaridad:        control.Escolaridad,
+		ProfesionOficio:    control.ProfesionOficio,
+	}
+}
+
+// guardarConsultante crea el consultante o actualiza sus datos con los más recientes
+func guardarConsultante(tx *gorm.DB, consultante *models.Consultante) error {
+	consultante.UpdatedAt = time.Now()
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "tipo_documento"}, {Name: "numero_documento"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"nombre", "fecha_nacimiento",
+			"lugar_nacimiento", "sexo", "lugar_expedicion", "direccion", "barrio", "estrato",
+			"numero_telefonico", "numero_celular", "correo_electronico", "estado_civil",
+			"escolaridad", "profesion_oficio", "updated_at",
+		}),
+	}).Create(consultante).Error
+}
+
+// CompletarDesdeConsultante llena los datos personales que el formulario dejó vacíos con los
+// del consultante ya registrado, para no volver a digitarlos cuando regresa
+func (s *ConsultanteService) CompletarDesdeConsultante(tx *gorm.DB, control *models.ControlOperativo, consultanteID uint) error {
+	var consultante models.Consultante
+	if err := tx.First(&consultante, consultanteID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrConsultanteNoEncontrado
+		}
+		return err
+	}
+	control.SincronizarFechas()
+	completarControl(control, &consultante)
+	return nil
+}
+
+// completarControl copia al control los datos del consultante que el control no trae. Las fechas
+// del control deben estar sincronizadas con sus partes
+func completarControl(control *models.ControlOperativo, consultante *models.Consultante) {
+	completar := func(destino *string, valor string) {
+		if strings.TrimSpace(*destino) == "" {
+			*destino = valor
+		}
+	}
+	completarEntero := func(destino *int, valor int) {
+		if *destino == 0 {
+			*destino = valor
+		}
+	}
+
+	completar(&control.TipoDocumento, consultante.TipoDocumento)
+	completar(&control.NumeroDocumento, consultante.NumeroDocumento)
+	completar(&control.NombreConsultante, consultante.Nombre)
+	if control.FechaNacimiento == nil {
+		control.AsignarFechaNacimiento(consultante.FechaNacimiento)
+	}
+	completar(&control.LugarNacimiento, consultante.LugarNacimiento)
+	completar(&control.Sexo, consultante.Sexo)
+	completar(&control.LugarExpedicion, consultante.LugarExpedicion)
+	completar(&control.Direccion, consultante.Direccion)
+	completar(&control.Barrio, consultante.Barrio)
+	completarEntero(&control.Estrato, consultante.Estrato)
+	completar(&control.NumeroTelefonico, consultante.NumeroTelefonico)
+	completar(&control.NumeroCelular, consultante.NumeroCelular)
+	completar(&control.CorreoElectronico, consultante.CorreoElectronico)
+	completar(&control.EstadoCivil, consultante.EstadoCivil)
+	completar(&control.Escolaridad, consultante.Escolaridad)
+	completar(&control.ProfesionOficio, consultante.ProfesionOficio)
+}
+
+// Vincular asocia el control al consultante de su documento, creándolo si no existe. Los datos
+// del control pasan a ser los vigentes del consultante. Sin documento el control queda sin vincular.
+// Solo modifica el control en memoria; quien llama lo guarda junto con consultante_id
+func (s *ConsultanteService) Vincular(tx *gorm.DB, control *models.ControlOperativo) error {
+	tipo, numero := NormalizarDocumento(control.TipoDocumento, control.NumeroDocumento)
+	if tipo == "" || numero == "" {
+		control.ConsultanteID = nil
+		return nil
+	}
+	// La fecha de nacimiento llega por partes desde el formulario y el consultante guarda la fecha
+	control.SincronizarFechas()
+
+	// Un consultante que regresa conserva los datos que esta vez no se diligenciaron
+	var existente models.Consultante
+	err := tx.Where("tipo_documento = ? AND numero_documento = ?", tipo, numero).First(&existente).Error
+	if err == nil {
+		completarControl(control, &existente)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	consultante := datosDesdeControl(control, tipo, numero)
+	if err := guardarConsultante(tx, &consultante); err != nil {
+		return fmt.Errorf("error guardando consultante: %w", err)
+	}
+	// Con ON CONFLICT el ID retornado puede no venir; se consulta por la llave natural
+	if consultante.ID == 0 {
+		if err := tx.Where("tipo_documento = ? AND numero_documento = ?", tipo, numero).
+			First(&consultante).Error; err != nil {
+			return err
+		}
+	}
+
+	control.ConsultanteID = &consultante.ID
+	return nil
+}
+
+// Obtener busca un consultante por ID
+func (s *ConsultanteService) Obtener(id uint) (*models.Consultante, error) {
+	var consultante models.Consultante
+	err := s.db.First(&consultante, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrConsultanteNoEncontrado
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &consultante, nil
+}
+
+// BuscarPorDocumento encuentra al consultante que regresa para precargar el formulario
+func (s *ConsultanteService) BuscarPorDocumento(tipo, numero string) (*models.Consultante, error) {
+	tipo, numero = NormalizarDocumento(tipo, numero)
+	var consultante models.Consultante
+	err := s.db.Where("tipo_documento = ? AND numero_documento = ?", tipo, numero).First(&consultante).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrConsultanteNoEncontrado
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &consultante, nil
+}
+
+// ListarControles retorna los casos del consultante, del más reciente al más antiguo. Si visible no
+// es nil, solo incluye los controles para los que retorna true
+func (s *ConsultanteService) ListarControles(consultanteID uint, visible func(*models.ControlOperativo) bool) ([]ResumenControlConsultante, error) {
+	var controles []models.ControlOperativo
+	if err := s.db.Where("consultante_id = ?", consultanteID).
+		Order("created_at DESC").
+		Find(&controles).Error; err != nil {
+		return nil, err
+	}
+
+	resumen := make([]ResumenControlConsultante, 0, len(controles))
+	for i := range controles {
+		control := &controles[i]
+		if visible != nil && !visible(control) {
+			continue
+		}
+		resumen = append(resumen, ResumenControlConsultante{
+			ID:                 control.ID,
+			CreatedAt:          control.CreatedAt,
+			AreaConsulta:       control.AreaConsulta,
+			NombreEstudiante:   control.NombreEstudiante,
+			DocenteResponsable: control.NombreDocenteResponsable,
+			EstadoFlujo:        control.EstadoFlujo,
+			EstadoResultado:    control.EstadoResultado,
+			Activo:             control.Activo,
+		})
+	}
+	return resumen, nil
+}
+
+// MigrarSiPendiente ejecuta una sola vez la agrupación de los controles existentes en consultantes
+func (s *ConsultanteService) MigrarSiPendiente(configuracionService *ConfiguracionService) error {
+	if configuracionService.Obtener(ClaveMigracionConsultantes, "") != "" {
+		return nil
+	}
+
+	consultantes, controles, err := s.MigrarDesdeControles()
+	if err != nil {
+		return err
+	}
+	log.Printf("✅ Migración de consultantes: %d consultantes, %d controles vinculados", consultantes, controles)
+	return configuracionService.Establecer(ClaveMigracionConsultantes, time.Now().Format(time.RFC3339), nil)
+}
+
+// NormalizarTiposRegistrados corrige los consultantes y contrapartes guardados con el tipo sin
+// normalizar (p. ej. "C.C."). Si ya existe el consultante con el tipo normalizado, sus controles
+// pasan a ese registro y se elimina el duplicado. Solo actúa sobre los registros pendientes, así que
+// puede ejecutarse en cada arranque
+func (s *ConsultanteService) NormalizarTiposRegistrados() (int, error) {
+	var pendientes []models.Consultante
+	if err := s.db.Where("tipo_documento !~ '^[A-Z]+$'").Find(&pendientes).Error; err != nil {
+		return 0, err
+	}
+
+	corregidos := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, consultante := range pendientes {
+			tipo := validacion.NormalizarTipoDocumento(consultante.TipoDocumento)
+			if tipo == consultante.TipoDocumento {
+				continue
+			}
+
+			var existente models.Consultante
+			err := tx.Where("tipo_documento = ? AND numero_documento = ?", tipo, consultante.NumeroDocumento).First(&existente).Error
+			switch {
+			case err == nil:
+				if err := tx.Model(&models.ControlOperativo{}).
+					Where("consultante_id = ?", consultante.ID).
+					Update("consultante_id", existente.ID).Error; err != nil {
+					return err
+				}
+				if err := tx.Delete(&models.Consultante{}, consultante.ID).Error; err != nil {
+					return err
+				}
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				if err := tx.Model(&models.Consultante{}).
+					Where("id = ?", consultante.ID).
+					Update("tipo_documento", tipo).Error; err != nil {
+					return err
+				}
+			default:
+				return err
+			}
+			corregidos++
+		}
+
+		return tx.Model(&models.Contraparte{}).
+			Where("tipo_documento !~ '^[A-Z]*$'").
+			Update("tipo_documento", gorm.Expr("UPPER(REGEXP_REPLACE(tipo_documento, '[^[:alpha:]]', '', 'g'))")).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return corregidos, nil
+}
+
+// MigrarDesdeControles agrupa los controles sin consultante por documento normalizado. Cada
+// consultante queda con los datos de su control más reciente
+func (s *ConsultanteService) MigrarDesdeControles() (int, int, error) {
+	var pendientes []models.ControlOperativo
+	if err := s.db.Where("consultante_id IS NULL AND COALESCE(numero_documento, '') <> ''").
+		Order("created_at ASC, id ASC").
+		Find(&pendientes).Error; err != nil {
+		return 0, 0, err
+	}
+
+	type grupo struct {
+		tipo, numero string
+		ultimo       *models.ControlOperativo
+		ids          []uint
+	}
+	grupos := map[string]*grupo{}
+	var orden []string
+	for i := range pendientes {
+		control := &pendientes[i]
+		tipo, numero := NormalizarDocumento(control.TipoDocumento, control.NumeroDocumento)
+		if tipo == "" || numero == "" {
+			continue
+		}
+		llave := tipo + "|" + numero
+		g, ok := grupos[llave]
+		if !ok {
+			g = &grupo{tipo: tipo, numero: numero}
+			grupos[llave] = g
+			orden = append(orden, llave)
+		}
+		// Los controles vienen en orden de creación, así que el último gana
+		g.ultimo = control
+		g.ids = append(g.ids, control.ID)
+	}
+
+	vinculados := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, llave := range orden {
+			g := grupos[llave]
+
+			// Un consultante ya creado por un control nuevo conserva sus datos vigentes
+			var consultante models.Consultante
+			err := tx.Where("tipo_documento = ? AND numero_documento = ?", g.tipo, g.numero).First(&consultante).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				consultante = datosDesdeControl(g.ultimo, g.tipo, g.numero)
+				err = tx.Create(&consultante).Error
+			}
+			if err != nil {
+				return fmt.Errorf("error migrando consultante %s: %w", llave, err)
+			}
+
+			if err := tx.Model(&models.ControlOperativo{}).Where("id IN ?", g.ids).
+				UpdateColumn("consultante_id", consultante.ID).Error; err != nil {
+				return err
+			}
+			vinculados += len(g.ids)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(orden), vinculados, nil
+}
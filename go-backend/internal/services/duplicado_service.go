@@ -0,0 +1,157 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+)
+
+const (
+	// VentanaDuplicadosMeses cubre tres semestres, suficiente para casos repetidos entre periodos
+	VentanaDuplicadosMeses = 18
+	// UmbralSolapamientoDescripcion es la proporción de palabras compartidas a partir de la cual
+	// dos descripciones se consideran el mismo problema
+	UmbralSolapamientoDescripcion = 0.35
+	maxCandidatosDuplicado        = 50
+)
+
+// PosibleDuplicado es un caso reciente que parece corresponder al mismo consultante y problema
+type PosibleDuplicado struct {
+	ControlID               uint      `json:"control_id"`
+	CreatedAt               time.Time `json:"created_at"`
+	NombreConsultante       string    `json:"nombre_consultante"`
+	NumeroDocumento         string    `json:"numero_documento"`
+	AreaConsulta            string    `json:"area_consulta"`
+	NombreEstudiante        string    `json:"nombre_estudiante"`
+	EstadoFlujo             string    `json:"estado_flujo"`
+	Motivos                 []string  `json:"motivos"`
+	SolapamientoDescripcion float64   `json:"solapamiento_descripcion"`
+}
+
+// DuplicadoService detecta casos registrados dos veces para el mismo consultante y problema
+type DuplicadoService struct {
+	db *gorm.DB
+}
+
+func NewDuplicadoService(db *gorm.DB) *DuplicadoService {
+	return &DuplicadoService{db: db}
+}
+
+var sinTildes = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n")
+
+// palabrasSignificativas retorna las palabras de cuatro letras o más, sin tildes ni mayúsculas
+func palabrasSignificativas(texto string) map[string]bool {
+	palabras := map[string]bool{}
+	campos := strings.FieldsFunc(sinTildes.Replace(strings.ToLower(texto)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, palabra := range campos {
+		if len([]rune(palabra)) >= 4 {
+			palabras[palabra] = true
+		}
+	}
+	return palabras
+}
+
+// SolapamientoTexto mide qué tanto coinciden dos descripciones (índice de Jaccard de sus palabras)
+func SolapamientoTexto(a, b string) float64 {
+	palabrasA := palabrasSignificativas(a)
+	palabrasB := palabrasSignificativas(b)
+	if len(palabrasA) == 0 || len(palabrasB) == 0 {
+		return 0
+	}
+
+	comunes := 0
+	for palabra := range palabrasA {
+		if palabrasB[palabra] {
+			comunes++
+		}
+	}
+	return float64(comunes) / float64(len(palabrasA)+len(palabrasB)-comunes)
+}
+
+// BuscarPosiblesDuplicados compara el control que se va a crear con los casos activos recientes.
+// Un candidato es el mismo consultante (documento exacto o nombre sin importar tildes) y se
+// reporta cuando además coincide el área o la descripción del problema
+func (s *DuplicadoService) BuscarPosiblesDuplicados(control *models.ControlOperativo) ([]PosibleDuplicado, error) {
+	documento := strings.TrimSpace(control.NumeroDocumento)
+	nombre := strings.ToLower(strings.Join(strings.Fields(control.NombreConsultante), " "))
+
+	var condiciones []string
+	var valores []interface{}
+	if documento != "" {
+		condiciones = append(condiciones, "TRIM(numero_documento) = ?")
+		valores = append(valores, documento)
+	}
+	if control.ConsultanteID != nil {
+		condiciones = append(condiciones, "consultante_id = ?")
+		valores = append(valores, *control.ConsultanteID)
+	}
+	if nombre != "" {
+		condiciones = append(condiciones, "unaccent(LOWER(REGEXP_REPLACE(TRIM(nombre_consultante), '\\s+', ' ', 'g'))) = unaccent(?)")
+		valores = append(valores, nombre)
+	}
+	if len(condiciones) == 0 {
+		return nil, nil
+	}
+
+	var candidatos []models.ControlOperativo
+	err := s.db.Where("activo = true AND id <> ? AND created_at >= ?", control.ID, time.Now().AddDate(0, -VentanaDuplicadosMeses, 0)).
+		Where("("+strings.Join(condiciones, " OR ")+")", valores...).
+		Order("created_at DESC").
+		Limit(maxCandidatosDuplicado).
+		Find(&candidatos).Error
+	if err != nil {
+		return nil, fmt.Errorf("error buscando posibles duplicados: %w", err)
+	}
+
+	nombreSinTildes := sinTildes.Replace(nombre)
+	var duplicados []PosibleDuplicado
+	for _, candidato := range candidatos {
+		// Con documentos distintos se trata de otra persona aunque el nombre coincida
+		documentoCandidato := strings.TrimSpace(candidato.NumeroDocumento)
+		if documento != "" && documentoCandidato != "" && documentoCandidato != documento {
+			continue
+		}
+
+		var motivos []string
+		if documento != "" && documentoCandidato == documento {
+			motivos = append(motivos, "mismo número de documento")
+		} else if control.ConsultanteID != nil && candidato.ConsultanteID != nil && *candidato.ConsultanteID == *control.ConsultanteID {
+			motivos = append(motivos, "mismo consultante")
+		}
+		if nombreSinTildes != "" && sinTildes.Replace(strings.ToLower(strings.Join(strings.Fields(candidato.NombreConsultante), " "))) == nombreSinTildes {
+			motivos = append(motivos, "mismo nombre de consultante")
+		}
+
+		mismaArea := strings.EqualFold(strings.TrimSpace(candidato.AreaConsulta), strings.TrimSpace(control.AreaConsulta))
+		solapamiento := SolapamientoTexto(candidato.DescripcionCaso, control.DescripcionCaso)
+		if !mismaArea && solapamiento < UmbralSolapamientoDescripcion {
+			continue
+		}
+		if mismaArea {
+			motivos = append(motivos, "misma área de consulta")
+		}
+		if solapamiento >= UmbralSolapamientoDescripcion {
+			motivos = append(motivos, fmt.Sprintf("descripción similar (%.0f%%)", solapamiento*100))
+		}
+
+		duplicados = append(duplicados, PosibleDuplicado{
+			ControlID:               candidato.ID,
+			CreatedAt:               candidato.CreatedAt,
+			NombreConsultante:       candidato.NombreConsultante,
+			NumeroDocumento:         candidato.NumeroDocumento,
+			AreaConsulta:            candidato.AreaConsulta,
+			NombreEstudiante:        candidato.NombreEstudiante,
+			EstadoFlujo:             candidato.EstadoFlujo,
+			Motivos:                 motivos,
+			SolapamientoDescripcion: solapamiento,
+		})
+	}
+	return duplicados, nil
+}
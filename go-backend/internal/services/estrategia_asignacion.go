@@ -0,0 +1,202 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+)
+
+const (
+	ClaveEstrategiaAsignacion = "asignacion.estrategia"
+
+	EstrategiaMenorCarga = "menor_carga"
+	EstrategiaRoundRobin = "round_robin"
+
+	// Llave del bloqueo de Postgres que serializa las asignaciones automáticas
+	bloqueoAsignacionAutomatica = 7310001
+)
+
+var ErrSinProfesoresDisponibles = errors.New("no hay profesores activos disponibles para asignar")
+
+// CandidatoAsignacion es un profesor activo con su carga actual de casos
+type CandidatoAsignacion struct {
+	ProfesorID        uint       `json:"profesor_id"`
+	Nombres           string     `json:"nombres"`
+	Apellidos         string     `json:"apellidos"`
+	Sede              string     `json:"sede"`
+	AreasEspecialidad string     `json:"areas_especialidad"`
+	Pendientes        int64      `json:"pendientes"`
+	UltimaAsignacion  *time.Time `json:"ultima_asignacion"`
+}
+
+// AtiendeArea indica si el profesor puede recibir casos del área; sin especialidad atiende todas
+func (c CandidatoAsignacion) AtiendeArea(area string) bool {
+	if strings.TrimSpace(c.AreasEspecialidad) == "" {
+		return true
+	}
+	for _, especialidad := range strings.Split(c.AreasEspecialidad, ",") {
+		if strings.EqualFold(strings.TrimSpace(especialidad), strings.TrimSpace(area)) {
+			return true
+		}
+	}
+	return false
+}
+
+// EstrategiaAsignacion elige un profesor entre los candidatos que cumplen sede y área
+type EstrategiaAsignacion interface {
+	Seleccionar(candidatos []CandidatoAsignacion) CandidatoAsignacion
+}
+
+// estrategiaMenorCarga elige al profesor con menos casos pendiente_profesor
+type estrategiaMenorCarga struct{}
+
+func (estrategiaMenorCarga) Seleccionar(candidatos []CandidatoAsignacion) CandidatoAsignacion {
+	ordenados := append([]CandidatoAsignacion(nil), candidatos...)
+	sort.SliceStable(ordenados, func(i, j int) bool {
+		if ordenados[i].Pendientes != ordenados[j].Pendientes {
+			return ordenados[i].Pendientes < ordenados[j].Pendientes
+		}
+		return asignadoAntes(ordenados[i], ordenados[j])
+	})
+	return ordenados[0]
+}
+
+// estrategiaRoundRobin elige al profesor que lleva más tiempo sin recibir un caso
+type estrategiaRoundRobin struct{}
+
+func (estrategiaRoundRobin) Seleccionar(candidatos []CandidatoAsignacion) CandidatoAsignacion {
+	ordenados := append([]CandidatoAsignacion(nil), candidatos...)
+	sort.SliceStable(ordenados, func(i, j int) bool {
+		return asignadoAntes(ordenados[i], ordenados[j])
+	})
+	return ordenados[0]
+}
+
+// asignadoAntes ordena primero a quien nunca ha recibido casos y luego por la asignación más antigua
+func asignadoAntes(a, b CandidatoAsignacion) bool {
+	switch {
+	case a.UltimaAsignacion == nil && b.UltimaAsignacion != nil:
+		return true
+	case a.UltimaAsignacion != nil && b.UltimaAsignacion == nil:
+		return false
+	case a.UltimaAsignacion != nil && !a.UltimaAsignacion.Equal(*b.UltimaAsignacion):
+		return a.UltimaAsignacion.Before(*b.UltimaAsignacion)
+	}
+	return a.ProfesorID < b.ProfesorID
+}
+
+// EstrategiasAsignacion son las estrategias que los coordinadores pueden seleccionar
+var EstrategiasAsignacion = map[string]EstrategiaAsignacion{
+	EstrategiaMenorCarga: estrategiaMenorCarga{},
+	EstrategiaRoundRobin: estrategiaRoundRobin{},
+}
+
+// EstrategiaActual retorna el nombre de la estrategia configurada por los coordinadores
+func (s *AsignacionService) EstrategiaActual() string {
+	nombre := s.configuracionService.Obtener(ClaveEstrategiaAsignacion, EstrategiaMenorCarga)
+	if _, ok := EstrategiasAsignacion[nombre]; !ok {
+		return EstrategiaMenorCarga
+	}
+	return nombre
+}
+
+// EstablecerEstrategia cambia la estrategia usada en las asignaciones automáticas
+func (s *AsignacionService) EstablecerEstrategia(nombre string, actor *models.User) error {
+	if _, ok := EstrategiasAsignacion[nombre]; !ok {
+		return fmt.Errorf("estrategia de asignación desconocida: %s", nombre)
+	}
+	return s.configuracionService.Establecer(ClaveEstrategiaAsignacion, nombre, actor)
+}
+
+// CandidatosAsignacion lista los profesores activos con su carga de casos pendientes
+func (s *AsignacionService) CandidatosAsignacion(tx *gorm.DB) ([]CandidatoAsignacion, error) {
+	var candidatos []CandidatoAsignacion
+	err := tx.Raw(`
+		SELECT
+			u.id AS profesor_id,
+			u.nombres,
+			u.apellidos,
+			COALESCE(NULLIF(p.sede, ''), u.sede) AS sede,
+			COALESCE(p.areas_especialidad, '') AS areas_especialidad,
+			(SELECT COUNT(*) FROM control_operativos co
+				WHERE co.profesor_asignado_id = u.id AND co.activo = true AND co.estado_flujo = ?) AS pendientes,
+			(SELECT MAX(co.created_at) FROM control_operativos co
+				WHERE co.profesor_asignado_id = u.id) AS ultima_asignacion
+		FROM users u
+		LEFT JOIN profesors p ON p.user_id = u.id
+		WHERE u.role = 'profesor' AND u.activo = true
+		ORDER BY u.id
+	`, EstadoPendienteProfesor).Scan(&candidatos).Error
+	return candidatos, err
+}
+
+// AsignarAutomaticamente elige el profesor para un caso nuevo. Prefiere profesores de la sede del
+// estudiante que atiendan el área; si no hay, relaja primero la sede y luego el área
+func (s *AsignacionService) AsignarAutomaticamente(tx *gorm.DB, estudiante *models.User, area string) (*models.User, error) {
+	// Evita que dos casos creados al mismo tiempo vean la misma carga y caigan en el mismo profesor
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", bloqueoAsignacionAutomatica).Error; err != nil {
+		return nil, err
+	}
+
+	candidatos, err := s.CandidatosAsignacion(tx)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidatos) == 0 {
+		return nil, ErrSinProfesoresDisponibles
+	}
+
+	mismaSede := func(c CandidatoAsignacion) bool {
+		return estudiante.Sede != "" && strings.EqualFold(strings.TrimSpace(c.Sede), strings.TrimSpace(estudiante.Sede))
+	}
+	filtros := []func(CandidatoAsignacion) bool{
+		func(c CandidatoAsignacion) bool { return mismaSede(c) && c.AtiendeArea(area) },
+		func(c CandidatoAsignacion) bool { return c.AtiendeArea(area) },
+		mismaSede,
+		func(CandidatoAsignacion) bool { return true },
+	}
+
+	estrategia := EstrategiasAsignacion[s.EstrategiaActual()]
+	for _, filtro := range filtros {
+		var elegibles []CandidatoAsignacion
+		for _, candidato := range candidatos {
+			if filtro(candidato) {
+				elegibles = append(elegibles, candidato)
+			}
+		}
+		if len(elegibles) == 0 {
+			continue
+		}
+		elegido := estrategia.Seleccionar(elegibles)
+		return s.ObtenerProfesorActivo(tx, elegido.ProfesorID)
+	}
+	return nil, ErrSinProfesoresDisponibles
+}
+
+// EstablecerAreasEspecialidad define las áreas que atiende un profesor
+func (s *AsignacionService) EstablecerAreasEspecialidad(profesorID uint, areas []string) (string, error) {
+	var limpias []string
+	for _, area := range areas {
+		if area = strings.TrimSpace(area); area != "" {
+			limpias = append(limpias, area)
+		}
+	}
+	valor := strings.Join(limpias, ",")
+
+	result := s.db.Model(&models.Profesor{}).
+		Where("user_id = ?", profesorID).
+		Updates(map[string]interface{}{"areas_especialidad": valor, "updated_at": time.Now()})
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return "", ErrProfesorNoDisponible
+	}
+	return valor, nil
+}
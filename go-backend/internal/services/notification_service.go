@@ -1,7 +1,10 @@
 package services
 
 import (
+	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -72,14 +75,7 @@ func (s *NotificationService) NotificarControlCompletadoAEstudiante(controlOpera
 
 // Crear notificación cuando el coordinador asigna un resultado
 func (s *NotificationService) NotificarResultadoAsignadoAEstudiante(controlOperativoID uint, estudianteID uint, resultado string) error {
-	mensajeResultado := map[string]string{
-		"asesoria_consulta":      "Asesoría/Consulta",
-		"auto_reparto":           "Auto Reparto",
-		"reparto":                "Reparto",
-		"solicitud_conciliacion": "Solicitud de Conciliación",
-	}
-
-	mensaje := "Se ha asignado el resultado final a tu control operativo: " + mensajeResultado[resultado]
+	mensaje := "Se ha asignado el resultado final a tu control operativo: " + EstadosResultado[resultado]
 
 	notificacion := models.Notificacion{
 		ControlOperativoID: controlOperativoID,
@@ -98,6 +94,385 @@ func (s *NotificationService) NotificarResultadoAsignadoAEstudiante(controlOpera
 	return nil
 }
 
+// Crear notificación cuando el estudiante modifica un control que el profesor tiene en revisión
+func (s *NotificationService) NotificarControlEditadoAProfesor(controlOperativoID uint, profesorID uint, campos []string) error {
+	mensaje := fmt.Sprintf("El estudiante actualizó el control operativo #%d que tienes en revisión", controlOperativoID)
+	if len(campos) > 0 {
+		mensaje += ". Campos modificados: " + strings.Join(campos, ", ")
+	}
+
+	notificacion := models.Notificacion{
+		ControlOperativoID: controlOperativoID,
+		UserID:             profesorID,
+		TipoNotificacion:   "control_editado",
+		Mensaje:            mensaje,
+		Leida:              false,
+	}
+
+	if err := s.db.Create(&notificacion).Error; err != nil {
+		log.Printf("Error creando notificación: %v", err)
+		return err
+	}
+
+	log.Printf("✉️ Notificación de edición enviada al profesor ID %d", profesorID)
+	return nil
+}
+
+// Crear notificación cuando el profesor devuelve el control para correcciones
+func (s *NotificationService) NotificarControlDevueltoAEstudiante(controlOperativoID uint, estudianteID uint, observaciones string) error {
+	notificacion := models.Notificacion{
+		ControlOperativoID: controlOperativoID,
+		UserID:             estudianteID,
+		TipoNotificacion:   "control_devuelto",
+		Mensaje:            fmt.Sprintf("El profesor devolvió tu control operativo #%d para correcciones: %s", controlOperativoID, observaciones),
+		Leida:              false,
+	}
+
+	if err := s.db.Create(&notificacion).Error; err != nil {
+		log.Printf("Error creando notificación: %v", err)
+		return err
+	}
+
+	log.Printf("✉️ Notificación de devolución enviada al estudiante ID %d", estudianteID)
+	return nil
+}
+
+// Crear notificación cuando el estudiante reenvía el control corregido
+func (s *NotificationService) NotificarControlReenviadoAProfesor(controlOperativoID uint, profesorID uint, ronda int) error {
+	notificacion := models.Notificacion{
+		ControlOperativoID: controlOperativoID,
+		UserID:             profesorID,
+		TipoNotificacion:   "control_reenviado",
+		Mensaje:            fmt.Sprintf("El estudiante reenvió el control operativo #%d con las correcciones de la ronda %d", controlOperativoID, ronda),
+		Leida:              false,
+	}
+
+	if err := s.db.Create(&notificacion).Error; err != nil {
+		log.Printf("Error creando notificación: %v", err)
+		return err
+	}
+
+	log.Printf("✉️ Notificación de reenvío enviada al profesor ID %d", profesorID)
+	return nil
+}
+
+// Crear notificaciones para el profesor anterior, el nuevo y el estudiante cuando se reasigna un control
+func (s *NotificationService) NotificarReasignacion(reasignacion Reasignacion) error {
+	notificaciones := []models.Notificacion{
+		{
+			ControlOperativoID: reasignacion.ControlID,
+			UserID:             reasignacion.ProfesorNuevoID,
+			TipoNotificacion:   "nuevo_control_asignado",
+			Mensaje:            fmt.Sprintf("Se te ha reasignado el control operativo #%d para completar la sección V", reasignacion.ControlID),
+		},
+		{
+			ControlOperativoID: reasignacion.ControlID,
+			UserID:             reasignacion.EstudianteID,
+			TipoNotificacion:   "profesor_reasignado",
+			Mensaje:            fmt.Sprintf("Tu control operativo #%d ahora está a cargo del profesor %s", reasignacion.ControlID, reasignacion.ProfesorNuevo),
+		},
+	}
+	if reasignacion.ProfesorAnteriorID != nil && *reasignacion.ProfesorAnteriorID != reasignacion.ProfesorNuevoID {
+		notificaciones = append(notificaciones, models.Notificacion{
+			ControlOperativoID: reasignacion.ControlID,
+			UserID:             *reasignacion.ProfesorAnteriorID,
+			TipoNotificacion:   "control_reasignado",
+			Mensaje:            fmt.Sprintf("El control operativo #%d fue reasignado al profesor %s", reasignacion.ControlID, reasignacion.ProfesorNuevo),
+		})
+	}
+
+	if err := s.db.Create(&notificaciones).Error; err != nil {
+		log.Printf("Error creando notificaciones de reasignación: %v", err)
+		return err
+	}
+
+	log.Printf("✉️ Notificaciones de reasignación enviadas para el control ID %d", reasignacion.ControlID)
+	return nil
+}
+
+// Recordar al profesor que el plazo para emitir el concepto está por vencer
+func (s *NotificationService) NotificarSLAPorVencer(controlOperativoID uint, profesorID uint, fechaLimite time.Time) error {
+	notificacion := models.Notificacion{
+		ControlOperativoID: controlOperativoID,
+		UserID:             profesorID,
+		TipoNotificacion:   "sla_por_vencer",
+		Mensaje: fmt.Sprintf("El plazo para emitir el concepto del control operativo #%d vence el %s",
+			controlOperativoID, fechaLimite.Format("02/01/2006")),
+		Leida: false,
+	}
+
+	if err := s.db.Create(&notificacion).Error; err != nil {
+		log.Printf("Error creando notificación: %v", err)
+		return err
+	}
+
+	log.Printf("✉️ Recordatorio de SLA enviado al profesor ID %d", profesorID)
+	return nil
+}
+
+// Escalar a los coordinadores (y avisar al profesor) un control cuyo plazo ya venció
+func (s *NotificationService) NotificarSLAVencido(controlOperativoID uint, profesorID *uint, fechaLimite time.Time) error {
+	mensaje := fmt.Sprintf("El control operativo #%d superó el plazo para el concepto del asesor (venció el %s)",
+		controlOperativoID, fechaLimite.Format("02/01/2006"))
+
+	if profesorID != nil {
+		notificacion := models.Notificacion{
+			ControlOperativoID: controlOperativoID,
+			UserID:             *profesorID,
+			TipoNotificacion:   "sla_vencido",
+			Mensaje:            mensaje,
+			Leida:              false,
+		}
+		if err := s.db.Create(&notificacion).Error; err != nil {
+			log.Printf("Error creando notificación: %v", err)
+		}
+	}
+
+	return s.CrearNotificacion(0, controlOperativoID, "sla_vencido", mensaje, "coordinador")
+}
+
+// Crear notificación al estudiante cuando su caso se cierra
+func (s *NotificationService) NotificarControlCerrado(controlOperativoID uint, estudianteID uint, motivo string) error {
+	notificacion := models.Notificacion{
+		ControlOperativoID: controlOperativoID,
+		UserID:             estudianteID,
+		TipoNotificacion:   "control_cerrado",
+		Mensaje:            fmt.Sprintf("El control operativo #%d fue cerrado: %s", controlOperativoID, MotivosCierre[motivo]),
+		Leida:              false,
+	}
+
+	if err := s.db.Create(&notificacion).Error; err != nil {
+		log.Printf("Error creando notificación: %v", err)
+		return err
+	}
+
+	log.Printf("✉️ Notificación de cierre enviada al estudiante ID %d", estudianteID)
+	return nil
+}
+
+// Crear notificaciones al estudiante y al profesor cuando el coordinador reabre un caso
+func (s *NotificationService) NotificarControlReabierto(controlOperativoID uint, estudianteID uint, profesorID *uint) error {
+	mensaje := fmt.Sprintf("El coordinador reabrió el control operativo #%d", controlOperativoID)
+	notificaciones := []models.Notificacion{{
+		ControlOperativoID: controlOperativoID,
+		UserID:             estudianteID,
+		TipoNotificacion:   "control_reabierto",
+		Mensaje:            mensaje,
+	}}
+	if profesorID != nil {
+		notificaciones = append(notificaciones, models.Notificacion{
+			ControlOperativoID: controlOperativoID,
+			UserID:             *profesorID,
+			TipoNotificacion:   "control_reabierto",
+			Mensaje:            mensaje,
+		})
+	}
+
+	if err := s.db.Create(&notificaciones).Error; err != nil {
+		log.Printf("Error creando notificaciones de reapertura: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Crear notificaciones al estudiante y al profesor cuando un coordinador desactiva o restaura un control
+func (s *NotificationService) NotificarCambioActivacion(controlOperativoID uint, estudianteID uint, profesorID *uint, activo bool, motivo string) error {
+	tipo := "control_desactivado"
+	mensaje := fmt.Sprintf("El coordinador desactivó el control operativo #%d: %s", controlOperativoID, motivo)
+	if activo {
+		tipo = "control_restaurado"
+		mensaje = fmt.Sprintf("El coordinador restauró el control operativo #%d", controlOperativoID)
+		if motivo != "" {
+			mensaje += ": " + motivo
+		}
+	}
+
+	notificaciones := []models.Notificacion{{
+		ControlOperativoID: controlOperativoID,
+		UserID:             estudianteID,
+		TipoNotificacion:   tipo,
+		Mensaje:            mensaje,
+	}}
+	if profesorID != nil {
+		notificaciones = append(notificaciones, models.Notificacion{
+			ControlOperativoID: controlOperativoID,
+			UserID:             *profesorID,
+			TipoNotificacion:   tipo,
+			Mensaje:            mensaje,
+		})
+	}
+
+	if err := s.db.Create(&notificaciones).Error; err != nil {
+		log.Printf("Error creando notificaciones de %s: %v", tipo, err)
+		return err
+	}
+	return nil
+}
+
+// NotificarResultadoConciliacion avisa al estudiante y al profesor del caso el resultado de la audiencia
+func (s *NotificationService) NotificarResultadoConciliacion(controlOperativoID uint, estudianteID uint, profesorID *uint, resultado string) error {
+	mensaje := fmt.Sprintf("Se registró el resultado de la conciliación del control operativo #%d: %s",
+		controlOperativoID, ResultadosConciliacion[resultado])
+
+	notificaciones := []models.Notificacion{{
+		ControlOperativoID: controlOperativoID,
+		UserID:             estudianteID,
+		TipoNotificacion:   "conciliacion_resultado",
+		Mensaje:            mensaje,
+	}}
+	if profesorID != nil {
+		notificaciones = append(notificaciones, models.Notificacion{
+			ControlOperativoID: controlOperativoID,
+			UserID:             *profesorID,
+			TipoNotificacion:   "conciliacion_resultado",
+			Mensaje:            mensaje,
+		})
+	}
+
+	if err := s.db.Create(&notificaciones).Error; err != nil {
+		log.Printf("Error creando notificaciones de conciliación: %v", err)
+		return err
+	}
+	return nil
+}
+
+// NotificarCita avisa a los participantes de una cita que fue programada, reprogramada, cancelada o está próxima
+func (s *NotificationService) NotificarCita(cita *models.Cita, tipo string, destinatarios []uint) error {
+	var mensaje string
+	fecha := cita.Inicio.Local().Format("02/01/2006 15:04")
+	switch tipo {
+	case "cita_programada":
+		mensaje = fmt.Sprintf("Se programó una cita del control operativo #%d para el %s en %s", cita.ControlOperativoID, fecha, cita.Lugar)
+	case "cita_reprogramada":
+		mensaje = fmt.Sprintf("La cita del control operativo #%d se movió al %s en %s", cita.ControlOperativoID, fecha, cita.Lugar)
+	case "cita_cancelada":
+		mensaje = fmt.Sprintf("Se canceló la cita del control operativo #%d del %s: %s", cita.ControlOperativoID, fecha, cita.MotivoCancelacion)
+	default:
+		mensaje = fmt.Sprintf("Recordatorio: cita del control operativo #%d el %s en %s", cita.ControlOperativoID, fecha, cita.Lugar)
+	}
+
+	var notificaciones []models.Notificacion
+	for _, userID := range destinatarios {
+		notificaciones = append(notificaciones, models.Notificacion{
+			ControlOperativoID: cita.ControlOperativoID,
+			UserID:             userID,
+			TipoNotificacion:   tipo,
+			Mensaje:            mensaje,
+		})
+	}
+	if len(notificaciones) == 0 {
+		return nil
+	}
+
+	if err := s.db.Create(&notificaciones).Error; err != nil {
+		log.Printf("Error creando notificaciones de %s: %v", tipo, err)
+		return err
+	}
+	return nil
+}
+
+// NotificarComentario avisa del nuevo comentario a los participantes del hilo; los mencionados
+// reciben una notificación de mención en lugar de la general. El autor no se notifica
+func (s *NotificationService) NotificarComentario(comentario *models.Comentario, autor *models.User, participantes []uint) error {
+	nombreAutor := strings.TrimSpace(autor.Nombres + " " + autor.Apellidos)
+	mencionados := map[uint]bool{}
+	for _, mencion := range comentario.Menciones {
+		mencionados[mencion.UserID] = true
+	}
+
+	notificados := map[uint]bool{autor.ID: true}
+	var notificaciones []models.Notificacion
+	agregar := func(userID uint, tipo, mensaje string) {
+		if notificados[userID] {
+			return
+		}
+		notificados[userID] = true
+		notificaciones = append(notificaciones, models.Notificacion{
+			ControlOperativoID: comentario.ControlOperativoID,
+			UserID:             userID,
+			TipoNotificacion:   tipo,
+			Mensaje:            mensaje,
+		})
+	}
+
+	for userID := range mencionados {
+		agregar(userID, "mencion_comentario",
+			fmt.Sprintf("%s te mencionó en el control operativo #%d", nombreAutor, comentario.ControlOperativoID))
+	}
+	for _, userID := range participantes {
+		agregar(userID, "nuevo_comentario",
+			fmt.Sprintf("%s comentó en el control operativo #%d", nombreAutor, comentario.ControlOperativoID))
+	}
+	if len(notificaciones) == 0 {
+		return nil
+	}
+
+	if err := s.db.Create(&notificaciones).Error; err != nil {
+		log.Printf("Error creando notificaciones del comentario #%d: %v", comentario.ID, err)
+		return err
+	}
+	return nil
+}
+
+// maxControlesEnAviso limita cuántos números de control se listan en un aviso resumido
+const maxControlesEnAviso = 15
+
+// listaControles arma "#1, #2 y #3", abreviando las listas largas
+func listaControles(ids []uint) string {
+	mostrados := ids
+	if len(mostrados) > maxControlesEnAviso {
+		mostrados = mostrados[:maxControlesEnAviso]
+	}
+	partes := make([]string, len(mostrados))
+	for i, id := range mostrados {
+		partes[i] = fmt.Sprintf("#%d", id)
+	}
+	if len(ids) > len(mostrados) {
+		return fmt.Sprintf("%s y %d más", strings.Join(partes, ", "), len(ids)-len(mostrados))
+	}
+	if len(partes) == 1 {
+		return partes[0]
+	}
+	return strings.Join(partes[:len(partes)-1], ", ") + " y " + partes[len(partes)-1]
+}
+
+// NotificarOperacionMasiva envía a cada usuario afectado un solo aviso con todos sus controles
+// modificados. mensajes asocia cada tipo de cambio con la función que arma su texto a partir de la
+// lista de controles; si el usuario se vio afectado de varias formas los textos se juntan
+func (s *NotificationService) NotificarOperacionMasiva(avisos []AvisoOperacionMasiva, mensajes map[string]func(controles string) string) error {
+	notificaciones := make([]models.Notificacion, 0, len(avisos))
+	for _, aviso := range avisos {
+		if len(aviso.Partes) == 0 {
+			continue
+		}
+		textos := make([]string, len(aviso.Partes))
+		for i, parte := range aviso.Partes {
+			textos[i] = mensajes[parte.Tipo](listaControles(parte.ControlIDs))
+		}
+		tipo := aviso.Partes[0].Tipo
+		if len(aviso.Partes) > 1 {
+			tipo = TipoAvisoOperacionMasiva
+		}
+		notificaciones = append(notificaciones, models.Notificacion{
+			// La notificación enlaza al primer control; el mensaje lista todos
+			ControlOperativoID: aviso.Partes[0].ControlIDs[0],
+			UserID:             aviso.UserID,
+			TipoNotificacion:   tipo,
+			Mensaje:            strings.Join(textos, ". "),
+		})
+	}
+	if len(notificaciones) == 0 {
+		return nil
+	}
+
+	if err := s.db.Create(&notificaciones).Error; err != nil {
+		log.Printf("Error creando notificaciones de operación masiva: %v", err)
+		return err
+	}
+	log.Printf("✉️ %d notificaciones resumidas de operación masiva enviadas", len(notificaciones))
+	return nil
+}
+
 // Obtener notificaciones de un usuario
 func (s *NotificationService) ObtenerNotificacionesUsuario(userID uint) ([]models.Notificacion, error) {
 	var notificaciones []models.Notificacion
@@ -0,0 +1,422 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+)
+
+const (
+	OperacionMasivaResultado     = "resultado"
+	OperacionMasivaReasignacion  = "reasignacion"
+	OperacionMasivaDesactivacion = "desactivacion"
+
+	// MaxControlesOperacionMasiva limita cuántos controles se modifican en una sola transacción
+	MaxControlesOperacionMasiva = 500
+)
+
+// Resultados posibles de cada control dentro de una operación masiva
+const (
+	ItemAplicado  = "aplicado"
+	ItemOmitido   = "omitido"
+	ItemFallido   = "error"
+	ItemRevertido = "revertido"
+)
+
+var ErrSeleccionMasivaInvalida = errors.New("selección de controles inválida")
+
+// errRevertirOperacion deshace la transacción completa cuando se pidió todo_o_nada y algún control falló
+var errRevertirOperacion = errors.New("operación masiva revertida")
+
+// omitido indica que el control ya estaba como se pedía y no se modificó
+type omitido string
+
+func (o omitido) Error() string { return string(o) }
+
+// SeleccionMasiva indica sobre qué controles actúa la operación: una lista de IDs o los mismos
+// filtros del listado de controles, con al menos un criterio. Con todo_o_nada, activo salvo que se
+// envíe false, un solo error revierte todos los cambios; sin él se guardan los controles que sí se
+// pudieron modificar y el reporte indica cuáles fallaron
+type SeleccionMasiva struct {
+	ControlIDs []uint        `json:"control_ids"`
+	Filtros    *FilterParams `json:"filtros"`
+	TodoONada  *bool         `json:"todo_o_nada"`
+}
+
+// todoONada indica si un fallo debe revertir toda la operación; es el comportamiento por defecto
+func (s SeleccionMasiva) todoONada() bool {
+	return s.TodoONada == nil || *s.TodoONada
+}
+
+// filtrosVacios indica que los filtros no restringen el listado, lo que seleccionaría todos los casos
+func filtrosVacios(f FilterParams) bool {
+	return f.EstadoFlujo == "" && f.EstadoResultado == "" && f.AreaConsulta == "" && f.CreatedByID == 0 &&
+		f.ProfesorResponsable == "" && f.DateFrom == "" && f.DateTo == "" && f.Activo == nil && f.SLA == ""
+}
+
+type ResultadoMasivoRequest struct {
+	SeleccionMasiva
+	EstadoResultado string `json:"estado_resultado" binding:"required"`
+}
+
+type ReasignacionMasivaFiltrosRequest struct {
+	SeleccionMasiva
+	ProfesorID uint   `json:"profesor_id" binding:"required"`
+	Motivo     string `json:"motivo"`
+}
+
+type DesactivacionMasivaRequest struct {
+	SeleccionMasiva
+	Motivo string `json:"motivo" binding:"required"`
+}
+
+// ItemOperacionMasiva es el resultado de la operación sobre un control
+type ItemOperacionMasiva struct {
+	ControlID uint   `json:"control_id"`
+	Resultado string `json:"resultado"`
+	Mensaje   string `json:"mensaje,omitempty"`
+}
+
+// ReporteOperacionMasiva resume lo que pasó con cada control de la operación
+type ReporteOperacionMasiva struct {
+	Operacion string                `json:"operacion"`
+	Total     int                   `json:"total"`
+	Aplicados int                   `json:"aplicados"`
+	Omitidos  int                   `json:"omitidos"`
+	Fallidos  int                   `json:"fallidos"`
+	TodoONada bool                  `json:"todo_o_nada"`
+	Revertida bool                  `json:"revertida"`
+	Items     []ItemOperacionMasiva `json:"items"`
+}
+
+// TipoAvisoOperacionMasiva es el tipo de la notificación de un usuario afectado de varias formas por
+// la misma operación, por ejemplo un profesor que recibe unos controles y pierde otros
+const TipoAvisoOperacionMasiva = "operacion_masiva"
+
+// AvisoOperacionMasiva es la notificación resumida que recibe un usuario por una operación masiva;
+// cada parte agrupa los controles que lo afectaron de la misma forma
+type AvisoOperacionMasiva struct {
+	UserID uint
+	Partes []ParteAvisoMasivo
+}
+
+// ParteAvisoMasivo es una sección del aviso resumido: un tipo de cambio y los controles afectados
+type ParteAvisoMasivo struct {
+	Tipo       string
+	ControlIDs []uint
+}
+
+// agregar suma el control a la parte del tipo indicado, creándola si es la primera vez
+func (a *AvisoOperacionMasiva) agregar(tipo string, controlID uint) {
+	for i := range a.Partes {
+		if a.Partes[i].Tipo != tipo {
+			continue
+		}
+		// Un usuario puede figurar dos veces por el mismo control, por ejemplo como creador y profesor
+		if ids := a.Partes[i].ControlIDs; len(ids) > 0 && ids[len(ids)-1] == controlID {
+			return
+		}
+		a.Partes[i].ControlIDs = append(a.Partes[i].ControlIDs, controlID)
+		return
+	}
+	a.Partes = append(a.Partes, ParteAvisoMasivo{Tipo: tipo, ControlIDs: []uint{controlID}})
+}
+
+// avisoMasivo indica a quién avisar por un control y de qué forma lo afectó; todos los avisos
+// de un mismo usuario se juntan en una sola notificación
+type avisoMasivo struct {
+	userID uint
+	tipo   string
+}
+
+// accionMasiva modifica un control dentro de la transacción y retorna a quién avisar
+type accionMasiva func(tx *gorm.DB, control *models.ControlOperativo) ([]avisoMasivo, error)
+
+// OperacionMasivaService aplica a muchos controles las operaciones que el coordinador hace uno a uno
+type OperacionMasivaService struct {
+	db                  *gorm.DB
+	queryService        *QueryService
+	workflowService     *WorkflowService
+	asignacionService   *AsignacionService
+	conciliacionService *ConciliacionService
+	slaService          *SLAService
+	notificationService *NotificationService
+}
+
+func NewOperacionMasivaService(db *gorm.DB, workflowService *WorkflowService, asignacionService *AsignacionService, conciliacionService *ConciliacionService, slaService *SLAService, notificationService *NotificationService) *OperacionMasivaService {
+	return &OperacionMasivaService{
+		db:                  db,
+		queryService:        NewQueryService(db),
+		workflowService:     workflowService,
+		asignacionService:   asignacionService,
+		conciliacionService: conciliacionService,
+		slaService:          slaService,
+		notificationService: notificationService,
+	}
+}
+
+// resolverSeleccion convierte la selección en la lista de IDs sobre la que se opera
+func (s *OperacionMasivaService) resolverSeleccion(seleccion SeleccionMasiva) ([]uint, error) {
+	if len(seleccion.ControlIDs) > 0 && seleccion.Filtros != nil {
+		return nil, fmt.Errorf("%w: indica control_ids o filtros, no ambos", ErrSeleccionMasivaInvalida)
+	}
+
+	var ids []uint
+	switch {
+	case len(seleccion.ControlIDs) > 0:
+		vistos := map[uint]bool{}
+		for _, id := range seleccion.ControlIDs {
+			if !vistos[id] {
+				vistos[id] = true
+				ids = append(ids, id)
+			}
+		}
+	case seleccion.Filtros != nil:
+		filtros := *seleccion.Filtros
+		if filtrosVacios(filtros) {
+			return nil, fmt.Errorf("%w: los filtros deben incluir al menos un criterio", ErrSeleccionMasivaInvalida)
+		}
+		if filtros.SLA != "" {
+			if filtros.SLA != FiltroSLAVencido && filtros.SLA != FiltroSLAPorVencer {
+				return nil, fmt.Errorf("%w: filtro sla no válido (vencido, por_vencer)", ErrSeleccionMasivaInvalida)
+			}
+			filtros.DiasAvisoSLA = s.slaService.ObtenerConfiguracion().DiasAviso
+		}
+		// Se pide uno más del máximo para detectar selecciones demasiado grandes
+		encontrados, err := s.queryService.GetControlesOperativosIDs(filtros, MaxControlesOperacionMasiva+1)
+		if err != nil {
+			return nil, err
+		}
+		ids = encontrados
+	default:
+		return nil, fmt.Errorf("%w: indica control_ids o filtros", ErrSeleccionMasivaInvalida)
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: ningún control cumple la selección", ErrSeleccionMasivaInvalida)
+	}
+	if len(ids) > MaxControlesOperacionMasiva {
+		return nil, fmt.Errorf("%w: la selección supera el máximo de %d controles", ErrSeleccionMasivaInvalida, MaxControlesOperacionMasiva)
+	}
+	return ids, nil
+}
+
+// ejecutar aplica la acción a cada control en una sola transacción. Cada control corre en su
+// propio punto de guardado, de modo que un fallo solo descarta los cambios de ese control salvo
+// que se haya pedido todo_o_nada. Las notificaciones se agrupan en un aviso por usuario
+func (s *OperacionMasivaService) ejecutar(operacion string, seleccion SeleccionMasiva, actor *models.User, accion accionMasiva) (*ReporteOperacionMasiva, []AvisoOperacionMasiva, error) {
+	ids, err := s.resolverSeleccion(seleccion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reporte := &ReporteOperacionMasiva{Operacion: operacion, Total: len(ids), TodoONada: seleccion.todoONada()}
+	var avisos []AvisoOperacionMasiva
+	indiceAvisos := map[uint]int{}
+
+	err = ConActor(s.db, actor).Transaction(func(tx *gorm.DB) error {
+		var controles []models.ControlOperativo
+		if err := tx.Where("id IN ?", ids).Find(&controles).Error; err != nil {
+			return err
+		}
+		porID := make(map[uint]*models.ControlOperativo, len(controles))
+		for i := range controles {
+			porID[controles[i].ID] = &controles[i]
+		}
+
+		for _, id := range ids {
+			item := ItemOperacionMasiva{ControlID: id}
+			control, ok := porID[id]
+			if !ok {
+				item.Resultado = ItemFallido
+				item.Mensaje = "Control operativo no encontrado"
+				reporte.Fallidos++
+				reporte.Items = append(reporte.Items, item)
+				continue
+			}
+
+			var destinatarios []avisoMasivo
+			err := tx.Transaction(func(sp *gorm.DB) error {
+				var err error
+				destinatarios, err = accion(sp, control)
+				return err
+			})
+			var sinCambios omitido
+			switch {
+			case err == nil:
+				item.Resultado = ItemAplicado
+				reporte.Aplicados++
+				for _, destinatario := range destinatarios {
+					pos, ok := indiceAvisos[destinatario.userID]
+					if !ok {
+						pos = len(avisos)
+						indiceAvisos[destinatario.userID] = pos
+						avisos = append(avisos, AvisoOperacionMasiva{UserID: destinatario.userID})
+					}
+					avisos[pos].agregar(destinatario.tipo, id)
+				}
+			case errors.As(err, &sinCambios):
+				item.Resultado = ItemOmitido
+				item.Mensaje = sinCambios.Error()
+				reporte.Omitidos++
+			default:
+				item.Resultado = ItemFallido
+				item.Mensaje = mensajeErrorMasivo(err)
+				reporte.Fallidos++
+			}
+			reporte.Items = append(reporte.Items, item)
+		}
+
+		if seleccion.todoONada() && reporte.Fallidos > 0 {
+			return errRevertirOperacion
+		}
+		return nil
+	})
+	if errors.Is(err, errRevertirOperacion) {
+		reporte.Revertida = true
+		reporte.Aplicados = 0
+		for i := range reporte.Items {
+			if reporte.Items[i].Resultado == ItemAplicado {
+				reporte.Items[i].Resultado = ItemRevertido
+			}
+		}
+		return reporte, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return reporte, avisos, nil
+}
+
+// mensajeErrorMasivo traduce el error de un control a un mensaje para el reporte
+func mensajeErrorMasivo(err error) string {
+	switch {
+	case errors.Is(err, ErrActorNoAutorizado):
+		return "No tienes permisos para modificar este control"
+	case errors.Is(err, ErrTransicionNoPermitida), errors.Is(err, ErrProfesorNoDisponible):
+		return err.Error()
+	default:
+		log.Printf("Error en operación masiva: %v", err)
+		return "Error actualizando el control"
+	}
+}
+
+// AsignarResultados establece el mismo estado resultado en todos los controles seleccionados
+func (s *OperacionMasivaService) AsignarResultados(req ResultadoMasivoRequest, actor *models.User) (*ReporteOperacionMasiva, error) {
+	if !EsEstadoResultadoValido(req.EstadoResultado) {
+		return nil, fmt.Errorf("%w: estado resultado no válido", ErrSeleccionMasivaInvalida)
+	}
+
+	reporte, avisos, err := s.ejecutar(OperacionMasivaResultado, req.SeleccionMasiva, actor, func(tx *gorm.DB, control *models.ControlOperativo) ([]avisoMasivo, error) {
+		if control.EstadoFlujo == EstadoConResultado && control.EstadoResultado != nil && *control.EstadoResultado == req.EstadoResultado {
+			return nil, omitido("El control ya tiene ese resultado")
+		}
+
+		comentario := fmt.Sprintf("Resultado asignado por coordinador (operación masiva): %s", req.EstadoResultado)
+		if err := s.workflowService.Transicionar(tx, control, EstadoConResultado, actor, comentario,
+			map[string]interface{}{"estado_resultado": req.EstadoResultado}); err != nil {
+			return nil, err
+		}
+		if req.EstadoResultado == EstadoResultadoSolicitudConciliacion {
+			if err := s.conciliacionService.Iniciar(tx, control); err != nil {
+				return nil, err
+			}
+		}
+		return []avisoMasivo{{control.CreatedByID, "resultado_asignado"}}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resultado := EstadosResultado[req.EstadoResultado]
+	go s.notificationService.NotificarOperacionMasiva(avisos, map[string]func(string) string{
+		"resultado_asignado": func(controles string) string {
+			return fmt.Sprintf("El coordinador asignó el resultado %s a tus controles operativos %s", resultado, controles)
+		},
+	})
+	return reporte, nil
+}
+
+// ReasignarProfesor mueve los controles seleccionados al profesor indicado
+func (s *OperacionMasivaService) ReasignarProfesor(req ReasignacionMasivaFiltrosRequest, actor *models.User) (*ReporteOperacionMasiva, error) {
+	profesor, err := s.asignacionService.ObtenerProfesorActivo(s.db, req.ProfesorID)
+	if err != nil {
+		return nil, err
+	}
+	motivo := strings.TrimSpace(req.Motivo)
+
+	reporte, avisos, err := s.ejecutar(OperacionMasivaReasignacion, req.SeleccionMasiva, actor, func(tx *gorm.DB, control *models.ControlOperativo) ([]avisoMasivo, error) {
+		if control.ProfesorAsignadoID != nil && *control.ProfesorAsignadoID == profesor.ID {
+			return nil, omitido("El control ya está asignado a ese profesor")
+		}
+
+		reasignacion, err := s.asignacionService.Reasignar(tx, control, profesor, actor, motivo)
+		if err != nil {
+			return nil, err
+		}
+		destinatarios := []avisoMasivo{
+			{reasignacion.ProfesorNuevoID, "nuevo_control_asignado"},
+			{reasignacion.EstudianteID, "profesor_reasignado"},
+		}
+		if reasignacion.ProfesorAnteriorID != nil {
+			destinatarios = append(destinatarios, avisoMasivo{*reasignacion.ProfesorAnteriorID, "control_reasignado"})
+		}
+		return destinatarios, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	nombre := NombreProfesor(profesor)
+	go s.notificationService.NotificarOperacionMasiva(avisos, map[string]func(string) string{
+		"nuevo_control_asignado": func(controles string) string {
+			return fmt.Sprintf("Se te reasignaron los controles operativos %s para completar la sección V", controles)
+		},
+		"profesor_reasignado": func(controles string) string {
+			return fmt.Sprintf("Tus controles operativos %s ahora están a cargo del profesor %s", controles, nombre)
+		},
+		"control_reasignado": func(controles string) string {
+			return fmt.Sprintf("Los controles operativos %s fueron reasignados al profesor %s", controles, nombre)
+		},
+	})
+	return reporte, nil
+}
+
+// Desactivar oculta de los listados todos los controles seleccionados
+func (s *OperacionMasivaService) Desactivar(req DesactivacionMasivaRequest, actor *models.User) (*ReporteOperacionMasiva, error) {
+	motivo := strings.TrimSpace(req.Motivo)
+	if motivo == "" {
+		return nil, fmt.Errorf("%w: el motivo de desactivación es obligatorio", ErrSeleccionMasivaInvalida)
+	}
+
+	reporte, avisos, err := s.ejecutar(OperacionMasivaDesactivacion, req.SeleccionMasiva, actor, func(tx *gorm.DB, control *models.ControlOperativo) ([]avisoMasivo, error) {
+		if !control.Activo {
+			return nil, omitido("El control ya está desactivado")
+		}
+
+		if err := s.workflowService.Desactivar(tx, control, actor, motivo); err != nil {
+			return nil, err
+		}
+		destinatarios := []avisoMasivo{{control.CreatedByID, "control_desactivado"}}
+		if control.ProfesorAsignadoID != nil {
+			destinatarios = append(destinatarios, avisoMasivo{*control.ProfesorAsignadoID, "control_desactivado"})
+		}
+		return destinatarios, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go s.notificationService.NotificarOperacionMasiva(avisos, map[string]func(string) string{
+		"control_desactivado": func(controles string) string {
+			return fmt.Sprintf("El coordinador desactivó los controles operativos %s: %s", controles, motivo)
+		},
+	})
+	return reporte, nil
+}
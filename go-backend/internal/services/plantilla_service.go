@@ -0,0 +1,292 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+)
+
+// Tipos de campo que admite una plantilla de área
+const (
+	CampoTexto      = "texto"
+	CampoTextoLargo = "texto_largo"
+	CampoNumero     = "numero"
+	CampoFecha      = "fecha"
+	CampoOpcion     = "opcion"
+	CampoSiNo       = "si_no"
+)
+
+var tiposCampoPlantilla = map[string]bool{
+	CampoTexto: true, CampoTextoLargo: true, CampoNumero: true,
+	CampoFecha: true, CampoOpcion: true, CampoSiNo: true,
+}
+
+var (
+	ErrPlantillaNoEncontrada = errors.New("plantilla no encontrada")
+	ErrPlantillaInvalida     = errors.New("plantilla inválida")
+	ErrPlantillaDuplicada    = errors.New("ya existe una plantilla para esa área")
+)
+
+// patronClaveCampo limita las claves a identificadores simples para usarlas como llaves JSON
+var patronClaveCampo = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// ErrorRespuestasPlantilla reúne los campos de la plantilla que el estudiante no diligenció bien
+type ErrorRespuestasPlantilla struct {
+	Area   string
+	Campos map[string]string
+}
+
+func (e *ErrorRespuestasPlantilla) Error() string {
+	claves := make([]string, 0, len(e.Campos))
+	for clave := range e.Campos {
+		claves = append(claves, clave)
+	}
+	sort.Strings(claves)
+	return fmt.Sprintf("los campos de la plantilla de %s no son válidos: %s", e.Area, strings.Join(claves, ", "))
+}
+
+// PlantillaService gestiona las plantillas que orientan la recepción de casos por área
+type PlantillaService struct {
+	db *gorm.DB
+}
+
+func NewPlantillaService(db *gorm.DB) *PlantillaService {
+	return &PlantillaService{db: db}
+}
+
+// Listar retorna las plantillas ordenadas por área; los estudiantes solo ven las activas
+func (s *PlantillaService) Listar(soloActivas bool) ([]models.PlantillaArea, error) {
+	var plantillas []models.PlantillaArea
+	query := s.db.Order("area_consulta ASC")
+	if soloActivas {
+		query = query.Where("activa = true")
+	}
+	err := query.Find(&plantillas).Error
+	return plantillas, err
+}
+
+// ObtenerPorArea busca la plantilla activa del área sin distinguir mayúsculas ni espacios
+func (s *PlantillaService) ObtenerPorArea(area string) (*models.PlantillaArea, error) {
+	var plantilla models.PlantillaArea
+	err := s.db.Where("LOWER(area_consulta) = LOWER(?) AND activa = true", strings.TrimSpace(area)).First(&plantilla).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPlantillaNoEncontrada
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &plantilla, nil
+}
+
+// validarDefinicion revisa que los campos de la plantilla estén bien definidos
+func validarDefinicion(req *models.PlantillaAreaRequest) error {
+	if strings.TrimSpace(req.AreaConsulta) == "" {
+		return fmt.Errorf("%w: el área es requerida", ErrPlantillaInvalida)
+	}
+
+	claves := map[string]bool{}
+	for _, campo := range req.Campos {
+		if !patronClaveCampo.MatchString(campo.Clave) {
+			return fmt.Errorf("%w: la clave %q solo puede tener minúsculas, números y guion bajo", ErrPlantillaInvalida, campo.Clave)
+		}
+		if claves[campo.Clave] {
+			return fmt.Errorf("%w: la clave %q está repetida", ErrPlantillaInvalida, campo.Clave)
+		}
+		claves[campo.Clave] = true
+
+		if !tiposCampoPlantilla[campo.Tipo] {
+			return fmt.Errorf("%w: el tipo %q del campo %s no existe", ErrPlantillaInvalida, campo.Tipo, campo.Clave)
+		}
+		if campo.Tipo == CampoOpcion && len(campo.Opciones) == 0 {
+			return fmt.Errorf("%w: el campo %s debe tener opciones", ErrPlantillaInvalida, campo.Clave)
+		}
+	}
+	return nil
+}
+
+// aplicarSolicitud copia a la plantilla los datos enviados por el coordinador
+func aplicarSolicitud(plantilla *models.PlantillaArea, req *models.PlantillaAreaRequest, actor *models.User) {
+	plantilla.AreaConsulta = strings.TrimSpace(req.AreaConsulta)
+	plantilla.PreguntasGuia = models.ListaTexto(req.PreguntasGuia)
+	plantilla.TextoPredeterminado = req.TextoPredeterminado
+	plantilla.Campos = models.CamposPlantilla(req.Campos)
+	plantilla.Activa = req.Activa == nil || *req.Activa
+	plantilla.ActualizadoPorID = &actor.ID
+}
+
+// existeOtraPlantilla evita dos plantillas para la misma área escrita con distintas mayúsculas
+func (s *PlantillaService) existeOtraPlantilla(tx *gorm.DB, area string, id uint) (bool, error) {
+	var total int64
+	err := tx.Model(&models.PlantillaArea{}).
+		Where("LOWER(area_consulta) = LOWER(?) AND id <> ?", strings.TrimSpace(area), id).
+		Count(&total).Error
+	return total > 0, err
+}
+
+// Crear registra la plantilla de un área
+func (s *PlantillaService) Crear(req *models.PlantillaAreaRequest, actor *models.User) (*models.PlantillaArea, error) {
+	if err := validarDefinicion(req); err != nil {
+		return nil, err
+	}
+
+	var plantilla models.PlantillaArea
+	aplicarSolicitud(&plantilla, req, actor)
+	err := ConActor(s.db, actor).Transaction(func(tx *gorm.DB) error {
+		existe, err := s.existeOtraPlantilla(tx, plantilla.AreaConsulta, 0)
+		if err != nil {
+			return err
+		}
+		if existe {
+			return ErrPlantillaDuplicada
+		}
+		return tx.Create(&plantilla).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &plantilla, nil
+}
+
+// Actualizar reemplaza la definición de una plantilla. Los controles ya creados conservan las
+// respuestas con las etiquetas que tenían
+func (s *PlantillaService) Actualizar(id uint, req *models.PlantillaAreaRequest, actor *models.User) (*models.PlantillaArea, error) {
+	if err := validarDefinicion(req); err != nil {
+		return nil, err
+	}
+
+	var plantilla models.PlantillaArea
+	err := ConActor(s.db, actor).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&plantilla, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPlantillaNoEncontrada
+			}
+			return err
+		}
+		existe, err := s.existeOtraPlantilla(tx, req.AreaConsulta, id)
+		if err != nil {
+			return err
+		}
+		if existe {
+			return ErrPlantillaDuplicada
+		}
+
+		aplicarSolicitud(&plantilla, req, actor)
+		plantilla.UpdatedAt = time.Now()
+		return tx.Save(&plantilla).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &plantilla, nil
+}
+
+// Eliminar borra la plantilla; el área vuelve a recibir casos sin campos adicionales
+func (s *PlantillaService) Eliminar(id uint, actor *models.User) error {
+	result := ConActor(s.db, actor).Delete(&models.PlantillaArea{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPlantillaNoEncontrada
+	}
+	return nil
+}
+
+// textoRespuesta convierte el valor recibido en JSON al texto que se guarda
+func textoRespuesta(valor interface{}) string {
+	switch v := valor.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case bool:
+		if v {
+			return "si"
+		}
+		return "no"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+}
+
+// validarRespuesta revisa un valor no vacío según el tipo del campo
+func validarRespuesta(campo models.CampoPlantilla, valor string) string {
+	switch campo.Tipo {
+	case CampoNumero:
+		if _, err := strconv.ParseFloat(valor, 64); err != nil {
+			return "debe ser un número"
+		}
+	case CampoFecha:
+		if _, err := time.Parse("2006-01-02", valor); err != nil {
+			return "debe ser una fecha con formato AAAA-MM-DD"
+		}
+	case CampoSiNo:
+		if valor != "si" && valor != "no" {
+			return "debe ser si o no"
+		}
+	case CampoOpcion:
+		for _, opcion := range campo.Opciones {
+			if valor == opcion {
+				return ""
+			}
+		}
+		return "debe ser una de: " + strings.Join(campo.Opciones, ", ")
+	}
+	return ""
+}
+
+// ValidarRespuestas compara las respuestas con la plantilla activa del área y las retorna en el
+// orden de la plantilla. Sin plantilla para el área no se admiten campos adicionales
+func (s *PlantillaService) ValidarRespuestas(area string, respuestas map[string]interface{}) (models.RespuestasPlantilla, error) {
+	plantilla, err := s.ObtenerPorArea(area)
+	if errors.Is(err, ErrPlantillaNoEncontrada) {
+		if len(respuestas) > 0 {
+			return nil, &ErrorRespuestasPlantilla{Area: area, Campos: map[string]string{
+				"respuestas_plantilla": "el área no tiene campos adicionales",
+			}}
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	errores := map[string]string{}
+	definidos := map[string]bool{}
+	var resultado models.RespuestasPlantilla
+	for _, campo := range plantilla.Campos {
+		definidos[campo.Clave] = true
+		valor := textoRespuesta(respuestas[campo.Clave])
+		if valor == "" {
+			if campo.Requerido {
+				errores[campo.Clave] = campo.Etiqueta + " es requerido"
+			}
+			continue
+		}
+		if problema := validarRespuesta(campo, valor); problema != "" {
+			errores[campo.Clave] = campo.Etiqueta + " " + problema
+			continue
+		}
+		resultado = append(resultado, models.RespuestaPlantilla{Clave: campo.Clave, Etiqueta: campo.Etiqueta, Valor: valor})
+	}
+	for clave := range respuestas {
+		if !definidos[clave] {
+			errores[clave] = "no está definido en la plantilla del área"
+		}
+	}
+
+	if len(errores) > 0 {
+		return nil, &ErrorRespuestasPlantilla{Area: plantilla.AreaConsulta, Campos: errores}
+	}
+	return resultado, nil
+}
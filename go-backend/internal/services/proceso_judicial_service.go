@@ -0,0 +1,270 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+)
+
+const (
+	EstadoProcesoActivo    = "activo"
+	EstadoProcesoTerminado = "terminado"
+
+	// Los radicados de la Rama Judicial tienen 23 dígitos
+	longitudRadicado = 23
+)
+
+// ResultadosConProcesoJudicial son los estados resultado que terminan en una demanda radicada
+var ResultadosConProcesoJudicial = map[string]bool{
+	"reparto":      true,
+	"auto_reparto": true,
+}
+
+var (
+	ErrProcesoNoEncontrado   = errors.New("el control no tiene proceso judicial registrado")
+	ErrProcesoNoAplica       = errors.New("solo los controles con resultado reparto o auto reparto tienen proceso judicial")
+	ErrProcesoInvalido       = errors.New("datos del proceso judicial inválidos")
+	ErrActuacionNoEncontrada = errors.New("actuación no encontrada")
+)
+
+// NormalizarRadicado deja solo los dígitos del número de radicado
+func NormalizarRadicado(radicado string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, radicado)
+}
+
+// ProcesoJudicialService gestiona la radicación y las actuaciones de los casos llevados a reparto
+type ProcesoJudicialService struct {
+	db              *gorm.DB
+	workflowService *WorkflowService
+}
+
+func NewProcesoJudicialService(db *gorm.DB, workflowService *WorkflowService) *ProcesoJudicialService {
+	return &ProcesoJudicialService{
+		db:              db,
+		workflowService: workflowService,
+	}
+}
+
+// parsearFecha convierte una fecha AAAA-MM-DD del request
+func parsearFecha(campo, valor string) (time.Time, error) {
+	fecha, err := time.Parse("2006-01-02", strings.TrimSpace(valor))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s inválida, use el formato AAAA-MM-DD", ErrProcesoInvalido, campo)
+	}
+	return fecha, nil
+}
+
+// Obtener retorna el proceso del control con sus actuaciones en orden cronológico
+func (s *ProcesoJudicialService) Obtener(controlID uint) (*models.ProcesoJudicial, error) {
+	var proceso models.ProcesoJudicial
+	err := s.db.Where("control_operativo_id = ?", controlID).
+		Preload("Actuaciones", func(db *gorm.DB) *gorm.DB {
+			return db.Order("fecha ASC, id ASC")
+		}).
+		Preload("Actuaciones.RegistradoPor", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, nombres, apellidos, email, role")
+		}).
+		First(&proceso).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProcesoNoEncontrado
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &proceso, nil
+}
+
+// Guardar registra o actualiza los datos de radicación del proceso del control
+func (s *ProcesoJudicialService) Guardar(control *models.ControlOperativo, req models.ProcesoJudicialRequest, actor *models.User) (*models.ProcesoJudicial, error) {
+	if control.EstadoResultado == nil || !ResultadosConProcesoJudicial[*control.EstadoResultado] {
+		return nil, ErrProcesoNoAplica
+	}
+
+	radicado := NormalizarRadicado(req.Radicado)
+	if len(radicado) != longitudRadicado {
+		return nil, fmt.Errorf("%w: el radicado debe tener %d dígitos", ErrProcesoInvalido, longitudRadicado)
+	}
+	fechaRadicacion, err := parsearFecha("fecha_radicacion", req.FechaRadicacion)
+	if err != nil {
+		return nil, err
+	}
+
+	estado := req.Estado
+	if estado == "" {
+		estado = EstadoProcesoActivo
+	}
+	if estado != EstadoProcesoActivo && estado != EstadoProcesoTerminado {
+		return nil, fmt.Errorf("%w: estado desconocido %s", ErrProcesoInvalido, estado)
+	}
+	var fechaTerminacion *time.Time
+	if estado == EstadoProcesoTerminado {
+		if req.FechaTerminacion == nil {
+			return nil, fmt.Errorf("%w: indique la fecha de terminación", ErrProcesoInvalido)
+		}
+		fecha, err := parsearFecha("fecha_terminacion", *req.FechaTerminacion)
+		if err != nil {
+			return nil, err
+		}
+		fechaTerminacion = &fecha
+	}
+
+	var proceso models.ProcesoJudicial
+	err = ConActor(s.db, actor).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("control_operativo_id = ?", control.ID).First(&proceso).Error
+		nuevo := errors.Is(err, gorm.ErrRecordNotFound)
+		if err != nil && !nuevo {
+			return err
+		}
+		radicadoAnterior := proceso.Radicado
+
+		proceso.ControlOperativoID = control.ID
+		proceso.Despacho = strings.TrimSpace(req.Despacho)
+		proceso.Radicado = radicado
+		proceso.FechaRadicacion = fechaRadicacion
+		proceso.TipoProceso = strings.TrimSpace(req.TipoProceso)
+		proceso.Estado = estado
+		proceso.FechaTerminacion = fechaTerminacion
+		proceso.Observaciones = strings.TrimSpace(req.Observaciones)
+		if nuevo {
+			proceso.CreadoPorID = actor.ID
+		}
+		if err := tx.Save(&proceso).Error; err != nil {
+			return fmt.Errorf("error guardando proceso judicial: %w", err)
+		}
+
+		if nuevo || radicadoAnterior != radicado {
+			return s.workflowService.RegistrarTransicion(tx, control.ID, control.EstadoFlujo, control.EstadoFlujo, actor,
+				fmt.Sprintf("Proceso judicial radicado %s en %s", radicado, proceso.Despacho))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Obtener(control.ID)
+}
+
+// AgregarActuacion registra una actuación procesal
+func (s *ProcesoJudicialService) AgregarActuacion(proceso *models.ProcesoJudicial, req models.ActuacionProcesalRequest, actor *models.User) (*models.ActuacionProcesal, error) {
+	fecha, err := parsearFecha("fecha", req.Fecha)
+	if err != nil {
+		return nil, err
+	}
+	if fecha.Before(proceso.FechaRadicacion) {
+		return nil, fmt.Errorf("%w: la actuación no puede ser anterior a la radicación", ErrProcesoInvalido)
+	}
+
+	actuacion := models.ActuacionProcesal{
+		ProcesoJudicialID: proceso.ID,
+		Fecha:             fecha,
+		Actuacion:         strings.TrimSpace(req.Actuacion),
+		Anotacion:         strings.TrimSpace(req.Anotacion),
+		RegistradoPorID:   actor.ID,
+	}
+	if err := ConActor(s.db, actor).Create(&actuacion).Error; err != nil {
+		return nil, fmt.Errorf("error guardando actuación: %w", err)
+	}
+	return &actuacion, nil
+}
+
+// EliminarActuacion borra una actuación; solo su autor o un coordinador pueden hacerlo
+func (s *ProcesoJudicialService) EliminarActuacion(proceso *models.ProcesoJudicial, actuacionID uint, actor *models.User) error {
+	var actuacion models.ActuacionProcesal
+	err := s.db.Where("id = ? AND proceso_judicial_id = ?", actuacionID, proceso.ID).First(&actuacion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrActuacionNoEncontrada
+	}
+	if err != nil {
+		return err
+	}
+	if actor.Role != "coordinador" && actuacion.RegistradoPorID != actor.ID {
+		return ErrActorNoAutorizado
+	}
+	return ConActor(s.db, actor).Delete(&actuacion).Error
+}
+
+// ProcesoActivoEstudiante resume un proceso judicial activo para la vista del coordinador
+type ProcesoActivoEstudiante struct {
+	ProcesoID          uint       `json:"proceso_id"`
+	ControlOperativoID uint       `json:"control_operativo_id"`
+	EstudianteID       uint       `json:"-"`
+	NombreConsultante  string     `json:"nombre_consultante"`
+	AreaConsulta       string     `json:"area_consulta"`
+	Despacho           string     `json:"despacho"`
+	Radicado           string     `json:"radicado"`
+	TipoProceso        string     `json:"tipo_proceso"`
+	FechaRadicacion    time.Time  `json:"fecha_radicacion"`
+	UltimaActuacion    *time.Time `json:"ultima_actuacion"`
+}
+
+// ProcesosPorEstudiante agrupa los procesos activos por estudiante responsable del caso
+type ProcesosPorEstudiante struct {
+	EstudianteID uint                      `json:"estudiante_id"`
+	Nombres      string                    `json:"nombres"`
+	Apellidos    string                    `json:"apellidos"`
+	Email        string                    `json:"email"`
+	Procesos     []ProcesoActivoEstudiante `json:"procesos"`
+}
+
+// ListarActivosPorEstudiante retorna los procesos activos de controles activos, agrupados por estudiante.
+// Si estudianteID es distinto de cero solo retorna los de ese estudiante
+func (s *ProcesoJudicialService) ListarActivosPorEstudiante(estudianteID uint) ([]ProcesosPorEstudiante, error) {
+	query := s.db.Table("procesos_judiciales p").
+		Select(`p.id AS proceso_id, p.control_operativo_id, co.created_by_id AS estudiante_id,
+			co.nombre_consultante, co.area_consulta, p.despacho, p.radicado, p.tipo_proceso, p.fecha_radicacion,
+			(SELECT MAX(a.fecha) FROM actuaciones_procesales a WHERE a.proceso_judicial_id = p.id) AS ultima_actuacion`).
+		Joins("JOIN control_operativos co ON co.id = p.control_operativo_id").
+		Where("p.estado = ? AND co.activo = true", EstadoProcesoActivo)
+	if estudianteID != 0 {
+		query = query.Where("co.created_by_id = ?", estudianteID)
+	}
+
+	var procesos []ProcesoActivoEstudiante
+	if err := query.Order("co.created_by_id ASC, p.fecha_radicacion ASC").Scan(&procesos).Error; err != nil {
+		return nil, err
+	}
+
+	var ids []uint
+	for _, proceso := range procesos {
+		if len(ids) == 0 || ids[len(ids)-1] != proceso.EstudianteID {
+			ids = append(ids, proceso.EstudianteID)
+		}
+	}
+	var estudiantes []models.User
+	if len(ids) > 0 {
+		if err := s.db.Select("id, nombres, apellidos, email").Where("id IN ?", ids).Find(&estudiantes).Error; err != nil {
+			return nil, err
+		}
+	}
+	porID := map[uint]models.User{}
+	for _, estudiante := range estudiantes {
+		porID[estudiante.ID] = estudiante
+	}
+
+	agrupados := []ProcesosPorEstudiante{}
+	for _, proceso := range procesos {
+		if len(agrupados) == 0 || agrupados[len(agrupados)-1].EstudianteID != proceso.EstudianteID {
+			estudiante := porID[proceso.EstudianteID]
+			agrupados = append(agrupados, ProcesosPorEstudiante{
+				EstudianteID: proceso.EstudianteID,
+				Nombres:      estudiante.Nombres,
+				Apellidos:    estudiante.Apellidos,
+				Email:        estudiante.Email,
+			})
+		}
+		grupo := &agrupados[len(agrupados)-1]
+		grupo.Procesos = append(grupo.Procesos, proceso)
+	}
+	return agrupados, nil
+}
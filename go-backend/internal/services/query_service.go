@@ -58,6 +58,9 @@ type FilterParams struct {
 	DateFrom              string `json:"date_from" form:"date_from"`
 	DateTo                string `json:"date_to" form:"date_to"`
 	Activo                *bool  `json:"activo" form:"activo"`
+	SLA                   string `json:"sla" form:"sla"`
+	IncluirCerrados       bool   `json:"incluir_cerrados" form:"incluir_cerrados"`
+	DiasAvisoSLA          int    `json:"-" form:"-"`
 }
 
 // GetControlesOperativos obtiene controles operativos con paginación y filtros optimizados
@@ -126,6 +129,17 @@ func (qs *QueryService) GetControlesOperativos(pagination PaginationParams, filt
 	return result, nil
 }
 
+// GetControlesOperativosIDs retorna los IDs de los controles que cumplen los filtros, hasta el límite indicado
+func (qs *QueryService) GetControlesOperativosIDs(filters FilterParams, limite int) ([]uint, error) {
+	query := qs.applyControlOperativoFilters(qs.db.Model(&models.ControlOperativo{}), filters, "")
+
+	var ids []uint
+	if err := query.Order("id ASC").Limit(limite).Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo controles: %v", err)
+	}
+	return ids, nil
+}
+
 // GetCalificaciones obtiene calificaciones con paginación optimizada
 func (qs *QueryService) GetCalificaciones(pagination PaginationParams, userRole string, userID uint) (*PaginationResult, error) {
 	// Validar parámetros
@@ -204,9 +218,11 @@ func (qs *QueryService) GetCalificaciones(pagination PaginationParams, userRole
 // applyControlOperativoFilters aplica filtros a la consulta de controles operativos
 func (qs *QueryService) applyControlOperativoFilters(query *gorm.DB, filters FilterParams, search string) *gorm.DB {
 	fmt.Printf("🔍 QUERY SERVICE - Aplicando filtros: %+v\n", filters)
-	// Filtro por estado de flujo
+	// Filtro por estado de flujo; los casos cerrados solo aparecen si se piden explícitamente
 	if filters.EstadoFlujo != "" {
 		query = query.Where("estado_flujo = ?", filters.EstadoFlujo)
+	} else if !filters.IncluirCerrados {
+		query = query.Where("estado_flujo <> ?", EstadoCerrado)
 	}
 
 	// Filtro por estado de resultado
@@ -240,18 +256,23 @@ func (qs *QueryService) applyControlOperativoFilters(query *gorm.DB, filters Fil
 		fmt.Printf("🔍 Aplicando filtro activo = true (por defecto)\n")
 	}
 
-	// Filtros de fecha
+	// Filtros de fecha sobre la fecha de atención; ambos extremos son inclusivos
 	if filters.DateFrom != "" {
 		if dateFrom, err := time.Parse("2006-01-02", filters.DateFrom); err == nil {
-			query = query.Where("created_at >= ?", dateFrom)
+			query = query.Where("fecha_atencion >= ?", dateFrom)
 		}
 	}
 	if filters.DateTo != "" {
 		if dateTo, err := time.Parse("2006-01-02", filters.DateTo); err == nil {
-			query = query.Where("created_at <= ?", dateTo.Add(24*time.Hour))
+			query = query.Where("fecha_atencion <= ?", dateTo)
 		}
 	}
 
+	// Filtro por vencimiento del concepto del asesor (vencido / por_vencer)
+	if filters.SLA != "" {
+		query = ConfiguracionSLA{DiasAviso: filters.DiasAvisoSLA}.AplicarFiltro(query, filters.SLA, time.Now())
+	}
+
 	// Búsqueda de texto
 	if search != "" {
 		searchTerm := "%" + strings.ToLower(search) + "%"
@@ -352,9 +373,9 @@ func (qs *QueryService) GetEstadisticasGenerales() (map[string]interface{}, erro
 	}
 
 	qs.db.Model(&models.ControlOperativo{}).
-		Select("to_char(created_at, 'YYYY-MM') as mes, COUNT(*) as total").
-		Where("activo = true AND created_at >= ?", time.Now().AddDate(0, -6, 0)).
-		Group("to_char(created_at, 'YYYY-MM')").
+		Select("to_char(fecha_atencion, 'YYYY-MM') as mes, COUNT(*) as total").
+		Where("activo = true AND fecha_atencion >= ?", time.Now().AddDate(0, -6, 0).Format("2006-01-02")).
+		Group("to_char(fecha_atencion, 'YYYY-MM')").
 		Order("mes DESC").
 		Scan(&monthlyStats)
 
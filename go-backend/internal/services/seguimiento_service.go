@@ -0,0 +1,250 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+	"consultorio-juridico/pkg/pdf"
+	"consultorio-juridico/pkg/terminos"
+)
+
+var (
+	ErrSeguimientoNoEncontrado = errors.New("seguimiento no encontrado")
+	ErrSeguimientoInvalido     = errors.New("seguimiento inválido")
+)
+
+// TiposSeguimiento son las actuaciones que se pueden registrar sobre un caso
+var TiposSeguimiento = map[string]string{
+	"llamada":   "Llamada telefónica",
+	"correo":    "Correo electrónico",
+	"carta":     "Carta o comunicación escrita",
+	"reunion":   "Reunión con el consultante",
+	"audiencia": "Audiencia",
+	"visita":    "Visita",
+	"otro":      "Otro",
+}
+
+// EsTipoSeguimientoValido indica si el tipo pertenece al catálogo
+func EsTipoSeguimientoValido(tipo string) bool {
+	_, ok := TiposSeguimiento[tipo]
+	return ok
+}
+
+// SeguimientoService gestiona las actuaciones registradas después de la atención inicial
+type SeguimientoService struct {
+	db *gorm.DB
+}
+
+func NewSeguimientoService(db *gorm.DB) *SeguimientoService {
+	return &SeguimientoService{db: db}
+}
+
+// directorioSeguimiento es donde se guardan los soportes de una actuación
+func directorioSeguimiento(controlID, seguimientoID uint) string {
+	return fmt.Sprintf("storage/uploads/control-operativo/%d/seguimientos/%d", controlID, seguimientoID)
+}
+
+// vencimientoSeguimiento calcula el vencimiento del término legal que inicia la actuación, si tiene uno
+func vencimientoSeguimiento(tipoTermino string, fecha time.Time) (*time.Time, error) {
+	if tipoTermino == "" {
+		return nil, nil
+	}
+	vencimiento, err := terminos.Calcular(tipoTermino, fecha)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v (%s)", ErrSeguimientoInvalido, err, tipoTermino)
+	}
+	return &vencimiento.FechaVencimiento, nil
+}
+
+// validarSeguimiento normaliza la solicitud y convierte la fecha
+func validarSeguimiento(req *models.SeguimientoRequest) (time.Time, error) {
+	req.Tipo = strings.TrimSpace(req.Tipo)
+	req.Descripcion = strings.TrimSpace(req.Descripcion)
+	req.TipoTermino = strings.TrimSpace(req.TipoTermino)
+	if !EsTipoSeguimientoValido(req.Tipo) {
+		return time.Time{}, fmt.Errorf("%w: tipo desconocido %s", ErrSeguimientoInvalido, req.Tipo)
+	}
+	if req.Descripcion == "" {
+		return time.Time{}, fmt.Errorf("%w: la descripción es requerida", ErrSeguimientoInvalido)
+	}
+	fecha, err := time.Parse("2006-01-02", strings.TrimSpace(req.Fecha))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: fecha inválida, use el formato AAAA-MM-DD", ErrSeguimientoInvalido)
+	}
+	return fecha, nil
+}
+
+// Listar retorna las actuaciones del control en orden cronológico
+func (s *SeguimientoService) Listar(controlID uint) ([]models.Seguimiento, error) {
+	var seguimientos []models.Seguimiento
+	err := s.db.Where("control_operativo_id = ?", controlID).
+		Preload("Autor", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, nombres, apellidos, email, role")
+		}).
+		Preload("Adjuntos").
+		Order("fecha ASC, id ASC").
+		Find(&seguimientos).Error
+	return seguimientos, err
+}
+
+// Obtener busca una actuación del control indicado
+func (s *SeguimientoService) Obtener(controlID, seguimientoID uint) (*models.Seguimiento, error) {
+	var seguimiento models.Seguimiento
+	err := s.db.Where("id = ? AND control_operativo_id = ?", seguimientoID, controlID).
+		Preload("Adjuntos").
+		First(&seguimiento).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSeguimientoNoEncontrado
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &seguimiento, nil
+}
+
+// Crear registra una actuación y asocia los soportes subidos previamente a /api/upload/temp
+func (s *SeguimientoService) Crear(controlID uint, req models.SeguimientoRequest, autor *models.User) (*models.Seguimiento, error) {
+	fecha, err := validarSeguimiento(&req)
+	if err != nil {
+		return nil, err
+	}
+	vencimiento, err := vencimientoSeguimiento(req.TipoTermino, fecha)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := VerificarArchivosTemporales(req.Adjuntos, autor.ID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSeguimientoInvalido, err)
+	}
+
+	seguimiento := models.Seguimiento{
+		ControlOperativoID: controlID,
+		Tipo:               req.Tipo,
+		Fecha:              fecha,
+		Descripcion:        req.Descripcion,
+		TipoTermino:        req.TipoTermino,
+		FechaVencimiento:   vencimiento,
+		AutorID:            autor.ID,
+	}
+	var movidos []ArchivoMovido
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&seguimiento).Error; err != nil {
+			return fmt.Errorf("error creando seguimiento: %w", err)
+		}
+		var err error
+		movidos, err = s.agregarAdjuntos(tx, &seguimiento, req.Adjuntos, autor.ID)
+		return err
+	})
+	if err != nil {
+		// Los soportes ya movidos vuelven al directorio temporal para poder asociarlos de nuevo
+		DevolverArchivosTemporales(movidos)
+		if seguimiento.ID != 0 {
+			os.RemoveAll(directorioSeguimiento(controlID, seguimiento.ID))
+		}
+		return nil, err
+	}
+	return &seguimiento, nil
+}
+
+// Actualizar modifica los datos de la actuación; los nuevos soportes, subidos por el editor, se suman
+// a los existentes
+func (s *SeguimientoService) Actualizar(seguimiento *models.Seguimiento, req models.SeguimientoRequest, editor *models.User) error {
+	fecha, err := validarSeguimiento(&req)
+	if err != nil {
+		return err
+	}
+	vencimiento, err := vencimientoSeguimiento(req.TipoTermino, fecha)
+	if err != nil {
+		return err
+	}
+
+	if err := VerificarArchivosTemporales(req.Adjuntos, editor.ID); err != nil {
+		return fmt.Errorf("%w: %v", ErrSeguimientoInvalido, err)
+	}
+
+	adjuntos := seguimiento.Adjuntos
+	var movidos []ArchivoMovido
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(seguimiento).Updates(map[string]interface{}{
+			"tipo":              req.Tipo,
+			"fecha":             fecha,
+			"descripcion":       req.Descripcion,
+			"tipo_termino":      req.TipoTermino,
+			"fecha_vencimiento": vencimiento,
+			"updated_at":        time.Now(),
+		}).Error; err != nil {
+			return fmt.Errorf("error actualizando seguimiento: %w", err)
+		}
+		var err error
+		movidos, err = s.agregarAdjuntos(tx, seguimiento, req.Adjuntos, editor.ID)
+		return err
+	})
+	if err != nil {
+		DevolverArchivosTemporales(movidos)
+		seguimiento.Adjuntos = adjuntos
+		return err
+	}
+	seguimiento.Tipo = req.Tipo
+	seguimiento.Fecha = fecha
+	seguimiento.Descripcion = req.Descripcion
+	seguimiento.TipoTermino = req.TipoTermino
+	seguimiento.FechaVencimiento = vencimiento
+	return nil
+}
+
+// Eliminar borra la actuación junto con sus soportes
+func (s *SeguimientoService) Eliminar(seguimiento *models.Seguimiento) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("seguimiento_id = ?", seguimiento.ID).Delete(&models.SeguimientoAdjunto{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(seguimiento).Error
+	})
+	if err != nil {
+		return fmt.Errorf("error eliminando seguimiento: %w", err)
+	}
+
+	if err := os.RemoveAll(directorioSeguimiento(seguimiento.ControlOperativoID, seguimiento.ID)); err != nil {
+		log.Printf("Warning: no se pudieron borrar los soportes del seguimiento #%d: %v", seguimiento.ID, err)
+	}
+	return nil
+}
+
+// agregarAdjuntos mueve los archivos temporales al directorio de la actuación y los registra en la
+// transacción indicada. Si algún archivo no se puede mover la actuación no se guarda; los archivos
+// retornados son los que alcanzaron a moverse, para devolverlos si la transacción falla
+func (s *SeguimientoService) agregarAdjuntos(tx *gorm.DB, seguimiento *models.Seguimiento, archivos []string, userID uint) ([]ArchivoMovido, error) {
+	directorio := directorioSeguimiento(seguimiento.ControlOperativoID, seguimiento.ID)
+	var movidos []ArchivoMovido
+	for _, nombre := range archivos {
+		movido, err := MoverArchivoTemporal(nombre, userID, directorio)
+		if err != nil {
+			if errors.Is(err, ErrArchivoTemporalNoEncontrado) {
+				return movidos, fmt.Errorf("%w: %v", ErrSeguimientoInvalido, err)
+			}
+			return movidos, err
+		}
+		movidos = append(movidos, *movido)
+
+		adjunto := models.SeguimientoAdjunto{
+			SeguimientoID:  seguimiento.ID,
+			NombreOriginal: movido.NombreOriginal,
+			NombreArchivo:  movido.Nombre,
+			TipoArchivo:    pdf.TipoMIMEAdjunto(movido.Nombre),
+			TamanoBytes:    movido.TamanoBytes,
+			RutaArchivo:    movido.Ruta,
+		}
+		if err := tx.Create(&adjunto).Error; err != nil {
+			return movidos, fmt.Errorf("error guardando adjunto del seguimiento: %w", err)
+		}
+		seguimiento.Adjuntos = append(seguimiento.Adjuntos, adjunto)
+	}
+	return movidos, nil
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+	"consultorio-juridico/pkg/terminos"
+)
+
+const (
+	ClaveSLADiasDefecto = "sla.dias_defecto"
+	ClaveSLADiasPorArea = "sla.dias_por_area"
+	ClaveSLADiasAviso   = "sla.dias_aviso"
+
+	SLADiasDefecto = 10
+	SLADiasAviso   = 2
+
+	// Filtros de ListarControles
+	FiltroSLAVencido   = "vencido"
+	FiltroSLAPorVencer = "por_vencer"
+)
+
+// ErrConfiguracionSLAInvalida indica que los plazos enviados no son válidos
+var ErrConfiguracionSLAInvalida = errors.New("configuración de SLA inválida")
+
+// ConfiguracionSLA define cuántos días hábiles tiene el profesor para emitir su concepto
+type ConfiguracionSLA struct {
+	DiasDefecto int            `json:"dias_defecto"`
+	DiasPorArea map[string]int `json:"dias_por_area"`
+	DiasAviso   int            `json:"dias_aviso"`
+}
+
+// SLAService calcula los vencimientos del concepto del asesor y envía recordatorios y escalamientos
+type SLAService struct {
+	db                   *gorm.DB
+	configuracionService *ConfiguracionService
+	notificationService  *NotificationService
+}
+
+func NewSLAService(db *gorm.DB, configuracionService *ConfiguracionService, notificationService *NotificationService) *SLAService {
+	return &SLAService{
+		db:                   db,
+		configuracionService: configuracionService,
+		notificationService:  notificationService,
+	}
+}
+
+// ObtenerConfiguracion retorna la configuración vigente con los valores por defecto aplicados
+func (s *SLAService) ObtenerConfiguracion() ConfiguracionSLA {
+	configuracion := ConfiguracionSLA{
+		DiasDefecto: s.configuracionService.ObtenerEntero(ClaveSLADiasDefecto, SLADiasDefecto),
+		DiasAviso:   s.configuracionService.ObtenerEntero(ClaveSLADiasAviso, SLADiasAviso),
+		DiasPorArea: map[string]int{},
+	}
+	if valor := s.configuracionService.Obtener(ClaveSLADiasPorArea, ""); valor != "" {
+		if err := json.Unmarshal([]byte(valor), &configuracion.DiasPorArea); err != nil {
+			log.Printf("Warning: configuración de SLA por área inválida: %v", err)
+		}
+	}
+	return configuracion
+}
+
+// GuardarConfiguracion valida y guarda la configuración de SLA
+func (s *SLAService) GuardarConfiguracion(configuracion ConfiguracionSLA, actor *models.User) error {
+	if configuracion.DiasDefecto <= 0 {
+		return fmt.Errorf("%w: dias_defecto debe ser mayor que cero", ErrConfiguracionSLAInvalida)
+	}
+	if configuracion.DiasAviso < 0 {
+		return fmt.Errorf("%w: dias_aviso no puede ser negativo", ErrConfiguracionSLAInvalida)
+	}
+	for area, dias := range configuracion.DiasPorArea {
+		if dias <= 0 {
+			return fmt.Errorf("%w: los días del área %s deben ser mayores que cero", ErrConfiguracionSLAInvalida, area)
+		}
+	}
+
+	porArea, err := json.Marshal(configuracion.DiasPorArea)
+	if err != nil {
+		return err
+	}
+
+	// Las tres claves se guardan juntas para no dejar una mezcla de plazos viejos y nuevos
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.configuracionService.EstablecerEn(tx, ClaveSLADiasDefecto, strconv.Itoa(configuracion.DiasDefecto), actor); err != nil {
+			return err
+		}
+		if err := s.configuracionService.EstablecerEn(tx, ClaveSLADiasAviso, strconv.Itoa(configuracion.DiasAviso), actor); err != nil {
+			return err
+		}
+		return s.configuracionService.EstablecerEn(tx, ClaveSLADiasPorArea, string(porArea), actor)
+	})
+}
+
+// DiasParaArea retorna el plazo del área o el plazo por defecto
+func (c ConfiguracionSLA) DiasParaArea(area string) int {
+	for nombre, dias := range c.DiasPorArea {
+		if strings.EqualFold(strings.TrimSpace(nombre), strings.TrimSpace(area)) {
+			return dias
+		}
+	}
+	return c.DiasDefecto
+}
+
+// CalcularFechaLimite retorna el vencimiento del concepto para un caso que entra a revisión en la fecha
+// indicada; el plazo se cuenta en días hábiles, sin fines de semana ni festivos
+func (s *SLAService) CalcularFechaLimite(area string, desde time.Time) time.Time {
+	return terminos.SumarDiasHabiles(desde, s.ObtenerConfiguracion().DiasParaArea(area))
+}
+
+// AsignarFechaLimite reinicia el plazo del control desde la fecha indicada
+func (s *SLAService) AsignarFechaLimite(tx *gorm.DB, control *models.ControlOperativo, desde time.Time) error {
+	fechaLimite := s.CalcularFechaLimite(control.AreaConsulta, desde)
+	if err := tx.Model(&models.ControlOperativo{}).
+		Where("id = ?", control.ID).
+		UpdateColumns(map[string]interface{}{
+			"fecha_limite_concepto": fechaLimite,
+			"sla_recordatorio_at":   nil,
+			"sla_escalado_at":       nil,
+		}).Error; err != nil {
+		return fmt.Errorf("error asignando fecha límite: %w", err)
+	}
+	control.FechaLimiteConcepto = &fechaLimite
+	control.SLARecordatorioAt = nil
+	control.SLAEscaladoAt = nil
+	return nil
+}
+
+// AplicarFiltro restringe la consulta a los controles vencidos o próximos a vencer
+func (c ConfiguracionSLA) AplicarFiltro(query *gorm.DB, filtro string, ahora time.Time) *gorm.DB {
+	switch filtro {
+	case FiltroSLAVencido:
+		return query.Where("estado_flujo = ? AND fecha_limite_concepto <= ?", EstadoPendienteProfesor, ahora)
+	case FiltroSLAPorVencer:
+		return query.Where("estado_flujo = ? AND fecha_limite_concepto > ? AND fecha_limite_concepto <= ?",
+			EstadoPendienteProfesor, ahora, terminos.SumarDiasHabiles(ahora, c.DiasAviso))
+	}
+	return query
+}
+
+// IniciarScheduler revisa periódicamente los vencimientos en segundo plano
+func (s *SLAService) IniciarScheduler(intervalo time.Duration) {
+	ticker := time.NewTicker(intervalo)
+	go func() {
+		s.RevisarVencimientos()
+		for range ticker.C {
+			s.RevisarVencimientos()
+		}
+	}()
+}
+
+// RevisarVencimientos asigna plazos faltantes, recuerda al profesor los que están por vencer
+// y escala a los coordinadores los vencidos
+func (s *SLAService) RevisarVencimientos() {
+	ahora := time.Now()
+	configuracion := s.ObtenerConfiguracion()
+
+	s.completarFechasLimite()
+
+	var porVencer []models.ControlOperativo
+	configuracion.AplicarFiltro(s.db.Model(&models.ControlOperativo{}), FiltroSLAPorVencer, ahora).
+		Where("activo = true AND sla_recordatorio_at IS NULL AND profesor_asignado_id IS NOT NULL").
+		Find(&porVencer)
+	for _, control := range porVencer {
+		if s.marcar(control.ID, "sla_recordatorio_at", ahora) {
+			s.notificationService.NotificarSLAPorVencer(control.ID, *control.ProfesorAsignadoID, *control.FechaLimiteConcepto)
+		}
+	}
+
+	var vencidos []models.ControlOperativo
+	configuracion.AplicarFiltro(s.db.Model(&models.ControlOperativo{}), FiltroSLAVencido, ahora).
+		Where("activo = true AND sla_escalado_at IS NULL").
+		Find(&vencidos)
+	for _, control := range vencidos {
+		if s.marcar(control.ID, "sla_escalado_at", ahora) {
+			s.notificationService.NotificarSLAVencido(control.ID, control.ProfesorAsignadoID, *control.FechaLimiteConcepto)
+		}
+	}
+
+	if len(porVencer) > 0 || len(vencidos) > 0 {
+		log.Printf("⏰ SLA: %d recordatorios y %d escalamientos procesados", len(porVencer), len(vencidos))
+	}
+}
+
+// completarFechasLimite asigna plazo a los controles en revisión que aún no lo tienen,
+// contando desde la última vez que entraron a pendiente_profesor
+func (s *SLAService) completarFechasLimite() {
+	var controles []models.ControlOperativo
+	if err := s.db.Where("activo = true AND estado_flujo = ? AND fecha_limite_concepto IS NULL", EstadoPendienteProfesor).
+		Find(&controles).Error; err != nil {
+		log.Printf("Warning: SLA no pudo consultar controles sin fecha límite: %v", err)
+		return
+	}
+
+	for i := range controles {
+		control := &controles[i]
+		desde := control.CreatedAt
+
+		var ultimaEntrada *time.Time
+		s.db.Model(&models.ControlOperativoTransicion{}).
+			Where("control_operativo_id = ? AND estado_nuevo = ? AND estado_anterior <> estado_nuevo", control.ID, EstadoPendienteProfesor).
+			Select("MAX(created_at)").
+			Scan(&ultimaEntrada)
+		if ultimaEntrada != nil {
+			desde = *ultimaEntrada
+		}
+
+		if err := s.AsignarFechaLimite(s.db, control, desde); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+}
+
+// marcar registra que el aviso ya se envió; retorna false si otra instancia se adelantó
+func (s *SLAService) marcar(controlID uint, campo string, ahora time.Time) bool {
+	result := s.db.Model(&models.ControlOperativo{}).
+		Where("id = ? AND "+campo+" IS NULL", controlID).
+		UpdateColumn(campo, ahora)
+	return result.Error == nil && result.RowsAffected == 1
+}
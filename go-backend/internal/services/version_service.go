@@ -0,0 +1,188 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+)
+
+var ErrVersionNoEncontrada = errors.New("versión no encontrada")
+
+// CambioCampo describe la diferencia de un campo entre dos revisiones
+type CambioCampo struct {
+	Campo         string      `json:"campo"`
+	ValorAnterior interface{} `json:"valor_anterior"`
+	ValorNuevo    interface{} `json:"valor_nuevo"`
+}
+
+// VersionService guarda las revisiones de un control operativo y calcula sus diferencias
+type VersionService struct {
+	db *gorm.DB
+}
+
+func NewVersionService(db *gorm.DB) *VersionService {
+	return &VersionService{db: db}
+}
+
+// DatosEditables retorna los campos que el estudiante puede modificar, con sus nombres JSON
+func DatosEditables(control *models.ControlOperativo) models.JSONMap {
+	return models.JSONMap{
+		"ciudad":               control.Ciudad,
+		"fecha_dia":            control.FechaDia,
+		"fecha_mes":            control.FechaMes,
+		"fecha_ano":            control.FechaAno,
+		"nombre_estudiante":    control.NombreEstudiante,
+		"area_consulta":        control.AreaConsulta,
+		"remitido_por":         control.RemitidoPor,
+		"correo_electronico":   control.CorreoElectronico,
+		"nombre_consultante":   control.NombreConsultante,
+		"edad":                 control.Edad,
+		"fecha_nacimiento_dia": control.FechaNacimientoDia,
+		"fecha_nacimiento_mes": control.FechaNacimientoMes,
+		"fecha_nacimiento_ano": control.FechaNacimientoAno,
+		"lugar_nacimiento":     control.LugarNacimiento,
+		"sexo":                 control.Sexo,
+		"tipo_documento":       control.TipoDocumento,
+		"numero_documento":     control.NumeroDocumento,
+		"lugar_expedicion":     control.LugarExpedicion,
+		"direccion":            control.Direccion,
+		"barrio":               control.Barrio,
+		"estrato":              control.Estrato,
+		"numero_telefonico":    control.NumeroTelefonico,
+		"numero_celular":       control.NumeroCelular,
+		"estado_civil":         control.EstadoCivil,
+		"escolaridad":          control.Escolaridad,
+		"profesion_oficio":     control.ProfesionOficio,
+		"descripcion_caso":     control.DescripcionCaso,
+		"concepto_estudiante":  control.ConceptoEstudiante,
+		"respuestas_plantilla": control.RespuestasPlantilla,
+	}
+}
+
+// ColumnasEditables traduce los datos editables a columnas de la tabla: la fecha de atención y la
+// de nacimiento se reciben por partes pero se guardan como fechas
+func ColumnasEditables(control *models.ControlOperativo) map[string]interface{} {
+	columnas := map[string]interface{}(DatosEditables(control))
+	for _, parte := range []string{"fecha_dia", "fecha_mes", "fecha_ano",
+		"fecha_nacimiento_dia", "fecha_nacimiento_mes", "fecha_nacimiento_ano"} {
+		delete(columnas, parte)
+	}
+	columnas["fecha_atencion"] = models.FechaDesdePartes(control.FechaAno, control.FechaMes, control.FechaDia)
+	columnas["fecha_nacimiento"] = models.FechaDesdePartes(control.FechaNacimientoAno, control.FechaNacimientoMes, control.FechaNacimientoDia)
+	return columnas
+}
+
+// Diferencias compara dos revisiones y retorna los campos modificados ordenados por nombre
+func Diferencias(antes, despues models.JSONMap) []CambioCampo {
+	antes, despues = normalizarJSON(antes), normalizarJSON(despues)
+
+	campos := map[string]bool{}
+	for campo := range antes {
+		campos[campo] = true
+	}
+	for campo := range despues {
+		campos[campo] = true
+	}
+
+	var nombres []string
+	for campo := range campos {
+		nombres = append(nombres, campo)
+	}
+	sort.Strings(nombres)
+
+	cambios := []CambioCampo{}
+	for _, campo := range nombres {
+		anterior, nuevo := antes[campo], despues[campo]
+		if fmt.Sprint(anterior) == fmt.Sprint(nuevo) {
+			continue
+		}
+		cambios = append(cambios, CambioCampo{Campo: campo, ValorAnterior: anterior, ValorNuevo: nuevo})
+	}
+	return cambios
+}
+
+// normalizarJSON lleva los valores al mismo tipo que tendrían al leerse desde jsonb
+func normalizarJSON(datos models.JSONMap) models.JSONMap {
+	if datos == nil {
+		return models.JSONMap{}
+	}
+	bytes, err := json.Marshal(datos)
+	if err != nil {
+		return datos
+	}
+	normalizado := models.JSONMap{}
+	if err := json.Unmarshal(bytes, &normalizado); err != nil {
+		return datos
+	}
+	return normalizado
+}
+
+// GuardarVersion conserva los datos actuales del control como la siguiente revisión
+func (s *VersionService) GuardarVersion(tx *gorm.DB, control *models.ControlOperativo, editor *models.User) (*models.ControlOperativoVersion, error) {
+	var ultima int
+	if err := tx.Model(&models.ControlOperativoVersion{}).
+		Where("control_operativo_id = ?", control.ID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&ultima).Error; err != nil {
+		return nil, fmt.Errorf("error consultando versiones: %w", err)
+	}
+
+	version := models.ControlOperativoVersion{
+		ControlOperativoID: control.ID,
+		Version:            ultima + 1,
+		Datos:              DatosEditables(control),
+		EditadoPorID:       editor.ID,
+	}
+	if err := tx.Create(&version).Error; err != nil {
+		return nil, fmt.Errorf("error guardando versión: %w", err)
+	}
+	return &version, nil
+}
+
+// ListarVersiones retorna las revisiones anteriores de un control en orden ascendente
+func (s *VersionService) ListarVersiones(controlID uint) ([]models.ControlOperativoVersion, error) {
+	var versiones []models.ControlOperativoVersion
+	err := s.db.Where("control_operativo_id = ?", controlID).
+		Preload("EditadoPor", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, nombres, apellidos, email, role")
+		}).
+		Order("version ASC").
+		Find(&versiones).Error
+	return versiones, err
+}
+
+// DatosVersion retorna los datos de una revisión. Las revisiones guardadas se numeran desde 1
+// y la siguiente a la última corresponde a los datos actuales del control
+func (s *VersionService) DatosVersion(control *models.ControlOperativo, version int) (models.JSONMap, error) {
+	var guardada models.ControlOperativoVersion
+	err := s.db.Where("control_operativo_id = ? AND version = ?", control.ID, version).First(&guardada).Error
+	if err == nil {
+		return guardada.Datos, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	actual, err := s.VersionActual(control.ID)
+	if err != nil {
+		return nil, err
+	}
+	if version == actual {
+		return DatosEditables(control), nil
+	}
+	return nil, ErrVersionNoEncontrada
+}
+
+// VersionActual retorna el número de revisión de los datos actuales del control
+func (s *VersionService) VersionActual(controlID uint) (int, error) {
+	var total int64
+	err := s.db.Model(&models.ControlOperativoVersion{}).
+		Where("control_operativo_id = ?", controlID).
+		Count(&total).Error
+	return int(total) + 1, err
+}
@@ -0,0 +1,378 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"consultorio-juridico/internal/models"
+)
+
+// Estados del flujo de un control operativo
+const (
+	EstadoPendienteProfesor    = "pendiente_profesor"
+	EstadoRequiereCorrecciones = "requiere_correcciones"
+	EstadoCompleto             = "completo"
+	EstadoConResultado         = "con_resultado"
+	EstadoCerrado              = "cerrado"
+)
+
+// EstadosResultado son los resultados que puede tomar un control con concepto del asesor
+var EstadosResultado = map[string]string{
+	"asesoria_consulta":      "Asesoría/Consulta",
+	"auto_reparto":           "Auto Reparto",
+	"reparto":                "Reparto",
+	"solicitud_conciliacion": "Solicitud de Conciliación",
+}
+
+// EsEstadoResultadoValido indica si el estado resultado pertenece al catálogo
+func EsEstadoResultadoValido(estado string) bool {
+	_, ok := EstadosResultado[estado]
+	return ok
+}
+
+var (
+	ErrTransicionNoPermitida = errors.New("transición de estado no permitida")
+	ErrActorNoAutorizado     = errors.New("el usuario no puede modificar este control")
+)
+
+// reglaTransicion define un cambio de estado legal y los roles que pueden ejecutarlo
+type reglaTransicion struct {
+	Desde string
+	Hacia string
+	Roles []string
+}
+
+// transicionesPermitidas es la única fuente de verdad del flujo del control operativo
+var transicionesPermitidas = []reglaTransicion{
+	// El profesor asignado emite su concepto (y puede corregirlo mientras no haya resultado)
+	{Desde: EstadoPendienteProfesor, Hacia: EstadoCompleto, Roles: []string{"profesor"}},
+	{Desde: EstadoCompleto, Hacia: EstadoCompleto, Roles: []string{"profesor"}},
+	// El profesor devuelve el control para que el estudiante corrija su concepto, y este lo reenvía
+	{Desde: EstadoPendienteProfesor, Hacia: EstadoRequiereCorrecciones, Roles: []string{"profesor"}},
+	{Desde: EstadoRequiereCorrecciones, Hacia: EstadoPendienteProfesor, Roles: []string{"estudiante"}},
+	// El estudiante creador o el coordinador establecen el resultado
+	{Desde: EstadoCompleto, Hacia: EstadoConResultado, Roles: []string{"estudiante", "coordinador"}},
+	// Solo el coordinador puede cambiar un resultado ya establecido
+	{Desde: EstadoConResultado, Hacia: EstadoConResultado, Roles: []string{"coordinador"}},
+	// El profesor asignado o el coordinador cierran el caso desde cualquier estado abierto;
+	// solo el coordinador lo reabre, y vuelve al estado que tenía al cerrarse
+	{Desde: EstadoPendienteProfesor, Hacia: EstadoCerrado, Roles: []string{"profesor", "coordinador"}},
+	{Desde: EstadoRequiereCorrecciones, Hacia: EstadoCerrado, Roles: []string{"profesor", "coordinador"}},
+	{Desde: EstadoCompleto, Hacia: EstadoCerrado, Roles: []string{"profesor", "coordinador"}},
+	{Desde: EstadoConResultado, Hacia: EstadoCerrado, Roles: []string{"profesor", "coordinador"}},
+	{Desde: EstadoCerrado, Hacia: EstadoPendienteProfesor, Roles: []string{"coordinador"}},
+	{Desde: EstadoCerrado, Hacia: EstadoRequiereCorrecciones, Roles: []string{"coordinador"}},
+	{Desde: EstadoCerrado, Hacia: EstadoCompleto, Roles: []string{"coordinador"}},
+	{Desde: EstadoCerrado, Hacia: EstadoConResultado, Roles: []string{"coordinador"}},
+}
+
+// MotivosCierre es el catálogo de razones por las que se cierra un caso
+var MotivosCierre = map[string]string{
+	"resuelto":      "Caso resuelto",
+	"desistimiento": "Desistimiento del consultante",
+	"sin_contacto":  "Sin contacto con el consultante",
+	"remitido":      "Remitido a otra entidad",
+}
+
+// EsMotivoCierreValido indica si el motivo pertenece al catálogo
+func EsMotivoCierreValido(motivo string) bool {
+	_, ok := MotivosCierre[motivo]
+	return ok
+}
+
+// PermiteEdicion indica si el creador aún puede modificar los datos del control en ese estado
+func PermiteEdicion(estado string) bool {
+	return estado == EstadoPendienteProfesor || estado == EstadoRequiereCorrecciones
+}
+
+// WorkflowService centraliza las transiciones de EstadoFlujo y su historial
+type WorkflowService struct {
+	db *gorm.DB
+}
+
+func NewWorkflowService(db *gorm.DB) *WorkflowService {
+	return &WorkflowService{db: db}
+}
+
+// PuedeTransicionar indica si el rol puede mover un control de un estado a otro
+func (s *WorkflowService) PuedeTransicionar(desde, hacia, rol string) bool {
+	for _, regla := range transicionesPermitidas {
+		if regla.Desde != desde || regla.Hacia != hacia {
+			continue
+		}
+		for _, r := range regla.Roles {
+			if r == rol {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TransicionesDisponibles lista los estados a los que el rol puede llevar el control
+func (s *WorkflowService) TransicionesDisponibles(desde, rol string) []string {
+	var estados []string
+	for _, regla := range transicionesPermitidas {
+		if regla.Desde == desde && s.PuedeTransicionar(desde, regla.Hacia, rol) {
+			estados = append(estados, regla.Hacia)
+		}
+	}
+	return estados
+}
+
+// validarActor verifica que el usuario tenga relación con el control según su rol
+func (s *WorkflowService) validarActor(control *models.ControlOperativo, actor *models.User) error {
+	switch actor.Role {
+	case "estudiante":
+		if control.CreatedByID != actor.ID {
+			return ErrActorNoAutorizado
+		}
+	case "profesor":
+		if control.ProfesorAsignadoID == nil || *control.ProfesorAsignadoID != actor.ID {
+			return ErrActorNoAutorizado
+		}
+	case "coordinador":
+		// Los coordinadores pueden actuar sobre cualquier control
+	default:
+		return ErrActorNoAutorizado
+	}
+	return nil
+}
+
+// Transicionar cambia el estado de flujo del control dentro de la transacción indicada,
+// aplica los cambios adicionales y registra la transición en el historial
+func (s *WorkflowService) Transicionar(tx *gorm.DB, control *models.ControlOperativo, hacia string, actor *models.User, comentario string, cambios map[string]interface{}) error {
+	desde := control.EstadoFlujo
+
+	if !control.Activo {
+		return ErrTransicionNoPermitida
+	}
+	if !s.PuedeTransicionar(desde, hacia, actor.Role) {
+		return fmt.Errorf("%w: %s → %s para rol %s", ErrTransicionNoPermitida, desde, hacia, actor.Role)
+	}
+	if err := s.validarActor(control, actor); err != nil {
+		return err
+	}
+	// Un caso con conflicto de interés sin revisar solo puede cerrarse
+	if control.ConflictoPendiente && hacia != EstadoCerrado {
+		return ErrConflictoPendiente
+	}
+
+	if cambios == nil {
+		cambios = map[string]interface{}{}
+	}
+	cambios["estado_flujo"] = hacia
+	cambios["updated_at"] = time.Now()
+
+	// La condición sobre el estado actual evita que dos peticiones concurrentes
+	// apliquen transiciones sobre un estado que ya cambió
+	result := tx.Model(&models.ControlOperativo{}).
+		Where("id = ? AND estado_flujo = ?", control.ID, desde).
+		Updates(cambios)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: el control cambió de estado", ErrTransicionNoPermitida)
+	}
+
+	if err := s.RegistrarTransicion(tx, control.ID, desde, hacia, actor, comentario); err != nil {
+		return err
+	}
+
+	return tx.First(control, control.ID).Error
+}
+
+// RegistrarTransicion guarda una entrada en el historial del control
+func (s *WorkflowService) RegistrarTransicion(tx *gorm.DB, controlID uint, desde, hacia string, actor *models.User, comentario string) error {
+	transicion := models.ControlOperativoTransicion{
+		ControlOperativoID: controlID,
+		EstadoAnterior:     desde,
+		EstadoNuevo:        hacia,
+		ActorID:            actor.ID,
+		ActorRol:           actor.Role,
+		Comentario:         comentario,
+	}
+
+	if err := tx.Create(&transicion).Error; err != nil {
+		return fmt.Errorf("error registrando transición: %w", err)
+	}
+	return nil
+}
+
+// DevolverAEstudiante envía el control a corrección y abre una nueva ronda con las observaciones del profesor
+func (s *WorkflowService) DevolverAEstudiante(tx *gorm.DB, control *models.ControlOperativo, actor *models.User, observaciones string) (*models.RondaCorreccion, error) {
+	if err := s.Transicionar(tx, control, EstadoRequiereCorrecciones, actor, observaciones, nil); err != nil {
+		return nil, err
+	}
+
+	var ultima int
+	if err := tx.Model(&models.RondaCorreccion{}).
+		Where("control_operativo_id = ?", control.ID).
+		Select("COALESCE(MAX(ronda), 0)").
+		Scan(&ultima).Error; err != nil {
+		return nil, fmt.Errorf("error consultando rondas de corrección: %w", err)
+	}
+
+	ronda := models.RondaCorreccion{
+		ControlOperativoID: control.ID,
+		Ronda:              ultima + 1,
+		Observaciones:      observaciones,
+		ProfesorID:         actor.ID,
+		FechaDevolucion:    time.Now(),
+	}
+	if err := tx.Create(&ronda).Error; err != nil {
+		return nil, fmt.Errorf("error registrando ronda de corrección: %w", err)
+	}
+	return &ronda, nil
+}
+
+// ReenviarAProfesor devuelve el control corregido al profesor y cierra la ronda abierta
+func (s *WorkflowService) ReenviarAProfesor(tx *gorm.DB, control *models.ControlOperativo, actor *models.User, respuesta string) (*models.RondaCorreccion, error) {
+	comentario := "Control reenviado con correcciones"
+	if respuesta != "" {
+		comentario += ": " + respuesta
+	}
+	if err := s.Transicionar(tx, control, EstadoPendienteProfesor, actor, comentario, nil); err != nil {
+		return nil, err
+	}
+
+	var ronda models.RondaCorreccion
+	if err := tx.Where("control_operativo_id = ? AND fecha_reenvio IS NULL", control.ID).
+		Order("ronda DESC").
+		First(&ronda).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo ronda de corrección abierta: %w", err)
+	}
+
+	ahora := time.Now()
+	if err := tx.Model(&ronda).Updates(map[string]interface{}{
+		"respuesta_estudiante": respuesta,
+		"fecha_reenvio":        ahora,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("error cerrando ronda de corrección: %w", err)
+	}
+	ronda.RespuestaEstudiante = respuesta
+	ronda.FechaReenvio = &ahora
+	return &ronda, nil
+}
+
+// ObtenerRondasCorreccion retorna las rondas de corrección de un control en orden
+func (s *WorkflowService) ObtenerRondasCorreccion(controlID uint) ([]models.RondaCorreccion, error) {
+	var rondas []models.RondaCorreccion
+	err := s.db.Where("control_operativo_id = ?", controlID).
+		Preload("Profesor", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, nombres, apellidos, email, role")
+		}).
+		Order("ronda ASC").
+		Find(&rondas).Error
+	return rondas, err
+}
+
+// Cerrar archiva el caso con el motivo, la fecha y la nota de cierre
+func (s *WorkflowService) Cerrar(tx *gorm.DB, control *models.ControlOperativo, actor *models.User, motivo, nota string, fecha time.Time) error {
+	if !EsMotivoCierreValido(motivo) {
+		return fmt.Errorf("%w: motivo de cierre desconocido", ErrTransicionNoPermitida)
+	}
+
+	comentario := "Caso cerrado: " + MotivosCierre[motivo]
+	if nota != "" {
+		comentario += ". " + nota
+	}
+	return s.Transicionar(tx, control, EstadoCerrado, actor, comentario, map[string]interface{}{
+		"motivo_cierre": motivo,
+		"fecha_cierre":  fecha,
+		"nota_cierre":   nota,
+	})
+}
+
+// Reabrir devuelve un caso cerrado al estado que tenía antes del cierre
+func (s *WorkflowService) Reabrir(tx *gorm.DB, control *models.ControlOperativo, actor *models.User, motivo string) error {
+	if control.EstadoFlujo != EstadoCerrado {
+		return fmt.Errorf("%w: el control no está cerrado", ErrTransicionNoPermitida)
+	}
+
+	var cierre models.ControlOperativoTransicion
+	if err := tx.Where("control_operativo_id = ? AND estado_nuevo = ? AND estado_anterior <> ?", control.ID, EstadoCerrado, EstadoCerrado).
+		Order("created_at DESC, id DESC").
+		First(&cierre).Error; err != nil {
+		return fmt.Errorf("error obteniendo el cierre del control: %w", err)
+	}
+
+	comentario := "Caso reabierto"
+	if motivo != "" {
+		comentario += ": " + motivo
+	}
+	return s.Transicionar(tx, control, cierre.EstadoAnterior, actor, comentario, map[string]interface{}{
+		"motivo_cierre": nil,
+		"fecha_cierre":  nil,
+		"nota_cierre":   "",
+	})
+}
+
+// Desactivar oculta el control de todos los listados sin borrarlo
+func (s *WorkflowService) Desactivar(tx *gorm.DB, control *models.ControlOperativo, actor *models.User, motivo string) error {
+	ahora := time.Now()
+	result := tx.Model(&models.ControlOperativo{}).
+		Where("id = ? AND activo = true", control.ID).
+		Updates(map[string]interface{}{
+			"activo":               false,
+			"motivo_desactivacion": motivo,
+			"desactivado_at":       ahora,
+			"desactivado_por_id":   actor.ID,
+			"updated_at":           ahora,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: el control ya está desactivado", ErrTransicionNoPermitida)
+	}
+
+	if err := s.RegistrarTransicion(tx, control.ID, control.EstadoFlujo, control.EstadoFlujo, actor, "Control desactivado: "+motivo); err != nil {
+		return err
+	}
+	return tx.First(control, control.ID).Error
+}
+
+// Restaurar vuelve a activar un control desactivado conservando su estado de flujo
+func (s *WorkflowService) Restaurar(tx *gorm.DB, control *models.ControlOperativo, actor *models.User, motivo string) error {
+	result := tx.Model(&models.ControlOperativo{}).
+		Where("id = ? AND activo = false", control.ID).
+		Updates(map[string]interface{}{
+			"activo":               true,
+			"motivo_desactivacion": "",
+			"desactivado_at":       nil,
+			"desactivado_por_id":   nil,
+			"updated_at":           time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: el control no está desactivado", ErrTransicionNoPermitida)
+	}
+
+	comentario := "Control restaurado"
+	if motivo != "" {
+		comentario += ": " + motivo
+	}
+	if err := s.RegistrarTransicion(tx, control.ID, control.EstadoFlujo, control.EstadoFlujo, actor, comentario); err != nil {
+		return err
+	}
+	return tx.First(control, control.ID).Error
+}
+
+// ObtenerHistorial retorna las transiciones de un control en orden cronológico
+func (s *WorkflowService) ObtenerHistorial(controlID uint) ([]models.ControlOperativoTransicion, error) {
+	var historial []models.ControlOperativoTransicion
+	err := s.db.Where("control_operativo_id = ?", controlID).
+		Preload("Actor", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, nombres, apellidos, email, role")
+		}).
+		Order("created_at ASC, id ASC").
+		Find(&historial).Error
+	return historial, err
+}
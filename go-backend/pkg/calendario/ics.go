@@ -0,0 +1,94 @@
+package calendario
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+const formatoFechaICS = "20060102T150405Z"
+
+// Evento es una entrada VEVENT del calendario
+type Evento struct {
+	UID         string
+	Inicio      time.Time
+	Fin         time.Time
+	Resumen     string
+	Descripcion string
+	Lugar       string
+	Cancelado   bool
+	Actualizado time.Time
+}
+
+// GenerarICS arma un calendario iCalendar (RFC 5545) con los eventos indicados
+func GenerarICS(nombre string, eventos []Evento) []byte {
+	var buf bytes.Buffer
+	escribir := func(linea string) {
+		buf.WriteString(plegarLinea(linea))
+		buf.WriteString("\r\n")
+	}
+
+	escribir("BEGIN:VCALENDAR")
+	escribir("VERSION:2.0")
+	escribir("PRODID:-//Consultorio Juridico UCMC//Citas//ES")
+	escribir("CALSCALE:GREGORIAN")
+	escribir("METHOD:PUBLISH")
+	escribir("X-WR-CALNAME:" + escaparTexto(nombre))
+
+	for _, evento := range eventos {
+		escribir("BEGIN:VEVENT")
+		escribir("UID:" + evento.UID)
+		escribir("DTSTAMP:" + evento.Actualizado.UTC().Format(formatoFechaICS))
+		escribir("DTSTART:" + evento.Inicio.UTC().Format(formatoFechaICS))
+		escribir("DTEND:" + evento.Fin.UTC().Format(formatoFechaICS))
+		escribir("SUMMARY:" + escaparTexto(evento.Resumen))
+		if evento.Descripcion != "" {
+			escribir("DESCRIPTION:" + escaparTexto(evento.Descripcion))
+		}
+		if evento.Lugar != "" {
+			escribir("LOCATION:" + escaparTexto(evento.Lugar))
+		}
+		if evento.Cancelado {
+			escribir("STATUS:CANCELLED")
+		} else {
+			escribir("STATUS:CONFIRMED")
+		}
+		escribir("END:VEVENT")
+	}
+
+	escribir("END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// escaparTexto escapa los caracteres especiales de los valores TEXT
+func escaparTexto(texto string) string {
+	reemplazos := strings.NewReplacer(
+		"\\", "\\\\",
+		";", "\\;",
+		",", "\\,",
+		"\r\n", "\\n",
+		"\n", "\\n",
+	)
+	return reemplazos.Replace(texto)
+}
+
+// plegarLinea divide las líneas de más de 75 octetos sin partir caracteres UTF-8
+func plegarLinea(linea string) string {
+	const maximo = 75
+	if len(linea) <= maximo {
+		return linea
+	}
+
+	var buf strings.Builder
+	octetos := 0
+	for _, r := range linea {
+		tamano := len(string(r))
+		if octetos+tamano > maximo {
+			buf.WriteString("\r\n ")
+			octetos = 1 // el espacio de continuación cuenta dentro de la línea
+		}
+		buf.WriteRune(r)
+		octetos += tamano
+	}
+	return buf.String()
+}
@@ -0,0 +1,210 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"consultorio-juridico/internal/models"
+)
+
+// Títulos del documento según el resultado de la audiencia
+var titulosConciliacion = map[string]string{
+	"acuerdo_total":   "ACTA DE CONCILIACIÓN - ACUERDO TOTAL",
+	"acuerdo_parcial": "ACTA DE CONCILIACIÓN - ACUERDO PARCIAL",
+	"no_acuerdo":      "CONSTANCIA DE NO ACUERDO",
+	"inasistencia":    "CONSTANCIA DE INASISTENCIA",
+}
+
+// GenerarActaConciliacion - Acta (si hubo acuerdo) o constancia de la audiencia de conciliación,
+// con el mismo formato carta, encabezado y footer del control operativo
+func (g *PDFGenerator) GenerarActaConciliacion(control *models.ControlOperativo, conciliacion *models.Conciliacion) ([]byte, error) {
+	if conciliacion.Resultado == nil {
+		return nil, fmt.Errorf("la conciliación del control #%d no tiene resultado", control.ID)
+	}
+	resultado := *conciliacion.Resultado
+	titulo, ok := titulosConciliacion[resultado]
+	if !ok {
+		return nil, fmt.Errorf("resultado de conciliación desconocido: %s", resultado)
+	}
+	hayAcuerdo := strings.HasPrefix(resultado, "acuerdo_")
+
+	pdf := g.nuevoDocumentoCarta()
+	// A diferencia del formulario, el acta crece con el texto de los acuerdos
+	pdf.SetAutoPageBreak(true, MARGEN_INFERIOR+15)
+	pdf.SetFooterFunc(func() { g.generarFooterFinal(pdf) })
+	pdf.AddPage()
+	g.generarEncabezadoConTitulo(pdf, titulo)
+
+	// I. DATOS DE LA AUDIENCIA
+	g.generarHeaderSeccion(pdf, "I. DATOS DE LA AUDIENCIA")
+	fecha, hora := "", ""
+	if conciliacion.FechaAudiencia != nil {
+		fecha = conciliacion.FechaAudiencia.Local().Format("02/01/2006")
+		hora = conciliacion.FechaAudiencia.Local().Format("15:04")
+	}
+	mitad := ANCHO_UTIL / 2
+	g.filaEtiquetaValor(pdf, "Control operativo No.:", fmt.Sprintf("%d", control.ID), mitad, false)
+	g.filaEtiquetaValor(pdf, "Área:", control.AreaConsulta, mitad, true)
+	g.filaEtiquetaValor(pdf, "Fecha de la audiencia:", fecha, mitad, false)
+	g.filaEtiquetaValor(pdf, "Hora:", hora, mitad, true)
+	g.filaEtiquetaValor(pdf, "Lugar:", conciliacion.LugarAudiencia, ANCHO_UTIL, true)
+	g.filaEtiquetaValor(pdf, "Conciliador:", conciliacion.Conciliador, ANCHO_UTIL, true)
+	pdf.Ln(4)
+
+	// II. PARTES
+	g.generarHeaderSeccion(pdf, "II. PARTES")
+	anchos := []float64{30, 70, 50, ANCHO_UTIL - 150}
+	pdf.SetFont("Arial", "B", FUENTE_PEQUENA_9PT)
+	for i, columna := range []string{"CALIDAD", "NOMBRE", "DOCUMENTO", "ASISTIÓ"} {
+		salto := 0
+		if i == len(anchos)-1 {
+			salto = 1
+		}
+		pdf.CellFormat(anchos[i], ALTURA_CELDA_20PX, ProcesarTextoUTF8(columna), "1", salto, "C", false, 0, "")
+	}
+	pdf.SetFont("Arial", "", FUENTE_PEQUENA_9PT)
+	for _, parte := range conciliacion.Partes {
+		asistio := "-"
+		if parte.Asistio != nil {
+			asistio = "No"
+			if *parte.Asistio {
+				asistio = "Sí"
+			}
+		}
+		documento := strings.TrimSpace(parte.TipoDocumento + " " + parte.NumeroDocumento)
+		pdf.CellFormat(anchos[0], ALTURA_CELDA_20PX, ProcesarTextoUTF8(calidadParte(parte.Rol)), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(anchos[1], ALTURA_CELDA_20PX, ProcesarTextoUTF8(parte.Nombre), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(anchos[2], ALTURA_CELDA_20PX, ProcesarTextoUTF8(documento), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(anchos[3], ALTURA_CELDA_20PX, ProcesarTextoUTF8(asistio), "1", 1, "C", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	// III. ACUERDOS o CONSTANCIA
+	if hayAcuerdo {
+		g.generarHeaderSeccion(pdf, "III. ACUERDOS")
+		g.parrafo(pdf, conciliacion.Acuerdos)
+	} else {
+		g.generarHeaderSeccion(pdf, "III. CONSTANCIA")
+		g.parrafo(pdf, textoConstancia(resultado, fecha, conciliacion.Partes))
+	}
+	pdf.Ln(4)
+
+	// IV. OBSERVACIONES
+	if strings.TrimSpace(conciliacion.Observaciones) != "" {
+		g.generarHeaderSeccion(pdf, "IV. OBSERVACIONES")
+		g.parrafo(pdf, conciliacion.Observaciones)
+		pdf.Ln(4)
+	}
+
+	g.generarFirmasConciliacion(pdf, conciliacion)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("error generando acta de conciliación: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// generarHeaderSeccion - Encabezado gris de sección, igual al del formulario
+func (g *PDFGenerator) generarHeaderSeccion(pdf *gofpdf.Fpdf, titulo string) {
+	pdf.SetFillColor(239, 239, 239)
+	pdf.SetFont("Arial", "B", FUENTE_NORMAL_10PT)
+	pdf.CellFormat(ANCHO_UTIL, ALTURA_HEADER_22PX, ProcesarTextoUTF8(titulo), "1", 1, "L", true, 0, "")
+	pdf.SetFillColor(255, 255, 255)
+}
+
+// filaEtiquetaValor - Celda con etiqueta en negrita y valor; finLinea pasa a la siguiente fila
+func (g *PDFGenerator) filaEtiquetaValor(pdf *gofpdf.Fpdf, etiqueta, valor string, ancho float64, finLinea bool) {
+	x, y := pdf.GetX(), pdf.GetY()
+	pdf.Rect(x, y, ancho, ALTURA_CELDA_20PX, "D")
+
+	pdf.SetFont("Arial", "B", FUENTE_PEQUENA_9PT)
+	etiquetaProcesada := ProcesarTextoUTF8(etiqueta)
+	anchoEtiqueta := pdf.GetStringWidth(etiquetaProcesada) + 2
+	pdf.CellFormat(anchoEtiqueta, ALTURA_CELDA_20PX, etiquetaProcesada, "", 0, "L", false, 0, "")
+
+	pdf.SetFont("Arial", "", FUENTE_PEQUENA_9PT)
+	pdf.CellFormat(ancho-anchoEtiqueta, ALTURA_CELDA_20PX, ProcesarTextoUTF8(valor), "", 0, "L", false, 0, "")
+
+	if finLinea {
+		pdf.SetXY(MARGEN_IZQUIERDO, y+ALTURA_CELDA_20PX)
+	} else {
+		pdf.SetXY(x+ancho, y)
+	}
+}
+
+// parrafo - Texto libre dentro de un recuadro que continúa en la siguiente página si es necesario
+func (g *PDFGenerator) parrafo(pdf *gofpdf.Fpdf, texto string) {
+	pdf.SetFont("Arial", "", FUENTE_PEQUENA_9PT)
+	pdf.MultiCell(ANCHO_UTIL, 5, ProcesarTextoUTF8(texto), "1", "J", false)
+}
+
+// calidadParte - Rol de la parte con mayúscula inicial (convocante → Convocante)
+func calidadParte(rol string) string {
+	if rol == "" {
+		return rol
+	}
+	return strings.ToUpper(rol[:1]) + rol[1:]
+}
+
+// textoConstancia - Texto de la constancia cuando la audiencia no terminó en acuerdo
+func textoConstancia(resultado, fecha string, partes []models.ConciliacionParte) string {
+	if resultado == "inasistencia" {
+		var ausentes []string
+		for _, parte := range partes {
+			if parte.Asistio != nil && !*parte.Asistio {
+				ausentes = append(ausentes, fmt.Sprintf("%s (%s)", parte.Nombre, parte.Rol))
+			}
+		}
+		return fmt.Sprintf("Se deja constancia de que, citadas las partes a la audiencia de conciliación del %s, "+
+			"no asistieron: %s. Se expide la presente constancia conforme a la ley.", fecha, strings.Join(ausentes, ", "))
+	}
+	return fmt.Sprintf("Se deja constancia de que en la audiencia de conciliación del %s las partes comparecieron "+
+		"pero no llegaron a ningún acuerdo. Se expide la presente constancia conforme a la ley.", fecha)
+}
+
+// generarFirmasConciliacion - Espacio de firma para cada parte que asistió y para el conciliador
+func (g *PDFGenerator) generarFirmasConciliacion(pdf *gofpdf.Fpdf, conciliacion *models.Conciliacion) {
+	type firma struct{ nombre, calidad string }
+	var firmas []firma
+	for _, parte := range conciliacion.Partes {
+		if parte.Asistio != nil && !*parte.Asistio {
+			continue
+		}
+		firmas = append(firmas, firma{parte.Nombre, calidadParte(parte.Rol)})
+	}
+	firmas = append(firmas, firma{conciliacion.Conciliador, "Conciliador"})
+
+	anchoFirma := (ANCHO_UTIL - 10) / 2
+	pdf.SetFont("Arial", "", FUENTE_PEQUENA_9PT)
+	pdf.Ln(10)
+	for i, f := range firmas {
+		columna := i % 2
+		if columna == 0 {
+			// Cada fila de firmas ocupa unos 22mm; si no cabe se pasa a la siguiente página
+			if pdf.GetY()+22 > ALTO_CARTA-MARGEN_INFERIOR-15 {
+				pdf.AddPage()
+			}
+			pdf.Ln(12)
+		}
+		x := MARGEN_IZQUIERDO + float64(columna)*(anchoFirma+10)
+		y := pdf.GetY()
+		pdf.SetXY(x, y)
+		pdf.CellFormat(anchoFirma, 5, "______________________________", "", 2, "C", false, 0, "")
+		pdf.CellFormat(anchoFirma, 5, ProcesarTextoUTF8(f.nombre), "", 2, "C", false, 0, "")
+		pdf.CellFormat(anchoFirma, 5, ProcesarTextoUTF8(f.calidad), "", 0, "C", false, 0, "")
+		if columna == 0 && i < len(firmas)-1 {
+			pdf.SetXY(MARGEN_IZQUIERDO, y)
+		} else {
+			pdf.SetXY(MARGEN_IZQUIERDO, y+15)
+		}
+	}
+
+	pdf.Ln(6)
+	pdf.SetFont("Arial", "I", FUENTE_FOOTER_8PT)
+	pdf.CellFormat(ANCHO_UTIL, 4, ProcesarTextoUTF8(fmt.Sprintf("Documento generado el %s", time.Now().Format("02/01/2006 15:04"))), "", 1, "R", false, 0, "")
+}
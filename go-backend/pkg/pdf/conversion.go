@@ -0,0 +1,379 @@
+package pdf
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+var (
+	// ErrFormatoNoSoportado indica que no hay un convertidor registrado para la extensión del archivo
+	ErrFormatoNoSoportado = errors.New("formato de archivo no soportado")
+	// ErrImagenDemasiadoGrande indica que la imagen declara más pixeles de los que se pueden convertir
+	ErrImagenDemasiadoGrande = errors.New("la imagen tiene demasiados pixeles")
+)
+
+// MaxPixelesImagen limita las dimensiones de las imágenes que se convierten. Decodificar una imagen
+// ocupa 4 bytes por pixel, y un archivo pequeño puede declarar dimensiones enormes
+const MaxPixelesImagen = 40_000_000
+
+// ConvertidorPDF convierte un archivo adjunto a PDF para anexarlo al control operativo
+type ConvertidorPDF interface {
+	// ConvertirAPDF escribe en destino el PDF equivalente al archivo de origen
+	ConvertirAPDF(origen, destino string) error
+}
+
+// tiposAdjunto relaciona cada extensión aceptada con su tipo MIME y con el tipo que detecta
+// http.DetectContentType en el contenido (un DOCX es un ZIP)
+var tiposAdjunto = map[string]struct {
+	mime      string
+	contenido string
+}{
+	".pdf":  {"application/pdf", "application/pdf"},
+	".jpg":  {"image/jpeg", "image/jpeg"},
+	".jpeg": {"image/jpeg", "image/jpeg"},
+	".png":  {"image/png", "image/png"},
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", "application/zip"},
+}
+
+// RegistrarConvertidor asocia un convertidor a una o varias extensiones (p. ej. ".docx")
+func (g *PDFGenerator) RegistrarConvertidor(convertidor ConvertidorPDF, extensiones ...string) {
+	for _, ext := range extensiones {
+		g.convertidores[strings.ToLower(ext)] = convertidor
+	}
+}
+
+// AceptaAdjunto indica si el archivo es PDF o puede convertirse a PDF en este servidor
+func (g *PDFGenerator) AceptaAdjunto(nombre string) bool {
+	ext := strings.ToLower(filepath.Ext(nombre))
+	if _, ok := tiposAdjunto[ext]; !ok {
+		return false
+	}
+	_, convertible := g.convertidores[ext]
+	return ext == ".pdf" || convertible
+}
+
+// TipoMIMEAdjunto retorna el tipo MIME según la extensión del archivo
+func TipoMIMEAdjunto(nombre string) string {
+	if tipo, ok := tiposAdjunto[strings.ToLower(filepath.Ext(nombre))]; ok {
+		return tipo.mime
+	}
+	return "application/octet-stream"
+}
+
+// ValidarContenidoAdjunto revisa que los primeros bytes del archivo correspondan a su extensión,
+// para no aceptar p. ej. un ejecutable renombrado como .jpg
+func ValidarContenidoAdjunto(nombre string, cabecera []byte) error {
+	tipo, ok := tiposAdjunto[strings.ToLower(filepath.Ext(nombre))]
+	if !ok {
+		return ErrFormatoNoSoportado
+	}
+	detectado := http.DetectContentType(cabecera)
+	if !strings.HasPrefix(detectado, tipo.contenido) {
+		return fmt.Errorf("el contenido del archivo (%s) no corresponde a su extensión", detectado)
+	}
+	return nil
+}
+
+// validarPixeles rechaza las imágenes que superan MaxPixelesImagen antes de decodificarlas
+func validarPixeles(config image.Config) error {
+	if int64(config.Width)*int64(config.Height) > MaxPixelesImagen {
+		return fmt.Errorf("%w (%dx%d; máximo %d megapíxeles)", ErrImagenDemasiadoGrande,
+			config.Width, config.Height, MaxPixelesImagen/1_000_000)
+	}
+	return nil
+}
+
+// ValidarImagenAdjunto revisa que la imagen se pueda leer y que se pueda convertir a PDF
+func ValidarImagenAdjunto(datos []byte) error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(datos))
+	if err != nil {
+		return fmt.Errorf("la imagen no es válida: %w", err)
+	}
+	return validarPixeles(config)
+}
+
+// ValidarDocumentoWord revisa que el ZIP contenga las partes de un documento de Word, ya que por
+// la cabecera cualquier ZIP renombrado a .docx pasaría como válido
+func ValidarDocumentoWord(datos []byte) error {
+	archivo, err := zip.NewReader(bytes.NewReader(datos), int64(len(datos)))
+	if err != nil {
+		return fmt.Errorf("el documento no es válido: %w", err)
+	}
+	partes := map[string]bool{"[Content_Types].xml": false, "word/document.xml": false}
+	for _, f := range archivo.File {
+		if _, ok := partes[f.Name]; ok {
+			partes[f.Name] = true
+		}
+	}
+	for nombre, encontrada := range partes {
+		if !encontrada {
+			return fmt.Errorf("el archivo no es un documento de Word (falta %s)", nombre)
+		}
+	}
+	return nil
+}
+
+// ConvertirAdjunto retorna la ruta del PDF del adjunto. Los PDF se usan tal cual; los demás se
+// convierten junto al original con el sufijo .pdf
+func (g *PDFGenerator) ConvertirAdjunto(ruta string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(ruta))
+	if ext == ".pdf" {
+		return ruta, nil
+	}
+	convertidor, ok := g.convertidores[ext]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrFormatoNoSoportado, ext)
+	}
+
+	destino := ruta + ".pdf"
+	if err := convertidor.ConvertirAPDF(ruta, destino); err != nil {
+		os.Remove(destino)
+		return "", fmt.Errorf("error convirtiendo %s a PDF: %w", filepath.Base(ruta), err)
+	}
+	return destino, nil
+}
+
+// ConvertidorImagenes convierte una imagen JPEG o PNG en un PDF de una página tamaño carta,
+// sin depender de programas externos
+type ConvertidorImagenes struct{}
+
+// ConvertirAPDF implementa ConvertidorPDF
+func (ConvertidorImagenes) ConvertirAPDF(origen, destino string) error {
+	datos, err := os.ReadFile(origen)
+	if err != nil {
+		return err
+	}
+	config, formato, err := image.DecodeConfig(bytes.NewReader(datos))
+	if err != nil {
+		return fmt.Errorf("la imagen no es válida: %w", err)
+	}
+	if err := validarPixeles(config); err != nil {
+		return err
+	}
+
+	tipoImagen := "JPG"
+	ancho, alto := config.Width, config.Height
+	switch formato {
+	case "jpeg":
+		// Las fotos de celular suelen guardarse sin rotar y con la orientación en el EXIF
+		if orientacion := orientacionEXIF(datos); orientacion > 1 {
+			img, err := jpeg.Decode(bytes.NewReader(datos))
+			if err != nil {
+				return fmt.Errorf("la imagen no es válida: %w", err)
+			}
+			orientada := orientar(img, orientacion)
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, orientada, &jpeg.Options{Quality: 90}); err != nil {
+				return err
+			}
+			datos = buf.Bytes()
+			ancho, alto = orientada.Bounds().Dx(), orientada.Bounds().Dy()
+		}
+	case "png":
+		// gofpdf no lee PNG entrelazados ni de 16 bits, así que se reescribe en 8 bits sobre fondo blanco
+		img, err := png.Decode(bytes.NewReader(datos))
+		if err != nil {
+			return fmt.Errorf("la imagen no es válida: %w", err)
+		}
+		plana := image.NewRGBA(img.Bounds())
+		draw.Draw(plana, plana.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(plana, plana.Bounds(), img, img.Bounds().Min, draw.Over)
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, plana); err != nil {
+			return err
+		}
+		datos = buf.Bytes()
+		tipoImagen = "PNG"
+	default:
+		return fmt.Errorf("%w: imagen %s", ErrFormatoNoSoportado, formato)
+	}
+	if ancho == 0 || alto == 0 {
+		return errors.New("la imagen no tiene contenido")
+	}
+
+	orientacionPagina := "P"
+	anchoPagina, altoPagina := ANCHO_CARTA, ALTO_CARTA
+	if ancho > alto {
+		orientacionPagina = "L"
+		anchoPagina, altoPagina = ALTO_CARTA, ANCHO_CARTA
+	}
+	pdf := gofpdf.New(orientacionPagina, "mm", "Letter", "")
+	pdf.SetMargins(MARGEN_IZQUIERDO, MARGEN_SUPERIOR, MARGEN_DERECHO)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+
+	opciones := gofpdf.ImageOptions{ImageType: tipoImagen}
+	pdf.RegisterImageOptionsReader("adjunto", opciones, bytes.NewReader(datos))
+
+	// La imagen ocupa el área útil sin deformarse y queda centrada
+	anchoUtil := anchoPagina - MARGEN_IZQUIERDO - MARGEN_DERECHO
+	altoUtil := altoPagina - MARGEN_SUPERIOR - MARGEN_INFERIOR
+	escala := anchoUtil / float64(ancho)
+	if altoUtil/float64(alto) < escala {
+		escala = altoUtil / float64(alto)
+	}
+	w, h := float64(ancho)*escala, float64(alto)*escala
+	x := MARGEN_IZQUIERDO + (anchoUtil-w)/2
+	y := MARGEN_SUPERIOR + (altoUtil-h)/2
+	pdf.ImageOptions("adjunto", x, y, w, h, false, opciones, 0, "")
+
+	return pdf.OutputFileAndClose(destino)
+}
+
+// orientacionEXIF lee la etiqueta Orientation (0x0112) del EXIF de un JPEG; retorna 1 si no la hay
+func orientacionEXIF(datos []byte) int {
+	if len(datos) < 4 || datos[0] != 0xFF || datos[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(datos); {
+		if datos[i] != 0xFF {
+			return 1
+		}
+		marcador := datos[i+1]
+		largo := int(binary.BigEndian.Uint16(datos[i+2:]))
+		if marcador == 0xDA || largo < 2 || i+2+largo > len(datos) {
+			// Desde el inicio de la imagen (SOS) ya no hay metadatos
+			return 1
+		}
+		segmento := datos[i+4 : i+2+largo]
+		if marcador == 0xE1 && len(segmento) > 14 && string(segmento[:6]) == "Exif\x00\x00" {
+			return orientacionTIFF(segmento[6:])
+		}
+		i += 2 + largo
+	}
+	return 1
+}
+
+// orientacionTIFF busca la orientación en el primer IFD de la cabecera TIFF del EXIF
+func orientacionTIFF(tiff []byte) int {
+	var orden binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		orden = binary.LittleEndian
+	case "MM":
+		orden = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(orden.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	entradas := int(orden.Uint16(tiff[ifd:]))
+	for n := 0; n < entradas; n++ {
+		entrada := ifd + 2 + n*12
+		if entrada+12 > len(tiff) {
+			return 1
+		}
+		if orden.Uint16(tiff[entrada:]) == 0x0112 {
+			valor := int(orden.Uint16(tiff[entrada+8:]))
+			if valor >= 1 && valor <= 8 {
+				return valor
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// orientar aplica la rotación o el reflejo que indica la orientación EXIF (2 a 8)
+func orientar(img image.Image, orientacion int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientacion >= 5 {
+		dw, dh = h, w
+	}
+	destino := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientacion {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			default:
+				dx, dy = x, y
+			}
+			destino.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return destino
+}
+
+// ConvertidorLibreOffice convierte documentos de Office con LibreOffice en modo headless
+type ConvertidorLibreOffice struct {
+	binario      string
+	tiempoMaximo time.Duration
+}
+
+// NuevoConvertidorLibreOffice busca soffice o libreoffice en el PATH; retorna false si no está instalado
+func NuevoConvertidorLibreOffice() (*ConvertidorLibreOffice, bool) {
+	for _, nombre := range []string{"soffice", "libreoffice"} {
+		if binario, err := exec.LookPath(nombre); err == nil {
+			return &ConvertidorLibreOffice{binario: binario, tiempoMaximo: 2 * time.Minute}, true
+		}
+	}
+	return nil, false
+}
+
+// ConvertirAPDF implementa ConvertidorPDF
+func (c *ConvertidorLibreOffice) ConvertirAPDF(origen, destino string) error {
+	// Cada conversión usa su propio directorio y perfil: LibreOffice no admite dos procesos con el mismo perfil
+	trabajo, err := os.MkdirTemp(filepath.Dir(destino), ".conversion_")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(trabajo)
+	perfil, err := filepath.Abs(filepath.Join(trabajo, "perfil"))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.tiempoMaximo)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, c.binario,
+		"-env:UserInstallation=file://"+filepath.ToSlash(perfil),
+		"--headless", "--convert-to", "pdf", "--outdir", trabajo, origen)
+	salida, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("LibreOffice no terminó en %s", c.tiempoMaximo)
+	}
+	if err != nil {
+		return fmt.Errorf("error ejecutando LibreOffice: %w, salida: %s", err, strings.TrimSpace(string(salida)))
+	}
+
+	generado := filepath.Join(trabajo, strings.TrimSuffix(filepath.Base(origen), filepath.Ext(origen))+".pdf")
+	if _, err := os.Stat(generado); err != nil {
+		return fmt.Errorf("LibreOffice no generó el PDF: %s", strings.TrimSpace(string(salida)))
+	}
+	return os.Rename(generado, destino)
+}
@@ -0,0 +1,93 @@
+package pdf
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// pngConDimensiones genera un PNG de 1x1 cuyo encabezado declara las dimensiones indicadas
+func pngConDimensiones(t *testing.T, ancho, alto uint32) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	datos := buf.Bytes()
+
+	// Firma (8 bytes), longitud (4) y tipo "IHDR" (4); luego ancho, alto y 5 bytes más antes del CRC
+	ihdr := datos[12 : 12+4+13]
+	binary.BigEndian.PutUint32(ihdr[4:8], ancho)
+	binary.BigEndian.PutUint32(ihdr[8:12], alto)
+	binary.BigEndian.PutUint32(datos[12+4+13:], crc32.ChecksumIEEE(ihdr))
+	return datos
+}
+
+func TestConvertirImagenDemasiadoGrande(t *testing.T) {
+	datos := pngConDimensiones(t, 20000, 20000)
+	if err := ValidarImagenAdjunto(datos); !errors.Is(err, ErrImagenDemasiadoGrande) {
+		t.Fatalf("ValidarImagenAdjunto: se esperaba ErrImagenDemasiadoGrande, se obtuvo %v", err)
+	}
+
+	dir := t.TempDir()
+	origen := filepath.Join(dir, "enorme.png")
+	if err := os.WriteFile(origen, datos, 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := (ConvertidorImagenes{}).ConvertirAPDF(origen, filepath.Join(dir, "enorme.png.pdf"))
+	if !errors.Is(err, ErrImagenDemasiadoGrande) {
+		t.Fatalf("ConvertirAPDF: se esperaba ErrImagenDemasiadoGrande, se obtuvo %v", err)
+	}
+}
+
+func TestValidarImagenAdjunto(t *testing.T) {
+	if err := ValidarImagenAdjunto(pngConDimensiones(t, 1, 1)); err != nil {
+		t.Errorf("imagen válida: %v", err)
+	}
+	if err := ValidarImagenAdjunto([]byte("no es una imagen")); err == nil {
+		t.Error("se esperaba un error con datos que no son una imagen")
+	}
+}
+
+// zipConArchivos genera un ZIP con las entradas indicadas
+func zipConArchivos(t *testing.T, nombres ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	escritor := zip.NewWriter(&buf)
+	for _, nombre := range nombres {
+		w, err := escritor.Create(nombre)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte("<xml/>"))
+	}
+	if err := escritor.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestValidarDocumentoWord(t *testing.T) {
+	docx := zipConArchivos(t, "[Content_Types].xml", "_rels/.rels", "word/document.xml")
+	if err := ValidarDocumentoWord(docx); err != nil {
+		t.Errorf("documento válido: %v", err)
+	}
+
+	invalidos := map[string][]byte{
+		"zip cualquiera": zipConArchivos(t, "leeme.txt", "programa.exe"),
+		"hoja de excel":  zipConArchivos(t, "[Content_Types].xml", "xl/workbook.xml"),
+		"no es zip":      []byte("PK\x03\x04 truncado"),
+	}
+	for caso, datos := range invalidos {
+		if err := ValidarDocumentoWord(datos); err == nil {
+			t.Errorf("%s: se esperaba un error", caso)
+		}
+	}
+}
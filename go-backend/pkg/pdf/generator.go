@@ -4,13 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/jung-kurt/gofpdf"
 	"consultorio-juridico/internal/models"
+	"consultorio-juridico/pkg/terminos"
 )
 
 // ProcesarTextoUTF8 - Función para convertir UTF-8 a ISO-8859-1 para gofpdf
@@ -97,31 +96,44 @@ const (
 
 type PDFGenerator struct {
 	// Configuración de estilos
+	convertidores map[string]ConvertidorPDF // por extensión, para los adjuntos que no son PDF
 }
 
 func NewPDFGenerator() *PDFGenerator {
-	return &PDFGenerator{}
+	g := &PDFGenerator{convertidores: map[string]ConvertidorPDF{}}
+	g.RegistrarConvertidor(ConvertidorImagenes{}, ".jpg", ".jpeg", ".png")
+	if libreOffice, ok := NuevoConvertidorLibreOffice(); ok {
+		g.RegistrarConvertidor(libreOffice, ".docx")
+	} else {
+		fmt.Printf("⚠️ Warning: LibreOffice no encontrado, no se aceptarán adjuntos DOCX\n")
+	}
+	return g
 }
 
 // GenerarControlOperativo - NUEVA IMPLEMENTACIÓN SEGÚN CLAUDE.md
 func (g *PDFGenerator) GenerarControlOperativo(control *models.ControlOperativo) ([]byte, error) {
+	resultado, err := g.GenerarControlOperativoCombinado(control)
+	if err != nil {
+		return nil, err
+	}
+	return resultado.PDF, nil
+}
+
+// GenerarControlOperativoCombinado genera el formulario con sus adjuntos e informa las páginas de
+// cada parte. Un adjunto cifrado o dañado produce un *ErrorArchivoPDF con su nombre
+func (g *PDFGenerator) GenerarControlOperativoCombinado(control *models.ControlOperativo) (*ResultadoCombinacion, error) {
 	// Generar el PDF del formulario principal
 	mainPDFBytes, err := g.generarFormularioPrincipalRefactorizado(control)
 	if err != nil {
 		return nil, err
 	}
-	
-	// Si no hay documentos adjuntos, devolver solo el formulario principal
-	if len(control.DocumentosAdjuntos) == 0 {
-		return mainPDFBytes, nil
-	}
-	
-	// Si hay documentos adjuntos, concatenarlos
+
+	// Si hay documentos adjuntos, concatenarlos; sin adjuntos solo se cuentan las páginas
 	return g.concatenarPDFs(mainPDFBytes, control.DocumentosAdjuntos)
 }
 
-// generarFormularioPrincipalRefactorizado - NUEVA IMPLEMENTACIÓN COMPLETA según CLAUDE.md
-func (g *PDFGenerator) generarFormularioPrincipalRefactorizado(control *models.ControlOperativo) ([]byte, error) {
+// nuevoDocumentoCarta crea un documento tamaño carta con los márgenes del formulario
+func (g *PDFGenerator) nuevoDocumentoCarta() *gofpdf.Fpdf {
 	// CREAR PDF EN FORMATO CARTA CORREGIDO
 	pdf := gofpdf.NewCustom(&gofpdf.InitType{
 		OrientationStr: "P",         // Vertical
@@ -141,6 +153,13 @@ func (g *PDFGenerator) generarFormularioPrincipalRefactorizado(control *models.C
 	
 	// ENCODING UTF-8 según CLAUDE.md
 	pdf.SetFont("Arial", "", FUENTE_NORMAL_10PT)
+
+	return pdf
+}
+
+// generarFormularioPrincipalRefactorizado - NUEVA IMPLEMENTACIÓN COMPLETA según CLAUDE.md
+func (g *PDFGenerator) generarFormularioPrincipalRefactorizado(control *models.ControlOperativo) ([]byte, error) {
+	pdf := g.nuevoDocumentoCarta()
 	
 	// PÁGINA 1: Encabezado + Secciones I-V
 	pdf.AddPage()
@@ -158,6 +177,16 @@ func (g *PDFGenerator) generarFormularioPrincipalRefactorizado(control *models.C
 	g.generarSeccionVI_Exacta(pdf)
 	g.generarFooterFinal(pdf)
 
+	// ANEXO opcional: campos adicionales de la plantilla del área del caso
+	if len(control.RespuestasPlantilla) > 0 {
+		g.generarAnexoPlantilla(pdf, control.AreaConsulta, control.RespuestasPlantilla)
+	}
+
+	// ANEXO opcional: solo se incluye cuando el control trae sus seguimientos cargados
+	if len(control.Seguimientos) > 0 {
+		g.generarAnexoSeguimiento(pdf, control.Seguimientos)
+	}
+
 	// Generar bytes del PDF
 	var buf bytes.Buffer
 	err := pdf.Output(&buf)
@@ -170,6 +199,11 @@ func (g *PDFGenerator) generarFormularioPrincipalRefactorizado(control *models.C
 
 // generarEncabezadoExacto - ENCABEZADO EXACTO según CLAUDE.md - CORRECCIÓN COMPLETA
 func (g *PDFGenerator) generarEncabezadoExacto(pdf *gofpdf.Fpdf) {
+	g.generarEncabezadoConTitulo(pdf, "CONTROL OPERATIVO DE CONSULTA JURÍDICA")
+}
+
+// generarEncabezadoConTitulo - Encabezado institucional con el título del documento
+func (g *PDFGenerator) generarEncabezadoConTitulo(pdf *gofpdf.Fpdf, titulo string) {
 	// Espaciado vertical ajustado - 1cm margen superior HARDCODEADO
 	pdf.Ln(5)  // Mínimo espaciado - margen superior ya es 1cm
 	
@@ -245,10 +279,10 @@ func (g *PDFGenerator) generarEncabezadoExacto(pdf *gofpdf.Fpdf) {
 	// Espacio 8px según CLAUDE.md
 	pdf.Ln(8 * PX_TO_MM)
 	
-	// CONTROL OPERATIVO DE CONSULTA JURÍDICA - Arial Bold 11pt, centrado, NEGRO
+	// TÍTULO DEL DOCUMENTO - Arial Bold 11pt, centrado, NEGRO
 	pdf.SetTextColor(0, 0, 0)  // NEGRO según CLAUDE.md
 	pdf.SetFont("Arial", "B", FUENTE_SUBTITULO_11PT)
-	pdf.CellFormat(0, 4, ProcesarTextoUTF8("CONTROL OPERATIVO DE CONSULTA JURÍDICA"), "", 1, "C", false, 0, "")
+	pdf.CellFormat(0, 4, ProcesarTextoUTF8(titulo), "", 1, "C", false, 0, "")
 	
 	// Espacio 12px antes de la tabla según CLAUDE.md
 	pdf.Ln(12 * PX_TO_MM)
@@ -591,6 +625,12 @@ func (g *PDFGenerator) generarSeccionIV_Exacta(pdf *gofpdf.Fpdf, control *models
 	firmaX := MARGEN_IZQUIERDO + ANCHO_UTIL - 35  // 35mm desde el borde derecho
 	firmaY := y + alturaSeccionIV - 6       // 6mm desde abajo del área
 	
+	// Las respuestas de la plantilla del área van completas en su propio anexo
+	if len(control.RespuestasPlantilla) > 0 {
+		pdf.SetXY(MARGEN_IZQUIERDO+3, firmaY-3)
+		pdf.Cell(100, 3, ProcesarTextoUTF8(fmt.Sprintf("Ver anexo: información del área (%d campo(s))", len(control.RespuestasPlantilla))))
+	}
+	
 	pdf.SetXY(firmaX, firmaY-3)
 	pdf.Cell(30, 3, ProcesarTextoUTF8("Firma Estudiante:"))
 	
@@ -733,6 +773,138 @@ func (g *PDFGenerator) generarFooterFinal(pdf *gofpdf.Fpdf) {
 	pdf.CellFormat(ANCHO_UTIL, 5, footerText, "", 1, "C", false, 0, "")
 }
 
+// generarAnexoPlantilla - Página(s) adicionales con las respuestas a la plantilla del área, sin recortar
+func (g *PDFGenerator) generarAnexoPlantilla(pdf *gofpdf.Fpdf, area string, respuestas models.RespuestasPlantilla) {
+	const (
+		anchoCampo = 60.0
+		alturaFila = 5.0
+	)
+	anchoRespuesta := ANCHO_UTIL - anchoCampo
+	limiteY := ALTO_CARTA - MARGEN_INFERIOR - 15 // Reservar espacio para el footer
+
+	encabezado := func() {
+		pdf.AddPage()
+		pdf.SetFillColor(239, 239, 239)
+		pdf.SetFont("Arial", "B", FUENTE_NORMAL_10PT)
+		titulo := "ANEXO. INFORMACIÓN DEL ÁREA " + strings.ToUpper(area)
+		pdf.CellFormat(ANCHO_UTIL, ALTURA_HEADER_22PX, ProcesarTextoUTF8(titulo), "1", 1, "L", true, 0, "")
+
+		pdf.SetFont("Arial", "B", FUENTE_PEQUENA_9PT)
+		pdf.CellFormat(anchoCampo, ALTURA_CELDA_20PX, "CAMPO", "1", 0, "C", false, 0, "")
+		pdf.CellFormat(anchoRespuesta, ALTURA_CELDA_20PX, "RESPUESTA", "1", 1, "C", false, 0, "")
+		pdf.SetFont("Arial", "", FUENTE_PEQUENA_9PT)
+	}
+
+	encabezado()
+	for _, respuesta := range respuestas {
+		campo := pdf.SplitLines([]byte(ProcesarTextoUTF8(respuesta.Etiqueta)), anchoCampo-2)
+		valor := pdf.SplitLines([]byte(ProcesarTextoUTF8(respuesta.Valor)), anchoRespuesta-2)
+
+		// Una respuesta más larga que una página se parte en varias filas
+		for len(campo) > 0 || len(valor) > 0 {
+			disponibles := int((limiteY - pdf.GetY()) / alturaFila)
+			if disponibles < 2 {
+				g.generarFooterFinal(pdf)
+				encabezado()
+				continue
+			}
+			filas := len(campo)
+			if len(valor) > filas {
+				filas = len(valor)
+			}
+			if filas > disponibles {
+				filas = disponibles
+			}
+			alto := float64(filas) * alturaFila
+			if alto < ALTURA_CELDA_20PX {
+				alto = ALTURA_CELDA_20PX
+			}
+
+			x, y := pdf.GetX(), pdf.GetY()
+			pdf.Rect(x, y, anchoCampo, alto, "D")
+			pdf.Rect(x+anchoCampo, y, anchoRespuesta, alto, "D")
+			for i := 0; i < filas; i++ {
+				if i < len(campo) {
+					pdf.SetXY(x+1, y+float64(i)*alturaFila)
+					pdf.Cell(anchoCampo-2, alturaFila, string(campo[i]))
+				}
+				if i < len(valor) {
+					pdf.SetXY(x+anchoCampo+1, y+float64(i)*alturaFila)
+					pdf.Cell(anchoRespuesta-2, alturaFila, string(valor[i]))
+				}
+			}
+			campo = campo[min(filas, len(campo)):]
+			valor = valor[min(filas, len(valor)):]
+			pdf.SetXY(x, y+alto)
+		}
+	}
+	g.generarFooterFinal(pdf)
+}
+
+// generarAnexoSeguimiento - Página(s) adicionales con las actuaciones posteriores a la atención
+func (g *PDFGenerator) generarAnexoSeguimiento(pdf *gofpdf.Fpdf, seguimientos []models.Seguimiento) {
+	const (
+		anchoFecha = 25.0
+		anchoTipo  = 35.0
+		anchoAutor = 45.0
+		alturaFila = 5.0
+	)
+	anchoDescripcion := ANCHO_UTIL - anchoFecha - anchoTipo - anchoAutor
+	limiteY := ALTO_CARTA - MARGEN_INFERIOR - 15 // Reservar espacio para el footer
+
+	encabezado := func() {
+		pdf.AddPage()
+		pdf.SetFillColor(239, 239, 239)
+		pdf.SetFont("Arial", "B", FUENTE_NORMAL_10PT)
+		pdf.CellFormat(ANCHO_UTIL, ALTURA_HEADER_22PX, ProcesarTextoUTF8("ANEXO. SEGUIMIENTO DEL CASO"), "1", 1, "L", true, 0, "")
+
+		pdf.SetFont("Arial", "B", FUENTE_PEQUENA_9PT)
+		pdf.CellFormat(anchoFecha, ALTURA_CELDA_20PX, "FECHA", "1", 0, "C", false, 0, "")
+		pdf.CellFormat(anchoTipo, ALTURA_CELDA_20PX, "TIPO", "1", 0, "C", false, 0, "")
+		pdf.CellFormat(anchoDescripcion, ALTURA_CELDA_20PX, ProcesarTextoUTF8("DESCRIPCIÓN"), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(anchoAutor, ALTURA_CELDA_20PX, "REGISTRADO POR", "1", 1, "C", false, 0, "")
+		pdf.SetFont("Arial", "", FUENTE_PEQUENA_9PT)
+	}
+
+	encabezado()
+	for _, seguimiento := range seguimientos {
+		lineas := pdf.SplitLines([]byte(ProcesarTextoUTF8(seguimiento.Descripcion)), anchoDescripcion-2)
+		if len(seguimiento.Adjuntos) > 0 {
+			lineas = append(lineas, []byte(ProcesarTextoUTF8(fmt.Sprintf("(%d soporte(s) adjunto(s))", len(seguimiento.Adjuntos)))))
+		}
+		if seguimiento.FechaVencimiento != nil {
+			termino := seguimiento.TipoTermino
+			if tipo, ok := terminos.ObtenerTipo(termino); ok {
+				termino = tipo.Nombre
+			}
+			lineas = append(lineas, []byte(ProcesarTextoUTF8(fmt.Sprintf("Término: %s, vence el %s", termino, seguimiento.FechaVencimiento.Format("02/01/2006")))))
+		}
+		alto := float64(len(lineas)) * alturaFila
+		if alto < ALTURA_CELDA_20PX {
+			alto = ALTURA_CELDA_20PX
+		}
+
+		if pdf.GetY()+alto > limiteY {
+			g.generarFooterFinal(pdf)
+			encabezado()
+		}
+
+		x, y := pdf.GetX(), pdf.GetY()
+		autor := strings.TrimSpace(seguimiento.Autor.Nombres + " " + seguimiento.Autor.Apellidos)
+
+		pdf.CellFormat(anchoFecha, alto, seguimiento.Fecha.Format("02/01/2006"), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(anchoTipo, alto, ProcesarTextoUTF8(seguimiento.Tipo), "1", 0, "L", false, 0, "")
+		pdf.Rect(x+anchoFecha+anchoTipo, y, anchoDescripcion, alto, "D")
+		for i, linea := range lineas {
+			pdf.SetXY(x+anchoFecha+anchoTipo+1, y+float64(i)*alturaFila)
+			pdf.Cell(anchoDescripcion-2, alturaFila, string(linea))
+		}
+		pdf.SetXY(x+anchoFecha+anchoTipo+anchoDescripcion, y)
+		pdf.CellFormat(anchoAutor, alto, ProcesarTextoUTF8(autor), "1", 1, "L", false, 0, "")
+	}
+	g.generarFooterFinal(pdf)
+}
+
 // Función auxiliar para dividir texto por ancho de caracteres
 func (g *PDFGenerator) splitTextForWidth(text string, maxChars int) []string {
 	var lines []string
@@ -768,158 +940,52 @@ func (g *PDFGenerator) splitTextForWidth(text string, maxChars int) []string {
 	return lines
 }
 
-// Función para concatenar PDFs usando ghostscript
-func (g *PDFGenerator) concatenarPDFs(mainPDFBytes []byte, documentos []models.DocumentoAdjunto) ([]byte, error) {
-	// Crear directorio temporal
-	tempDir := fmt.Sprintf("/tmp/pdf_merge_%d", time.Now().Unix())
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return nil, fmt.Errorf("error creando directorio temporal: %v", err)
-	}
-	defer os.RemoveAll(tempDir)
-
-	// Guardar el PDF principal
-	mainPDFPath := filepath.Join(tempDir, "main.pdf")
-	if err := os.WriteFile(mainPDFPath, mainPDFBytes, 0644); err != nil {
-		return nil, fmt.Errorf("error guardando PDF principal: %v", err)
-	}
-
-	// Preparar lista de PDFs para concatenar
-	var pdfPaths []string
-	pdfPaths = append(pdfPaths, mainPDFPath)
+// concatenarPDFs anexa al formulario la versión PDF de los documentos adjuntos
+func (g *PDFGenerator) concatenarPDFs(mainPDFBytes []byte, documentos []models.DocumentoAdjunto) (*ResultadoCombinacion, error) {
+	archivos := []ArchivoPDF{{Nombre: "formulario", Datos: mainPDFBytes}}
 
-	// Agregar documentos adjuntos
+	// Agregar documentos adjuntos, usando la versión PDF de los que se convirtieron
 	for _, doc := range documentos {
-		if _, err := os.Stat(doc.RutaArchivo); err == nil {
-			pdfPaths = append(pdfPaths, doc.RutaArchivo)
-		} else {
-			fmt.Printf("Warning: Documento adjunto no encontrado: %s\n", doc.RutaArchivo)
+		if !doc.ConvertidoPDF || doc.RutaPDF == "" {
+			fmt.Printf("Warning: Documento adjunto sin versión PDF: %s\n", doc.NombreOriginal)
+			continue
 		}
+		datos, err := os.ReadFile(doc.RutaPDF)
+		if err != nil {
+			fmt.Printf("Warning: Documento adjunto no encontrado: %s\n", doc.RutaPDF)
+			continue
+		}
+		archivos = append(archivos, ArchivoPDF{Nombre: doc.NombreOriginal, Datos: datos})
 	}
 
-	// Si solo tenemos el PDF principal, devolverlo
-	if len(pdfPaths) == 1 {
-		return mainPDFBytes, nil
-	}
-
-	// Concatenar PDFs usando ghostscript
-	outputPath := filepath.Join(tempDir, "merged.pdf")
-	
-	// Construir comando gs
-	args := []string{
-		"-dNOPAUSE",
-		"-dBATCH",
-		"-sDEVICE=pdfwrite",
-		fmt.Sprintf("-sOutputFile=%s", outputPath),
-	}
-	args = append(args, pdfPaths...)
-	
-	cmd := exec.Command("gs", args...)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("error ejecutando ghostscript: %v, stderr: %s", err, stderr.String())
-	}
-
-	// Leer el PDF concatenado
-	mergedBytes, err := os.ReadFile(outputPath)
-	if err != nil {
-		return nil, fmt.Errorf("error leyendo PDF concatenado: %v", err)
-	}
-
-	return mergedBytes, nil
+	return CombinarPDFs(archivos)
 }
 
-// GenerarControlOperativoConAdjuntos genera el PDF del control operativo y anexa los archivos PDF adjuntos
+// GenerarControlOperativoConAdjuntos genera el formulario del control y anexa solo los archivos PDF
+// indicados, en lugar de los DocumentosAdjuntos del control
 func (g *PDFGenerator) GenerarControlOperativoConAdjuntos(control *models.ControlOperativo, archivosAdjuntos []string) ([]byte, error) {
-	// Primero generar el PDF principal del control operativo
-	pdfPrincipal, err := g.GenerarControlOperativo(control)
+	// Solo el formulario: GenerarControlOperativo ya anexa los DocumentosAdjuntos del control
+	pdfPrincipal, err := g.generarFormularioPrincipalRefactorizado(control)
 	if err != nil {
 		return nil, fmt.Errorf("error generando PDF principal: %w", err)
 	}
-	
-	// Si no hay archivos adjuntos, retornar solo el PDF principal
-	if len(archivosAdjuntos) == 0 {
-		return pdfPrincipal, nil
-	}
-	
-	// Crear archivo temporal para el PDF principal
-	tmpDir := "storage/temp"
-	os.MkdirAll(tmpDir, 0755)
-	
-	timestamp := time.Now().Unix()
-	principalFile := filepath.Join(tmpDir, fmt.Sprintf("principal_%d.pdf", timestamp))
-	
-	// Escribir el PDF principal al archivo temporal
-	err = os.WriteFile(principalFile, pdfPrincipal, 0644)
-	if err != nil {
-		return nil, fmt.Errorf("error escribiendo PDF principal temporal: %w", err)
-	}
-	defer os.Remove(principalFile)
-	
-	// Preparar lista de archivos para combinar
-	archivosParaCombinar := []string{principalFile}
-	
-	// Agregar archivos adjuntos PDF que existan
+
+	archivos := []ArchivoPDF{{Nombre: "formulario", Datos: pdfPrincipal}}
 	for _, rutaArchivo := range archivosAdjuntos {
-		if filepath.Ext(strings.ToLower(rutaArchivo)) == ".pdf" {
-			if _, err := os.Stat(rutaArchivo); err == nil {
-				archivosParaCombinar = append(archivosParaCombinar, rutaArchivo)
-			} else {
-				fmt.Printf("⚠️ Warning: Archivo no encontrado: %s\n", rutaArchivo)
-			}
+		if filepath.Ext(strings.ToLower(rutaArchivo)) != ".pdf" {
+			continue
 		}
+		datos, err := os.ReadFile(rutaArchivo)
+		if err != nil {
+			fmt.Printf("⚠️ Warning: Archivo no encontrado: %s\n", rutaArchivo)
+			continue
+		}
+		archivos = append(archivos, ArchivoPDF{Nombre: filepath.Base(rutaArchivo), Datos: datos})
 	}
-	
-	// Si solo tenemos el PDF principal, retornarlo
-	if len(archivosParaCombinar) == 1 {
-		return pdfPrincipal, nil
-	}
-	
-	// Usar gs (ghostscript) para combinar PDFs si está disponible
-	outputFile := filepath.Join(tmpDir, fmt.Sprintf("combined_%d.pdf", timestamp))
-	defer os.Remove(outputFile)
-	
-	err = g.combinarPDFsConGhostscript(archivosParaCombinar, outputFile)
-	if err != nil {
-		fmt.Printf("⚠️ Warning: No se pudo usar ghostscript, retornando PDF principal: %v\n", err)
-		return pdfPrincipal, nil
-	}
-	
-	// Leer el PDF combinado
-	combinedBytes, err := os.ReadFile(outputFile)
-	if err != nil {
-		fmt.Printf("⚠️ Warning: Error leyendo PDF combinado, retornando PDF principal: %v\n", err)
-		return pdfPrincipal, nil
-	}
-	
-	return combinedBytes, nil
-}
 
-// combinarPDFsConGhostscript combina múltiples PDFs usando ghostscript
-func (g *PDFGenerator) combinarPDFsConGhostscript(archivos []string, output string) error {
-	// Verificar si ghostscript está disponible
-	_, err := exec.LookPath("gs")
+	resultado, err := CombinarPDFs(archivos)
 	if err != nil {
-		return fmt.Errorf("ghostscript no encontrado: %w", err)
-	}
-	
-	// Construir comando de ghostscript
-	args := []string{
-		"-dNOPAUSE",
-		"-dBATCH", 
-		"-sDEVICE=pdfwrite",
-		"-sOutputFile=" + output,
-	}
-	args = append(args, archivos...)
-	
-	// Ejecutar comando
-	cmd := exec.Command("gs", args...)
-	output_bytes, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("error ejecutando ghostscript: %w, output: %s", err, string(output_bytes))
+		return nil, err
 	}
-	
-	return nil
-}
\ No newline at end of file
+	return resultado.PDF, nil
+}
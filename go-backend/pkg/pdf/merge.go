@@ -0,0 +1,160 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+var (
+	ErrPDFCifrado  = errors.New("el PDF está protegido con contraseña")
+	ErrPDFCorrupto = errors.New("el PDF está dañado o no es un PDF válido")
+	ErrPDFVersion  = errors.New("los PDF en versión 2.0 no se pueden anexar")
+)
+
+func init() {
+	// pdfcpu no debe crear su directorio de configuración en el HOME del servidor
+	api.DisableConfigDir()
+}
+
+// ArchivoPDF es un documento que se va a combinar
+type ArchivoPDF struct {
+	Nombre string
+	Datos  []byte
+}
+
+// ErrorArchivoPDF indica cuál de los archivos impidió la combinación
+type ErrorArchivoPDF struct {
+	Nombre string
+	Err    error
+}
+
+func (e *ErrorArchivoPDF) Error() string {
+	return fmt.Sprintf("%s: %v", e.Nombre, e.Err)
+}
+
+func (e *ErrorArchivoPDF) Unwrap() error {
+	return e.Err
+}
+
+// ParteCombinada es un archivo del PDF combinado con su número de páginas
+type ParteCombinada struct {
+	Nombre  string `json:"nombre"`
+	Paginas int    `json:"paginas"`
+}
+
+// ResultadoCombinacion es el PDF combinado y las páginas que aportó cada archivo, en orden
+type ResultadoCombinacion struct {
+	PDF     []byte
+	Paginas int
+	Partes  []ParteCombinada
+}
+
+// configuracionCombinacion replica la configuración que usa pdfcpu al combinar archivos
+func configuracionCombinacion() *model.Configuration {
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.MERGECREATE
+	conf.ValidationMode = model.ValidationRelaxed
+	conf.CreateBookmarks = false
+	return conf
+}
+
+// leerPDF interpreta el archivo y traduce los errores de pdfcpu a ErrPDFCifrado o ErrPDFCorrupto
+func leerPDF(archivo ArchivoPDF, conf *model.Configuration) (ctx *model.Context, err error) {
+	// El parser de pdfcpu puede entrar en pánico con algunos archivos mal formados
+	defer func() {
+		if r := recover(); r != nil {
+			ctx, err = nil, &ErrorArchivoPDF{Nombre: archivo.Nombre, Err: fmt.Errorf("%w (%v)", ErrPDFCorrupto, r)}
+		}
+	}()
+
+	ctx, err = api.ReadAndValidate(bytes.NewReader(archivo.Datos), conf)
+	if err == nil {
+		return ctx, nil
+	}
+
+	// Los PDF con contraseña de apertura fallan al descifrar; los que solo restringen permisos se leen
+	if errors.Is(err, pdfcpu.ErrWrongPassword) || errors.Is(err, pdfcpu.ErrUnknownEncryption) {
+		return nil, &ErrorArchivoPDF{Nombre: archivo.Nombre, Err: ErrPDFCifrado}
+	}
+	return nil, &ErrorArchivoPDF{Nombre: archivo.Nombre, Err: fmt.Errorf("%w (%v)", ErrPDFCorrupto, err)}
+}
+
+// ContarPaginas retorna el número de páginas del PDF, o ErrPDFCifrado / ErrPDFCorrupto si no se puede leer
+func ContarPaginas(datos []byte) (int, error) {
+	ctx, err := leerPDF(ArchivoPDF{Nombre: "documento", Datos: datos}, configuracionCombinacion())
+	if err != nil {
+		var errArchivo *ErrorArchivoPDF
+		if errors.As(err, &errArchivo) {
+			return 0, errArchivo.Err
+		}
+		return 0, err
+	}
+	return ctx.PageCount, nil
+}
+
+// CombinarPDFs une los archivos en el orden recibido sin programas externos. Si alguno está
+// cifrado o dañado retorna un *ErrorArchivoPDF con su nombre
+func CombinarPDFs(archivos []ArchivoPDF) (*ResultadoCombinacion, error) {
+	if len(archivos) == 0 {
+		return nil, errors.New("no hay archivos para combinar")
+	}
+
+	// Se leen todos antes de combinar para reportar el primer archivo inválido con su nombre
+	conf := configuracionCombinacion()
+	contextos := make([]*model.Context, len(archivos))
+	resultado := &ResultadoCombinacion{}
+	for i, archivo := range archivos {
+		ctx, err := leerPDF(archivo, conf)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 && ctx.XRefTable.Version() == model.V20 && contextos[0].XRefTable.Version() < model.V20 {
+			return nil, &ErrorArchivoPDF{Nombre: archivo.Nombre, Err: ErrPDFVersion}
+		}
+		contextos[i] = ctx
+		resultado.Partes = append(resultado.Partes, ParteCombinada{Nombre: archivo.Nombre, Paginas: ctx.PageCount})
+		resultado.Paginas += ctx.PageCount
+	}
+
+	if len(archivos) == 1 {
+		resultado.PDF = archivos[0].Datos
+		return resultado, nil
+	}
+
+	pdf, err := combinarContextos(contextos, archivos)
+	if err != nil {
+		return nil, err
+	}
+	resultado.PDF = pdf
+	return resultado, nil
+}
+
+// combinarContextos anexa al primer documento las páginas de los demás y escribe el resultado
+func combinarContextos(contextos []*model.Context, archivos []ArchivoPDF) (pdf []byte, err error) {
+	actual := archivos[0].Nombre
+	defer func() {
+		if r := recover(); r != nil {
+			pdf, err = nil, &ErrorArchivoPDF{Nombre: actual, Err: fmt.Errorf("%w (%v)", ErrPDFCorrupto, r)}
+		}
+	}()
+
+	destino := contextos[0]
+	destino.EnsureVersionForWriting()
+	for i, fuente := range contextos[1:] {
+		actual = archivos[i+1].Nombre
+		if err := pdfcpu.MergeXRefTables(actual, fuente, destino, false, false); err != nil {
+			return nil, &ErrorArchivoPDF{Nombre: actual, Err: fmt.Errorf("error combinando: %w", err)}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := api.WriteContext(destino, &buf); err != nil {
+		return nil, fmt.Errorf("error escribiendo PDF combinado: %w", err)
+	}
+	return buf.Bytes(), nil
+}
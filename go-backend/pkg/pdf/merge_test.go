@@ -0,0 +1,212 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// documentoPrueba genera un PDF con el número de páginas indicado
+func documentoPrueba(t *testing.T, paginas int, proteger func(*gofpdf.Fpdf)) []byte {
+	t.Helper()
+	pdf := gofpdf.New("P", "mm", "Letter", "")
+	if proteger != nil {
+		proteger(pdf)
+	}
+	pdf.SetFont("Arial", "", 12)
+	for i := 1; i <= paginas; i++ {
+		pdf.AddPage()
+		pdf.Cell(40, 10, fmt.Sprintf("Pagina %d de %d", i, paginas))
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatalf("error generando PDF de prueba: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCombinarPDFs(t *testing.T) {
+	archivos := []ArchivoPDF{
+		{Nombre: "formulario.pdf", Datos: documentoPrueba(t, 2, nil)},
+		{Nombre: "cedula.pdf", Datos: documentoPrueba(t, 1, nil)},
+		{Nombre: "contrato.pdf", Datos: documentoPrueba(t, 3, nil)},
+	}
+
+	resultado, err := CombinarPDFs(archivos)
+	if err != nil {
+		t.Fatalf("CombinarPDFs: %v", err)
+	}
+	if resultado.Paginas != 6 {
+		t.Errorf("Paginas = %d, se esperaban 6", resultado.Paginas)
+	}
+	esperadas := []ParteCombinada{{"formulario.pdf", 2}, {"cedula.pdf", 1}, {"contrato.pdf", 3}}
+	if len(resultado.Partes) != len(esperadas) {
+		t.Fatalf("Partes = %v, se esperaba %v", resultado.Partes, esperadas)
+	}
+	for i, parte := range esperadas {
+		if resultado.Partes[i] != parte {
+			t.Errorf("Partes[%d] = %v, se esperaba %v", i, resultado.Partes[i], parte)
+		}
+	}
+
+	// El PDF combinado debe poder leerse de nuevo con todas las páginas
+	paginas, err := ContarPaginas(resultado.PDF)
+	if err != nil {
+		t.Fatalf("ContarPaginas del combinado: %v", err)
+	}
+	if paginas != 6 {
+		t.Errorf("el PDF combinado tiene %d páginas, se esperaban 6", paginas)
+	}
+}
+
+func TestCombinarPDFsUnSoloArchivo(t *testing.T) {
+	datos := documentoPrueba(t, 2, nil)
+	resultado, err := CombinarPDFs([]ArchivoPDF{{Nombre: "formulario.pdf", Datos: datos}})
+	if err != nil {
+		t.Fatalf("CombinarPDFs: %v", err)
+	}
+	if resultado.Paginas != 2 || !bytes.Equal(resultado.PDF, datos) {
+		t.Errorf("con un solo archivo se esperaba el mismo PDF de 2 páginas, se obtuvieron %d", resultado.Paginas)
+	}
+}
+
+func TestCombinarPDFsConImagenConvertida(t *testing.T) {
+	dir := t.TempDir()
+	img := image.NewRGBA(image.Rect(0, 0, 60, 40))
+	img.Set(10, 10, color.Black)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	origen := filepath.Join(dir, "foto.png")
+	if err := os.WriteFile(origen, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	destino := filepath.Join(dir, "foto.png.pdf")
+	if err := (ConvertidorImagenes{}).ConvertirAPDF(origen, destino); err != nil {
+		t.Fatalf("ConvertirAPDF: %v", err)
+	}
+	convertido, err := os.ReadFile(destino)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resultado, err := CombinarPDFs([]ArchivoPDF{
+		{Nombre: "formulario.pdf", Datos: documentoPrueba(t, 1, nil)},
+		{Nombre: "foto.png", Datos: convertido},
+	})
+	if err != nil {
+		t.Fatalf("CombinarPDFs: %v", err)
+	}
+	if resultado.Paginas != 2 {
+		t.Errorf("Paginas = %d, se esperaban 2", resultado.Paginas)
+	}
+}
+
+func TestCombinarPDFsCifrado(t *testing.T) {
+	cifrado := documentoPrueba(t, 1, func(pdf *gofpdf.Fpdf) {
+		pdf.SetProtection(gofpdf.CnProtectPrint, "usuario", "propietario")
+	})
+
+	_, err := CombinarPDFs([]ArchivoPDF{
+		{Nombre: "formulario.pdf", Datos: documentoPrueba(t, 1, nil)},
+		{Nombre: "extracto.pdf", Datos: cifrado},
+	})
+	if !errors.Is(err, ErrPDFCifrado) {
+		t.Fatalf("se esperaba ErrPDFCifrado, se obtuvo %v", err)
+	}
+	var errArchivo *ErrorArchivoPDF
+	if !errors.As(err, &errArchivo) || errArchivo.Nombre != "extracto.pdf" {
+		t.Errorf("el error debe identificar extracto.pdf, se obtuvo %v", err)
+	}
+
+	if _, err := ContarPaginas(cifrado); !errors.Is(err, ErrPDFCifrado) {
+		t.Errorf("ContarPaginas: se esperaba ErrPDFCifrado, se obtuvo %v", err)
+	}
+}
+
+func TestCombinarPDFsSoloRestringido(t *testing.T) {
+	// Sin contraseña de apertura el PDF se lee aunque restrinja permisos
+	restringido := documentoPrueba(t, 2, func(pdf *gofpdf.Fpdf) {
+		pdf.SetProtection(gofpdf.CnProtectPrint, "", "propietario")
+	})
+
+	resultado, err := CombinarPDFs([]ArchivoPDF{
+		{Nombre: "formulario.pdf", Datos: documentoPrueba(t, 1, nil)},
+		{Nombre: "restringido.pdf", Datos: restringido},
+	})
+	if err != nil {
+		t.Fatalf("CombinarPDFs: %v", err)
+	}
+	if resultado.Paginas != 3 {
+		t.Errorf("Paginas = %d, se esperaban 3", resultado.Paginas)
+	}
+}
+
+func TestCombinarPDFsRestringidoDanado(t *testing.T) {
+	// Un PDF que solo restringe permisos y además está dañado se reporta como dañado, no como cifrado
+	restringido := documentoPrueba(t, 2, func(pdf *gofpdf.Fpdf) {
+		pdf.SetProtection(gofpdf.CnProtectPrint, "", "propietario")
+	})
+	// Se quita la mitad del archivo pero se conserva el trailer, que sigue declarando /Encrypt
+	danado := append(append([]byte{}, restringido[:len(restringido)/4]...), restringido[len(restringido)*3/4:]...)
+	if !bytes.Contains(danado, []byte("/Encrypt")) {
+		t.Fatal("el PDF de prueba debe conservar el diccionario /Encrypt")
+	}
+
+	_, err := CombinarPDFs([]ArchivoPDF{
+		{Nombre: "formulario.pdf", Datos: documentoPrueba(t, 1, nil)},
+		{Nombre: "restringido.pdf", Datos: danado},
+	})
+	if !errors.Is(err, ErrPDFCorrupto) {
+		t.Fatalf("se esperaba ErrPDFCorrupto, se obtuvo %v", err)
+	}
+	if _, err := ContarPaginas(danado); !errors.Is(err, ErrPDFCorrupto) {
+		t.Errorf("ContarPaginas: se esperaba ErrPDFCorrupto, se obtuvo %v", err)
+	}
+}
+
+func TestCombinarPDFsCorrupto(t *testing.T) {
+	valido := documentoPrueba(t, 1, nil)
+	casos := map[string][]byte{
+		"vacio.pdf":    {},
+		"texto.pdf":    []byte("esto no es un PDF"),
+		"truncado.pdf": valido[:len(valido)/3],
+		"sin_xref.pdf": []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n"),
+	}
+
+	for nombre, datos := range casos {
+		_, err := CombinarPDFs([]ArchivoPDF{
+			{Nombre: "formulario.pdf", Datos: valido},
+			{Nombre: nombre, Datos: datos},
+		})
+		if !errors.Is(err, ErrPDFCorrupto) {
+			t.Errorf("%s: se esperaba ErrPDFCorrupto, se obtuvo %v", nombre, err)
+			continue
+		}
+		var errArchivo *ErrorArchivoPDF
+		if !errors.As(err, &errArchivo) || errArchivo.Nombre != nombre {
+			t.Errorf("%s: el error debe identificar el archivo, se obtuvo %v", nombre, err)
+		}
+	}
+}
+
+func TestContarPaginas(t *testing.T) {
+	for _, paginas := range []int{1, 4} {
+		obtenidas, err := ContarPaginas(documentoPrueba(t, paginas, nil))
+		if err != nil {
+			t.Fatalf("ContarPaginas: %v", err)
+		}
+		if obtenidas != paginas {
+			t.Errorf("ContarPaginas = %d, se esperaban %d", obtenidas, paginas)
+		}
+	}
+}
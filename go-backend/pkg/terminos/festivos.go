@@ -0,0 +1,128 @@
+package terminos
+
+import (
+	"sort"
+	"time"
+)
+
+// Festivo es un día feriado nacional en Colombia
+type Festivo struct {
+	Fecha  time.Time `json:"fecha"`
+	Nombre string    `json:"nombre"`
+}
+
+// festivoFijo es un feriado que se celebra siempre en su fecha
+type festivoFijo struct {
+	mes    time.Month
+	dia    int
+	nombre string
+}
+
+// festivosFijos no se trasladan (Ley 51 de 1983, art. 1)
+var festivosFijos = []festivoFijo{
+	{time.January, 1, "Año Nuevo"},
+	{time.May, 1, "Día del Trabajo"},
+	{time.July, 20, "Día de la Independencia"},
+	{time.August, 7, "Batalla de Boyacá"},
+	{time.December, 8, "Inmaculada Concepción"},
+	{time.December, 25, "Navidad"},
+}
+
+// festivosTrasladables se corren al lunes siguiente cuando no caen en lunes (Ley Emiliani)
+var festivosTrasladables = []festivoFijo{
+	{time.January, 6, "Día de los Reyes Magos"},
+	{time.March, 19, "Día de San José"},
+	{time.June, 29, "San Pedro y San Pablo"},
+	{time.August, 15, "Asunción de la Virgen"},
+	{time.October, 12, "Día de la Raza"},
+	{time.November, 1, "Todos los Santos"},
+	{time.November, 11, "Independencia de Cartagena"},
+}
+
+// festivoPascua es un feriado definido por su distancia en días al Domingo de Pascua
+type festivoPascua struct {
+	dias        int
+	trasladable bool
+	nombre      string
+}
+
+var festivosPascua = []festivoPascua{
+	{-3, false, "Jueves Santo"},
+	{-2, false, "Viernes Santo"},
+	{39, true, "Ascensión del Señor"},
+	{60, true, "Corpus Christi"},
+	{68, true, "Sagrado Corazón de Jesús"},
+}
+
+// Pascua retorna el Domingo de Pascua del año (algoritmo gregoriano de Meeus/Jones/Butcher)
+func Pascua(ano int) time.Time {
+	a := ano % 19
+	b := ano / 100
+	c := ano % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	mes := (h + l - 7*m + 114) / 31
+	dia := (h+l-7*m+114)%31 + 1
+	return time.Date(ano, time.Month(mes), dia, 0, 0, 0, 0, time.UTC)
+}
+
+// siguienteLunes retorna la misma fecha si es lunes o el lunes siguiente
+func siguienteLunes(fecha time.Time) time.Time {
+	dias := (int(time.Monday) - int(fecha.Weekday()) + 7) % 7
+	return fecha.AddDate(0, 0, dias)
+}
+
+// Festivos retorna los feriados nacionales del año en orden cronológico. Cuando dos feriados
+// coinciden en la misma fecha se listan ambos
+func Festivos(ano int) []Festivo {
+	festivos := make([]Festivo, 0, len(festivosFijos)+len(festivosTrasladables)+len(festivosPascua))
+
+	for _, f := range festivosFijos {
+		festivos = append(festivos, Festivo{Fecha: time.Date(ano, f.mes, f.dia, 0, 0, 0, 0, time.UTC), Nombre: f.nombre})
+	}
+	for _, f := range festivosTrasladables {
+		fecha := siguienteLunes(time.Date(ano, f.mes, f.dia, 0, 0, 0, 0, time.UTC))
+		festivos = append(festivos, Festivo{Fecha: fecha, Nombre: f.nombre})
+	}
+
+	pascua := Pascua(ano)
+	for _, f := range festivosPascua {
+		fecha := pascua.AddDate(0, 0, f.dias)
+		if f.trasladable {
+			fecha = siguienteLunes(fecha)
+		}
+		festivos = append(festivos, Festivo{Fecha: fecha, Nombre: f.nombre})
+	}
+
+	sort.SliceStable(festivos, func(i, j int) bool {
+		return festivos[i].Fecha.Before(festivos[j].Fecha)
+	})
+	return festivos
+}
+
+// EsFestivo indica si la fecha (según su propio huso horario) es feriado nacional
+func EsFestivo(fecha time.Time) bool {
+	ano, mes, dia := fecha.Date()
+	for _, f := range Festivos(ano) {
+		if f.Fecha.Month() == mes && f.Fecha.Day() == dia {
+			return true
+		}
+	}
+	return false
+}
+
+// EsDiaHabil indica si la fecha es de lunes a viernes y no es feriado
+func EsDiaHabil(fecha time.Time) bool {
+	switch fecha.Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+	return !EsFestivo(fecha)
+}
@@ -0,0 +1,94 @@
+package terminos
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrTipoTerminoDesconocido = errors.New("tipo de término desconocido")
+
+// TipoTermino es un plazo legal contado en días hábiles
+type TipoTermino struct {
+	Clave      string `json:"clave"`
+	Nombre     string `json:"nombre"`
+	Dias       int    `json:"dias"`
+	Fundamento string `json:"fundamento"`
+}
+
+// Tipos es el catálogo de términos que maneja el consultorio
+var Tipos = []TipoTermino{
+	{"tutela_fallo", "Fallo de tutela", 10, "Decreto 2591 de 1991, art. 29"},
+	{"tutela_impugnacion", "Impugnación del fallo de tutela", 3, "Decreto 2591 de 1991, art. 31"},
+	{"tutela_segunda_instancia", "Fallo de segunda instancia de tutela", 20, "Decreto 2591 de 1991, art. 32"},
+	{"peticion_general", "Respuesta a derecho de petición", 15, "Ley 1755 de 2015, art. 14"},
+	{"peticion_documentos", "Petición de documentos o información", 10, "Ley 1755 de 2015, art. 14 num. 1"},
+	{"peticion_consulta", "Consulta a autoridad", 30, "Ley 1755 de 2015, art. 14 num. 2"},
+	{"recurso_reposicion", "Recurso de reposición (proceso judicial)", 3, "Código General del Proceso, art. 318"},
+	{"recurso_apelacion", "Recurso de apelación (proceso judicial)", 3, "Código General del Proceso, art. 322"},
+	{"recursos_administrativos", "Recursos en actuación administrativa", 10, "Ley 1437 de 2011, art. 76"},
+}
+
+// ObtenerTipo busca un término del catálogo por su clave
+func ObtenerTipo(clave string) (TipoTermino, bool) {
+	for _, tipo := range Tipos {
+		if tipo.Clave == clave {
+			return tipo, true
+		}
+	}
+	return TipoTermino{}, false
+}
+
+// Vencimiento es el resultado de calcular un término desde una fecha
+type Vencimiento struct {
+	Tipo             TipoTermino `json:"tipo"`
+	FechaInicio      time.Time   `json:"fecha_inicio"`
+	FechaVencimiento time.Time   `json:"fecha_vencimiento"`
+}
+
+// SumarDiasHabiles retorna el día hábil número dias contado a partir del día siguiente a desde,
+// conservando la hora. Con dias <= 0 retorna la misma fecha
+func SumarDiasHabiles(desde time.Time, dias int) time.Time {
+	fecha := desde
+	for dias > 0 {
+		fecha = fecha.AddDate(0, 0, 1)
+		if EsDiaHabil(fecha) {
+			dias--
+		}
+	}
+	return fecha
+}
+
+// DiasHabilesEntre cuenta los días hábiles posteriores a desde hasta hasta inclusive
+func DiasHabilesEntre(desde, hasta time.Time) int {
+	dias := 0
+	inicio := soloFecha(desde)
+	fin := soloFecha(hasta)
+	for fecha := inicio.AddDate(0, 0, 1); !fecha.After(fin); fecha = fecha.AddDate(0, 0, 1) {
+		if EsDiaHabil(fecha) {
+			dias++
+		}
+	}
+	return dias
+}
+
+// Calcular retorna el vencimiento del término indicado. El término empieza a correr el día hábil
+// siguiente a la fecha de inicio (notificación, radicación o presentación)
+func Calcular(clave string, desde time.Time) (Vencimiento, error) {
+	tipo, ok := ObtenerTipo(clave)
+	if !ok {
+		return Vencimiento{}, ErrTipoTerminoDesconocido
+	}
+
+	inicio := soloFecha(desde)
+	return Vencimiento{
+		Tipo:             tipo,
+		FechaInicio:      inicio,
+		FechaVencimiento: SumarDiasHabiles(inicio, tipo.Dias),
+	}, nil
+}
+
+// soloFecha descarta la hora conservando el huso horario
+func soloFecha(fecha time.Time) time.Time {
+	ano, mes, dia := fecha.Date()
+	return time.Date(ano, mes, dia, 0, 0, 0, 0, fecha.Location())
+}
@@ -0,0 +1,160 @@
+package terminos
+
+import (
+	"testing"
+	"time"
+)
+
+func fecha(valor string) time.Time {
+	t, err := time.Parse("2006-01-02", valor)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestPascua(t *testing.T) {
+	casos := map[int]string{
+		2019: "2019-04-21",
+		2020: "2020-04-12",
+		2021: "2021-04-04",
+		2022: "2022-04-17",
+		2023: "2023-04-09",
+		2024: "2024-03-31",
+		2025: "2025-04-20",
+		2026: "2026-04-05",
+		2038: "2038-04-25",
+	}
+	for ano, esperado := range casos {
+		if obtenido := Pascua(ano).Format("2006-01-02"); obtenido != esperado {
+			t.Errorf("Pascua(%d) = %s, se esperaba %s", ano, obtenido, esperado)
+		}
+	}
+}
+
+func TestFestivos(t *testing.T) {
+	casos := map[int][]string{
+		2023: {
+			"2023-01-01", "2023-01-09", "2023-03-20", "2023-04-06", "2023-04-07", "2023-05-01",
+			"2023-05-22", "2023-06-12", "2023-06-19", "2023-07-03", "2023-07-20", "2023-08-07",
+			"2023-08-21", "2023-10-16", "2023-11-06", "2023-11-13", "2023-12-08", "2023-12-25",
+		},
+		2024: {
+			"2024-01-01", "2024-01-08", "2024-03-25", "2024-03-28", "2024-03-29", "2024-05-01",
+			"2024-05-13", "2024-06-03", "2024-06-10", "2024-07-01", "2024-07-20", "2024-08-07",
+			"2024-08-19", "2024-10-14", "2024-11-04", "2024-11-11", "2024-12-08", "2024-12-25",
+		},
+		// En 2025 San Pedro y San Pablo y el Sagrado Corazón coinciden el 30 de junio
+		2025: {
+			"2025-01-01", "2025-01-06", "2025-03-24", "2025-04-17", "2025-04-18", "2025-05-01",
+			"2025-06-02", "2025-06-23", "2025-06-30", "2025-06-30", "2025-07-20", "2025-08-07",
+			"2025-08-18", "2025-10-13", "2025-11-03", "2025-11-17", "2025-12-08", "2025-12-25",
+		},
+		2026: {
+			"2026-01-01", "2026-01-12", "2026-03-23", "2026-04-02", "2026-04-03", "2026-05-01",
+			"2026-05-18", "2026-06-08", "2026-06-15", "2026-06-29", "2026-07-20", "2026-08-07",
+			"2026-08-17", "2026-10-12", "2026-11-02", "2026-11-16", "2026-12-08", "2026-12-25",
+		},
+	}
+
+	for ano, esperados := range casos {
+		festivos := Festivos(ano)
+		if len(festivos) != len(esperados) {
+			t.Fatalf("Festivos(%d) retornó %d fechas, se esperaban %d", ano, len(festivos), len(esperados))
+		}
+		for i, festivo := range festivos {
+			if obtenido := festivo.Fecha.Format("2006-01-02"); obtenido != esperados[i] {
+				t.Errorf("Festivos(%d)[%d] = %s (%s), se esperaba %s", ano, i, obtenido, festivo.Nombre, esperados[i])
+			}
+		}
+	}
+}
+
+func TestFestivosTrasladadosCaenEnLunes(t *testing.T) {
+	for ano := 2000; ano <= 2040; ano++ {
+		for _, festivo := range Festivos(ano) {
+			if festivo.Fecha.Year() != ano {
+				t.Errorf("%s de %d cae en %s", festivo.Nombre, ano, festivo.Fecha.Format("2006-01-02"))
+			}
+		}
+		pascua := Pascua(ano)
+		for _, f := range festivosPascua {
+			if !f.trasladable {
+				continue
+			}
+			if dia := siguienteLunes(pascua.AddDate(0, 0, f.dias)).Weekday(); dia != time.Monday {
+				t.Errorf("%s de %d cae en %s", f.nombre, ano, dia)
+			}
+		}
+	}
+}
+
+func TestEsDiaHabil(t *testing.T) {
+	casos := []struct {
+		fecha  string
+		habil  bool
+		motivo string
+	}{
+		{"2024-03-27", true, "miércoles de Semana Santa"},
+		{"2024-03-28", false, "Jueves Santo"},
+		{"2024-03-30", false, "sábado"},
+		{"2024-03-31", false, "domingo"},
+		{"2025-01-06", false, "Reyes Magos en lunes"},
+		{"2026-01-06", true, "Reyes Magos trasladado al 12 de enero"},
+		{"2026-01-12", false, "Reyes Magos trasladado"},
+		{"2025-11-11", true, "Independencia de Cartagena trasladada al 17"},
+	}
+	for _, caso := range casos {
+		if obtenido := EsDiaHabil(fecha(caso.fecha)); obtenido != caso.habil {
+			t.Errorf("EsDiaHabil(%s) = %v, se esperaba %v (%s)", caso.fecha, obtenido, caso.habil, caso.motivo)
+		}
+	}
+}
+
+func TestCalcular(t *testing.T) {
+	casos := []struct {
+		tipo     string
+		desde    string
+		esperado string
+	}{
+		// Lunes festivo y Jueves y Viernes Santo dentro del término
+		{"peticion_general", "2024-03-22", "2024-04-17"},
+		// Corpus Christi y Sagrado Corazón/San Pedro dentro del término
+		{"tutela_fallo", "2025-06-20", "2025-07-08"},
+		{"tutela_impugnacion", "2023-12-22", "2023-12-28"},
+		// Cambio de año con Reyes Magos trasladado
+		{"peticion_documentos", "2025-12-30", "2026-01-15"},
+		// Iniciar en fin de semana cuenta desde el lunes hábil
+		{"recurso_reposicion", "2026-08-15", "2026-08-20"},
+	}
+	for _, caso := range casos {
+		vencimiento, err := Calcular(caso.tipo, fecha(caso.desde))
+		if err != nil {
+			t.Fatalf("Calcular(%s, %s): %v", caso.tipo, caso.desde, err)
+		}
+		if obtenido := vencimiento.FechaVencimiento.Format("2006-01-02"); obtenido != caso.esperado {
+			t.Errorf("Calcular(%s, %s) = %s, se esperaba %s", caso.tipo, caso.desde, obtenido, caso.esperado)
+		}
+		if dias := DiasHabilesEntre(vencimiento.FechaInicio, vencimiento.FechaVencimiento); dias != vencimiento.Tipo.Dias {
+			t.Errorf("DiasHabilesEntre para %s = %d, se esperaba %d", caso.tipo, dias, vencimiento.Tipo.Dias)
+		}
+	}
+
+	if _, err := Calcular("inexistente", fecha("2025-01-01")); err != ErrTipoTerminoDesconocido {
+		t.Errorf("se esperaba ErrTipoTerminoDesconocido, se obtuvo %v", err)
+	}
+}
+
+func TestSumarDiasHabilesConservaHora(t *testing.T) {
+	bogota := time.FixedZone("COT", -5*60*60)
+	desde := time.Date(2024, time.December, 24, 16, 30, 0, 0, bogota)
+	obtenido := SumarDiasHabiles(desde, 2)
+	esperado := time.Date(2024, time.December, 27, 16, 30, 0, 0, bogota)
+	if !obtenido.Equal(esperado) {
+		t.Errorf("SumarDiasHabiles = %s, se esperaba %s", obtenido, esperado)
+	}
+
+	if obtenido := SumarDiasHabiles(desde, 0); !obtenido.Equal(desde) {
+		t.Errorf("SumarDiasHabiles con 0 días = %s, se esperaba %s", obtenido, desde)
+	}
+}
@@ -0,0 +1,192 @@
+package validacion
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+
+	"consultorio-juridico/internal/models"
+)
+
+// TiposDocumento son los documentos de identidad que recibe el consultorio
+var TiposDocumento = []string{"CC", "TI", "NUIP", "CE", "PA", "PEP"}
+
+// formatosDocumento define el número esperado para cada tipo, ya sin puntos ni espacios
+var formatosDocumento = map[string]struct {
+	patron *regexp.Regexp
+	nombre string
+}{
+	"CC":   {regexp.MustCompile(`^[0-9]{5,10}$`), "cédula de ciudadanía (5 a 10 dígitos)"},
+	"TI":   {regexp.MustCompile(`^[0-9]{10,11}$`), "tarjeta de identidad (10 u 11 dígitos)"},
+	"NUIP": {regexp.MustCompile(`^[0-9]{10}$`), "NUIP del registro civil (10 dígitos)"},
+	"CE":   {regexp.MustCompile(`^[0-9]{6,10}$`), "cédula de extranjería (6 a 10 dígitos)"},
+	"PA":   {regexp.MustCompile(`^[A-Z0-9]{5,20}$`), "pasaporte (5 a 20 letras o dígitos)"},
+	"PEP":  {regexp.MustCompile(`^[0-9]{15}$`), "permiso especial de permanencia (15 dígitos)"},
+}
+
+// NormalizarTipoDocumento deja el tipo en mayúsculas y sin puntos ni espacios, de modo que
+// "C.C." y "cc" correspondan a CC
+func NormalizarTipoDocumento(tipo string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, tipo)
+}
+
+// NormalizarNumeroDocumento deja solo letras y dígitos, de modo que "1.023.456" y "1023456"
+// correspondan al mismo documento
+func NormalizarNumeroDocumento(numero string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, numero)
+}
+
+var (
+	// Los fijos tienen 10 dígitos desde la marcación 60X de 2021; se aceptan también los 7 dígitos anteriores
+	patronFijo    = regexp.MustCompile(`^(60[1-8][0-9]{7}|[2-8][0-9]{6})$`)
+	patronCelular = regexp.MustCompile(`^3[0-9]{9}$`)
+)
+
+// soloDigitosTelefono quita separadores y el indicativo +57
+func soloDigitosTelefono(valor string) string {
+	digitos := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, valor)
+	if len(digitos) == 12 && strings.HasPrefix(digitos, "57") {
+		digitos = digitos[2:]
+	}
+	return digitos
+}
+
+// validarTelefono acepta un fijo o un celular, ya que muchos consultantes solo tienen celular
+func validarTelefono(fl validator.FieldLevel) bool {
+	digitos := soloDigitosTelefono(fl.Field().String())
+	return patronFijo.MatchString(digitos) || patronCelular.MatchString(digitos)
+}
+
+func validarCelular(fl validator.FieldLevel) bool {
+	return patronCelular.MatchString(soloDigitosTelefono(fl.Field().String()))
+}
+
+// fecha construye la fecha y retorna false si no existe (p. ej. 31 de febrero)
+func fecha(ano, mes, dia int) (time.Time, bool) {
+	if ano <= 0 || mes < 1 || mes > 12 || dia < 1 {
+		return time.Time{}, false
+	}
+	f := time.Date(ano, time.Month(mes), dia, 0, 0, 0, 0, time.Local)
+	return f, f.Day() == dia && int(f.Month()) == mes
+}
+
+// enRango indica si día y mes pasaron las reglas min/max, para no reportar dos veces el mismo error
+func enRango(mes, dia int) bool {
+	return mes >= 1 && mes <= 12 && dia >= 1 && dia <= 31
+}
+
+// EdadEn calcula los años cumplidos a la fecha de referencia
+func EdadEn(nacimiento, referencia time.Time) int {
+	edad := referencia.Year() - nacimiento.Year()
+	if referencia.Month() < nacimiento.Month() ||
+		(referencia.Month() == nacimiento.Month() && referencia.Day() < nacimiento.Day()) {
+		edad--
+	}
+	return edad
+}
+
+// validarControlOperativo revisa la coherencia entre campos que las etiquetas no pueden expresar:
+// fechas reales, edad frente a fecha de nacimiento y documento según tipo y edad
+func validarControlOperativo(sl validator.StructLevel) {
+	req := sl.Current().Interface().(models.ControlOperativoRequest)
+	manana := time.Now().AddDate(0, 0, 1)
+
+	// Fecha de atención
+	atencion, atencionValida := fecha(req.FechaAno, req.FechaMes, req.FechaDia)
+	if req.FechaAno != 0 && enRango(req.FechaMes, req.FechaDia) {
+		switch {
+		case !atencionValida:
+			sl.ReportError(req.FechaDia, "fecha_dia", "FechaDia", "fecha_invalida", "")
+		case atencion.After(manana):
+			sl.ReportError(req.FechaDia, "fecha_dia", "FechaDia", "fecha_futura", "")
+			atencionValida = false
+		}
+	}
+	referencia := time.Now()
+	if atencionValida {
+		referencia = atencion
+	}
+
+	// Fecha de nacimiento: se diligencia completa o no se diligencia
+	edad := -1
+	if req.FechaNacimientoAno != 0 || req.FechaNacimientoMes != 0 || req.FechaNacimientoDia != 0 {
+		nacimiento, ok := fecha(req.FechaNacimientoAno, req.FechaNacimientoMes, req.FechaNacimientoDia)
+		incompleta := req.FechaNacimientoAno == 0 || req.FechaNacimientoMes == 0 || req.FechaNacimientoDia == 0
+		switch {
+		case !ok && (incompleta || enRango(req.FechaNacimientoMes, req.FechaNacimientoDia)):
+			sl.ReportError(req.FechaNacimientoDia, "fecha_nacimiento_dia", "FechaNacimientoDia", "fecha_invalida", "")
+		case !ok:
+			// Día o mes fuera de rango, ya reportado por las reglas min/max
+		case nacimiento.After(referencia):
+			sl.ReportError(req.FechaNacimientoDia, "fecha_nacimiento_dia", "FechaNacimientoDia", "fecha_futura", "")
+		default:
+			edad = EdadEn(nacimiento, referencia)
+			if req.Edad != 0 && req.Edad != edad {
+				sl.ReportError(req.Edad, "edad", "Edad", "edad_inconsistente", strconv.Itoa(edad))
+			}
+		}
+	}
+	if edad < 0 && req.Edad > 0 {
+		edad = req.Edad
+	}
+
+	validarDocumento(sl, req.TipoDocumento, req.NumeroDocumento, edad)
+}
+
+// validarDocumento revisa el formato del número según el tipo y que el tipo corresponda a la edad
+func validarDocumento(sl validator.StructLevel, tipoDocumento, numeroDocumento string, edad int) {
+	tipo := NormalizarTipoDocumento(tipoDocumento)
+	numero := NormalizarNumeroDocumento(numeroDocumento)
+
+	if tipo == "" {
+		if numero != "" {
+			sl.ReportError(tipoDocumento, "tipo_documento", "TipoDocumento", "required", "")
+		}
+		return
+	}
+	formato, ok := formatosDocumento[tipo]
+	if !ok {
+		sl.ReportError(tipoDocumento, "tipo_documento", "TipoDocumento", "oneof", strings.Join(TiposDocumento, " "))
+		return
+	}
+	if numero != "" && !formato.patron.MatchString(numero) {
+		sl.ReportError(numeroDocumento, "numero_documento", "NumeroDocumento", "documento_formato", formato.nombre)
+	}
+
+	if edad < 0 {
+		return
+	}
+	switch {
+	case tipo == "TI" && edad >= 18:
+		sl.ReportError(tipoDocumento, "tipo_documento", "TipoDocumento", "documento_edad",
+			"la tarjeta de identidad es para menores de edad; un adulto se identifica con CC")
+	case tipo == "TI" && edad < 7:
+		sl.ReportError(tipoDocumento, "tipo_documento", "TipoDocumento", "documento_edad",
+			"la tarjeta de identidad se expide desde los 7 años; un menor de 7 años se identifica con NUIP")
+	case tipo == "NUIP" && edad >= 18:
+		sl.ReportError(tipoDocumento, "tipo_documento", "TipoDocumento", "documento_edad",
+			"el NUIP del registro civil es para menores de edad; un adulto se identifica con CC")
+	case tipo == "CC" && edad < 18:
+		sl.ReportError(tipoDocumento, "tipo_documento", "TipoDocumento", "documento_edad",
+			"la cédula de ciudadanía se expide a partir de los 18 años; un menor se identifica con TI o NUIP")
+	}
+}
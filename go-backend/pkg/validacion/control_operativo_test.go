@@ -0,0 +1,88 @@
+package validacion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"consultorio-juridico/internal/models"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	if err := Registrar(); err != nil {
+		panic(err)
+	}
+}
+
+// enlazar decodifica el cuerpo como lo hace el handler al registrar un control
+func enlazar(t *testing.T, cuerpo string) []ErrorCampo {
+	t.Helper()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/control-operativo", strings.NewReader(cuerpo))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	var req models.ControlOperativoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return Errores(err)
+	}
+	return nil
+}
+
+// formulario arma una solicitud válida con el documento y la fecha de nacimiento indicados
+func formulario(tipo, numero, nacimiento string) string {
+	return `{
+		"ciudad": "Bogotá", "fecha_dia": 10, "fecha_mes": 3, "fecha_ano": 2025,
+		"nombre_estudiante": "Estudiante", "area_consulta": "civil", "nombre_consultante": "Consultante",
+		"descripcion_caso": "Descripción", "concepto_estudiante": "Concepto",
+		"tipo_documento": "` + tipo + `", "numero_documento": "` + numero + `", ` + nacimiento + `
+	}`
+}
+
+func TestDocumentoComoLoEnviaElFormulario(t *testing.T) {
+	// Los formularios de estudiante y coordinador envían "C.C.", "T.I." y "NUIP"
+	casos := []struct {
+		tipo, numero, nacimiento string
+	}{
+		{"C.C.", "1.023.456.789", `"fecha_nacimiento_dia": 1, "fecha_nacimiento_mes": 1, "fecha_nacimiento_ano": 1990`},
+		{"T.I.", "1020304050", `"fecha_nacimiento_dia": 1, "fecha_nacimiento_mes": 1, "fecha_nacimiento_ano": 2012`},
+		{"NUIP", "1020304050", `"fecha_nacimiento_dia": 1, "fecha_nacimiento_mes": 1, "fecha_nacimiento_ano": 2021`},
+		{"cc", "52123456", `"edad": 40`},
+	}
+	for _, caso := range casos {
+		if errores := enlazar(t, formulario(caso.tipo, caso.numero, caso.nacimiento)); errores != nil {
+			t.Errorf("%s %s: se esperaba válido, se obtuvo %+v", caso.tipo, caso.numero, errores)
+		}
+	}
+}
+
+func TestDocumentoInvalido(t *testing.T) {
+	casos := []struct {
+		tipo, numero, nacimiento, campo, regla string
+	}{
+		{"C.C.", "1234", `"edad": 40`, "numero_documento", "documento_formato"},
+		{"NUIP", "12345", `"edad": 3`, "numero_documento", "documento_formato"},
+		{"C.C.", "1020304050", `"edad": 15`, "tipo_documento", "documento_edad"},
+		{"T.I.", "1020304050", `"edad": 30`, "tipo_documento", "documento_edad"},
+		{"NUIP", "1020304050", `"edad": 30`, "tipo_documento", "documento_edad"},
+		{"R.C.", "1020304050", `"edad": 3`, "tipo_documento", "oneof"},
+	}
+	for _, caso := range casos {
+		errores := enlazar(t, formulario(caso.tipo, caso.numero, caso.nacimiento))
+		if len(errores) != 1 || errores[0].Campo != caso.campo || errores[0].Regla != caso.regla {
+			t.Errorf("%s %s: se esperaba %s en %s, se obtuvo %+v", caso.tipo, caso.numero, caso.regla, caso.campo, errores)
+		}
+	}
+}
+
+func TestNormalizarTipoDocumento(t *testing.T) {
+	casos := map[string]string{"C.C.": "CC", " t.i. ": "TI", "NUIP": "NUIP", "C. E.": "CE", "pep": "PEP"}
+	for entrada, esperado := range casos {
+		if obtenido := NormalizarTipoDocumento(entrada); obtenido != esperado {
+			t.Errorf("NormalizarTipoDocumento(%q) = %q, se esperaba %q", entrada, obtenido, esperado)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package validacion
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"consultorio-juridico/internal/models"
+)
+
+// ErrorCampo describe por qué un campo de la solicitud no es válido
+type ErrorCampo struct {
+	Campo   string `json:"campo"`
+	Regla   string `json:"regla"`
+	Mensaje string `json:"mensaje"`
+}
+
+// mensajesRegla traduce las reglas del validador a mensajes para el usuario
+var mensajesRegla = map[string]string{
+	"required":           "es requerido",
+	"email":              "no es un correo electrónico válido",
+	"oneof":              "debe ser uno de: %s",
+	"min":                "debe ser mayor o igual a %s",
+	"max":                "debe ser menor o igual a %s",
+	"fecha_invalida":     "la fecha no existe en el calendario",
+	"fecha_futura":       "la fecha no puede ser posterior a hoy",
+	"edad_inconsistente": "no coincide con la fecha de nacimiento (%s años)",
+	"documento_formato":  "no tiene el formato de %s",
+	"documento_edad":     "%s",
+	"telefono":           "debe ser un teléfono fijo (601xxxxxxx) o celular colombiano",
+	"celular":            "debe ser un celular colombiano de 10 dígitos que empiece por 3",
+}
+
+// Registrar instala las reglas del consultorio en el validador que usa gin al decodificar las
+// solicitudes, de modo que ShouldBindJSON y binding.Validator las apliquen
+func Registrar() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("el validador de gin no es go-playground/validator")
+	}
+
+	// Los errores se reportan con el nombre JSON del campo, que es el que conoce el cliente
+	v.RegisterTagNameFunc(func(campo reflect.StructField) string {
+		nombre := strings.SplitN(campo.Tag.Get("json"), ",", 2)[0]
+		if nombre == "-" {
+			return ""
+		}
+		if nombre == "" {
+			return campo.Name
+		}
+		return nombre
+	})
+
+	if err := v.RegisterValidation("telefono", validarTelefono); err != nil {
+		return err
+	}
+	if err := v.RegisterValidation("celular", validarCelular); err != nil {
+		return err
+	}
+	v.RegisterStructValidation(validarControlOperativo, models.ControlOperativoRequest{})
+	return nil
+}
+
+// mensaje arma el texto de un error de campo
+func mensaje(fe validator.FieldError) string {
+	formato, ok := mensajesRegla[fe.Tag()]
+	if !ok {
+		return "no es válido"
+	}
+	if strings.Contains(formato, "%s") {
+		return fmt.Sprintf(formato, fe.Param())
+	}
+	return formato
+}
+
+// Errores convierte el error de ShouldBindJSON en la lista de campos inválidos. Cuando el error
+// no es de validación (JSON mal formado, tipos incorrectos) retorna un solo error sin campo
+func Errores(err error) []ErrorCampo {
+	var validacion validator.ValidationErrors
+	if !errors.As(err, &validacion) {
+		return []ErrorCampo{{Regla: "formato", Mensaje: err.Error()}}
+	}
+
+	errores := make([]ErrorCampo, 0, len(validacion))
+	for _, fe := range validacion {
+		errores = append(errores, ErrorCampo{
+			Campo:   campoJSON(fe),
+			Regla:   fe.Tag(),
+			Mensaje: mensaje(fe),
+		})
+	}
+	return errores
+}
+
+// campoJSON retorna la ruta del campo sin el nombre del struct raíz, p. ej. contrapartes[0].nombre
+func campoJSON(fe validator.FieldError) string {
+	ruta := fe.Namespace()
+	if i := strings.Index(ruta, "."); i >= 0 {
+		return ruta[i+1:]
+	}
+	return fe.Field()
+}